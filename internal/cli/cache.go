@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func newCacheCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and manage the HTTP response cache",
+		Long: `Inspect and manage the cache of registry and repository responses that "parse" fills.
+
+Entries are stored under keys such as pypi:release:requests@2.31.0 and
+npm:versions:react; the part before the first colon names the registry.`,
+	}
+	cmd.PersistentFlags().StringVar(&dir, "dir", "", "cache directory (default: ~/.cache/stacktower)")
+
+	open := func() (httputil.Cache, error) {
+		return httputil.NewFileCache(dir, source.DefaultCacheTTL)
+	}
+
+	cmd.AddCommand(newCacheStatsCmd(open))
+	cmd.AddCommand(newCacheListCmd(open))
+	cmd.AddCommand(newCachePruneCmd(open))
+	cmd.AddCommand(newCacheClearCmd(open))
+	cmd.AddCommand(newCacheExportCmd(open))
+	cmd.AddCommand(newCacheImportCmd(open))
+
+	return cmd
+}
+
+type cacheOpener func() (httputil.Cache, error)
+
+func newCacheStatsCmd(open cacheOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show the number, size and age of cached entries per registry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			entries, err := c.Entries()
+			if err != nil {
+				return err
+			}
+
+			type stats struct {
+				count, size    int
+				oldest, newest time.Time
+			}
+			add := func(s *stats, e httputil.Entry) {
+				s.count++
+				s.size += e.Size
+				if s.oldest.IsZero() || e.Stored.Before(s.oldest) {
+					s.oldest = e.Stored
+				}
+				if e.Stored.After(s.newest) {
+					s.newest = e.Stored
+				}
+			}
+			var total stats
+			byRegistry := make(map[string]*stats)
+			for _, e := range entries {
+				registry, _, _ := strings.Cut(e.Key, ":")
+				if byRegistry[registry] == nil {
+					byRegistry[registry] = &stats{}
+				}
+				add(byRegistry[registry], e)
+				add(&total, e)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "REGISTRY\tENTRIES\tSIZE\tOLDEST\tNEWEST")
+			row := func(name string, s *stats) {
+				fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", name, s.count, formatBytes(s.size), formatAge(s.oldest), formatAge(s.newest))
+			}
+			for _, registry := range slices.Sorted(maps.Keys(byRegistry)) {
+				row(registry, byRegistry[registry])
+			}
+			row("total", &total)
+			return tw.Flush()
+		},
+	}
+}
+
+func newCacheListCmd(open cacheOpener) *cobra.Command {
+	var prefix string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached keys with their size and age",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			entries, err := c.Entries()
+			if err != nil {
+				return err
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			for _, e := range entries {
+				if strings.HasPrefix(e.Key, prefix) {
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Key, formatBytes(e.Size), formatAge(e.Stored))
+				}
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "prefix", "", "only list keys with this prefix (e.g. pypi:)")
+	return cmd
+}
+
+func newCachePruneCmd(open cacheOpener) *cobra.Command {
+	var olderThan string
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete entries older than a given age",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return fmt.Errorf("--older-than: %w", err)
+			}
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			n, err := httputil.Prune(c, age)
+			if err != nil {
+				return err
+			}
+			loggerFromContext(cmd.Context()).Infof("Pruned %d entries older than %s", n, olderThan)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "age of the entries to delete, as a duration (36h) or in days (7d)")
+	_ = cmd.MarkFlagRequired("older-than")
+	return cmd
+}
+
+func newCacheClearCmd(open cacheOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Delete every cached entry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			if err := c.Clear(); err != nil {
+				return err
+			}
+			loggerFromContext(cmd.Context()).Info("Cleared the cache")
+			return nil
+		},
+	}
+}
+
+func newCacheExportCmd(open cacheOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <file.tar.gz>",
+		Short: "Write every cached entry to a tarball",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			n, err := httputil.Export(c, f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			loggerFromContext(cmd.Context()).Infof("Exported %d entries to %s", n, args[0])
+			return nil
+		},
+	}
+}
+
+func newCacheImportCmd(open cacheOpener) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file.tar.gz>",
+		Short: "Add the entries of a tarball written by export to the cache",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := open()
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			n, err := httputil.Import(c, f)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			loggerFromContext(cmd.Context()).Infof("Imported %d entries from %s", n, args[0])
+			return nil
+		},
+	}
+}
+
+// parseAge parses a Go duration, or a whole number of days such as 7d.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	age := time.Since(t)
+	if age >= 48*time.Hour {
+		return fmt.Sprintf("%dd", int(age/(24*time.Hour)))
+	}
+	return age.Round(time.Minute).String()
+}
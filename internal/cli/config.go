@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+// configNames are the file names a config is looked up under, in the
+// current directory and then in ~/.config/stacktower.
+var configNames = []string{"stacktower.yaml", "stacktower.yml"}
+
+// config is a stacktower.yaml file. The parse and render sections set
+// flags of those commands by name (max-depth: 5); a profile holds sections
+// that override them. ${VAR} references in the values of tokens and
+// registries are replaced by environment variables when it is loaded; a
+// reference to an unset variable is an error.
+type config struct {
+	Parse      map[string]any            `yaml:"parse"`
+	Render     map[string]any            `yaml:"render"`
+	Tokens     map[string]string         `yaml:"tokens"`
+	Registries map[string]registryConfig `yaml:"registries"`
+	Profiles   map[string]map[string]any `yaml:"profiles"`
+	path       string
+}
+
+type registryConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// findConfig returns the path of the config to use: path if set, else the
+// first config file found in the current directory or ~/.config/stacktower.
+// It returns "" if there is none.
+func findConfig(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	dirs := []string{"."}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "stacktower"))
+	}
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			p := filepath.Join(dir, name)
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// loadConfig reads the config at path and applies profile to it. An empty
+// path is an empty config.
+func loadConfig(path, profile string) (*config, error) {
+	cfg := &config{path: path}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := cfg.expandEnv(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if profile == "" {
+		return cfg, nil
+	}
+
+	p, ok := cfg.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (defined: %s)", profile, strings.Join(slices.Sorted(maps.Keys(cfg.Profiles)), ", "))
+	}
+	for section, values := range p {
+		m, ok := values.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("profile %q: section %q is not a mapping", profile, section)
+		}
+		switch section {
+		case "parse":
+			cfg.Parse = merged(cfg.Parse, m)
+		case "render":
+			cfg.Render = merged(cfg.Render, m)
+		default:
+			return nil, fmt.Errorf("profile %q: unknown section %q (want parse or render)", profile, section)
+		}
+	}
+	return cfg, nil
+}
+
+// expandEnv replaces the ${VAR} references in the credentials and
+// endpoints of the config.
+func (c *config) expandEnv() error {
+	for name, token := range c.Tokens {
+		v, err := expandEnv(token)
+		if err != nil {
+			return fmt.Errorf("tokens.%s: %w", name, err)
+		}
+		c.Tokens[name] = v
+	}
+	for name, r := range c.Registries {
+		var err error
+		if r.URL, err = expandEnv(r.URL); err != nil {
+			return fmt.Errorf("registries.%s.url: %w", name, err)
+		}
+		for header, value := range r.Headers {
+			if r.Headers[header], err = expandEnv(value); err != nil {
+				return fmt.Errorf("registries.%s.headers.%s: %w", name, header, err)
+			}
+		}
+		c.Registries[name] = r
+	}
+	return nil
+}
+
+// expandEnv replaces $VAR and ${VAR} in s by environment variables, which
+// must be set.
+func expandEnv(s string) (string, error) {
+	var unset []string
+	s = os.Expand(s, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok && !slices.Contains(unset, name) {
+			unset = append(unset, name)
+		}
+		return v
+	})
+	if len(unset) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(unset, ", "))
+	}
+	return s, nil
+}
+
+func merged(base, override map[string]any) map[string]any {
+	m := maps.Clone(base)
+	if m == nil {
+		m = make(map[string]any)
+	}
+	maps.Copy(m, override)
+	return m
+}
+
+// section returns the flag values for cmd: the parse section for parse and
+// its subcommands, the render section for render. Other commands have
+// none.
+func (c *config) section(cmd *cobra.Command) (string, map[string]any) {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		switch cmd.Name() {
+		case "parse":
+			return "parse", c.Parse
+		case "render":
+			return "render", c.Render
+		}
+	}
+	return "", nil
+}
+
+// applyConfig sets the flags of cmd that were not given on the command line
+// from STACKTOWER_<FLAG> environment variables (STACKTOWER_MAX_DEPTH), then
+// from the config. Flags it sets count as changed, like those given.
+func applyConfig(cmd *cobra.Command, cfg *config) error {
+	name, values := cfg.section(cmd)
+	if name == "" {
+		return nil
+	}
+
+	var errs []error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		env := "STACKTOWER_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if v, ok := os.LookupEnv(env); ok {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			}
+			f.Changed = true
+		}
+	})
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		f := cmd.Flags().Lookup(key)
+		if f == nil {
+			// Flags of other subcommands, such as python-version for
+			// parse rust, are not an error.
+			if !sectionHasFlag(cmd, name, key) {
+				errs = append(errs, fmt.Errorf("%s: unknown %s option %q", cfg.path, name, key))
+			}
+			continue
+		}
+		if f.Changed {
+			continue
+		}
+		if err := setFlag(f, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s.%s: %w", cfg.path, name, key, err))
+		}
+		f.Changed = true
+	}
+	return errors.Join(errs...)
+}
+
+// setFlag sets f to a value from the config. Each item of a list is added
+// on its own to flags that repeat, and joined by commas for others.
+func setFlag(f *pflag.Flag, v any) error {
+	list, isList := v.([]any)
+	if !isList {
+		return f.Value.Set(fmt.Sprint(v))
+	}
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.Replace(items)
+	}
+	return f.Value.Set(strings.Join(items, ","))
+}
+
+// sectionHasFlag reports whether any command under the section's command
+// has the flag.
+func sectionHasFlag(cmd *cobra.Command, section, flag string) bool {
+	for cmd.Parent() != nil && cmd.Name() != section {
+		cmd = cmd.Parent()
+	}
+	var has func(*cobra.Command) bool
+	has = func(c *cobra.Command) bool {
+		if c.Flags().Lookup(flag) != nil || c.PersistentFlags().Lookup(flag) != nil {
+			return true
+		}
+		return slices.ContainsFunc(c.Commands(), has)
+	}
+	return has(cmd)
+}
+
+// endpoints returns the registry endpoints of the config, leaving out what
+// the environment sets, which takes precedence.
+func (c *config) endpoints() (map[string]integrations.Endpoint, error) {
+	result := make(map[string]integrations.Endpoint, len(c.Registries))
+	for name, r := range c.Registries {
+		if !slices.Contains(integrations.Registries, name) {
+			return nil, fmt.Errorf("%s: unknown registry %q (want %s)", c.path, name, strings.Join(integrations.Registries, ", "))
+		}
+		prefix := "STACKTOWER_" + strings.ToUpper(name) + "_"
+		e := integrations.Endpoint{Headers: maps.Clone(r.Headers)}
+		if os.Getenv(prefix+"URL") == "" {
+			e.URL = r.URL
+		}
+		if os.Getenv(prefix+"TOKEN") != "" {
+			delete(e.Headers, "Authorization")
+		}
+		result[name] = e
+	}
+	return result, nil
+}
+
+// token returns the API token of a forge: its environment variable, such
+// as GITHUB_TOKEN, or else the config's.
+func (c *config) token(forge string) string {
+	return cmp.Or(os.Getenv(strings.ToUpper(forge)+"_TOKEN"), c.Tokens[forge])
+}
+
+const configKey ctxKey = 1
+
+func withConfig(ctx context.Context, c *config) context.Context {
+	return context.WithValue(ctx, configKey, c)
+}
+
+func configFromContext(ctx context.Context) *config {
+	if c, ok := ctx.Value(configKey).(*config); ok {
+		return c
+	}
+	return &config{}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// parseSubcommand returns the named subcommand of a fresh parse command,
+// with args parsed as its command line.
+func parseSubcommand(t *testing.T, name string, args ...string) *cobra.Command {
+	t.Helper()
+	for _, cmd := range newParseCmd().Commands() {
+		if cmd.Name() == name {
+			if err := cmd.ParseFlags(args); err != nil {
+				t.Fatal(err)
+			}
+			return cmd
+		}
+	}
+	t.Fatalf("no parse %s command", name)
+	return nil
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stacktower.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		env     map[string]string
+		args    []string
+		want    map[string]string // flag values
+		err     string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"max-depth": "10", "include-kinds": "[runtime,optional]"},
+		},
+		{
+			name:   "file",
+			config: "parse:\n  max-depth: 6\n",
+			want:   map[string]string{"max-depth": "6"},
+		},
+		{
+			name:   "env over file",
+			config: "parse:\n  max-depth: 6\n",
+			env:    map[string]string{"STACKTOWER_MAX_DEPTH": "4"},
+			want:   map[string]string{"max-depth": "4"},
+		},
+		{
+			name:   "flag over env and file",
+			config: "parse:\n  max-depth: 6\n",
+			env:    map[string]string{"STACKTOWER_MAX_DEPTH": "4"},
+			args:   []string{"--max-depth", "2"},
+			want:   map[string]string{"max-depth": "2"},
+		},
+		{
+			name:    "profile over section",
+			config:  "parse:\n  max-depth: 6\n  max-nodes: 100\nprofiles:\n  deep:\n    parse:\n      max-depth: 20\n",
+			profile: "deep",
+			want:    map[string]string{"max-depth": "20", "max-nodes": "100"},
+		},
+		{
+			name:    "unknown profile",
+			config:  "profiles:\n  deep:\n    parse:\n      max-depth: 20\n",
+			profile: "shallow",
+			err:     `unknown profile "shallow"`,
+		},
+		{
+			name:    "unknown profile section",
+			config:  "profiles:\n  deep:\n    crawl:\n      max-depth: 20\n",
+			profile: "deep",
+			err:     `unknown section "crawl"`,
+		},
+		{
+			name:   "unknown key",
+			config: "parse:\n  max-dept: 6\n",
+			err:    `unknown parse option "max-dept"`,
+		},
+		{
+			name:   "flag of another subcommand",
+			config: "parse:\n  python-version: \"3.11\"\n",
+			want:   map[string]string{"max-depth": "10"},
+		},
+		{
+			name:   "slice from file",
+			config: "parse:\n  include-kinds: [runtime, dev]\n",
+			want:   map[string]string{"include-kinds": "[runtime,dev]"},
+		},
+		{
+			name:   "slice from env",
+			config: "parse:\n  include-kinds: [runtime, dev]\n",
+			env:    map[string]string{"STACKTOWER_INCLUDE_KINDS": "runtime,peer"},
+			want:   map[string]string{"include-kinds": "[runtime,peer]"},
+		},
+		{
+			name:   "slice from flags",
+			config: "parse:\n  include-kinds: [runtime, dev]\n",
+			args:   []string{"--include-kinds", "build", "--include-kinds", "peer"},
+			want:   map[string]string{"include-kinds": "[build,peer]"},
+		},
+		{
+			name:   "references outside tokens and registries",
+			config: "parse:\n  output: ${STACKTOWER_TEST_UNSET}.json\n",
+			want:   map[string]string{"output": "${STACKTOWER_TEST_UNSET}.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tt.config != "" {
+				path = writeConfig(t, tt.config)
+			}
+			cmd := parseSubcommand(t, "rust", tt.args...)
+
+			cfg, err := loadConfig(path, tt.profile)
+			if err == nil {
+				err = applyConfig(cmd, cfg)
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := cmd.Flags().Lookup(name).Value.String(); got != want {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ExpandEnv(t *testing.T) {
+	t.Setenv("STACKTOWER_TEST_TOKEN", "secret")
+	config := `
+tokens:
+  github: ${STACKTOWER_TEST_TOKEN}
+registries:
+  npm:
+    url: https://$STACKTOWER_TEST_TOKEN@npm.example.com
+    headers:
+      Authorization: Bearer ${STACKTOWER_TEST_TOKEN}
+`
+	cfg, err := loadConfig(writeConfig(t, config), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Tokens["github"]; got != "secret" {
+		t.Errorf("tokens.github = %q", got)
+	}
+	npm := cfg.Registries["npm"]
+	if npm.URL != "https://secret@npm.example.com" || npm.Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("registries.npm = %+v", npm)
+	}
+
+	_, err = loadConfig(writeConfig(t, "tokens:\n  gitlab: ${STACKTOWER_TEST_UNSET}\n"), "")
+	if err == nil || !strings.Contains(err.Error(), "STACKTOWER_TEST_UNSET is not set") {
+		t.Errorf("err = %v, want an unset variable error", err)
+	}
+}
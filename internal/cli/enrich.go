@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	pkgio "github.com/matzehuels/stacktower/pkg/io"
+	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/source/metadata"
+)
+
+// ecosystemPURLTypes are the Package URL types of the ecosystems parse
+// records in the graph meta.
+var ecosystemPURLTypes = map[string]string{
+	"dotnet":     source.PURLNuGet,
+	"go":         source.PURLGolang,
+	"java":       source.PURLMaven,
+	"javascript": source.PURLNPM,
+	"php":        source.PURLComposer,
+	"python":     source.PURLPyPI,
+	"ruby":       source.PURLGem,
+	"rust":       source.PURLCargo,
+}
+
+type enrichOpts struct {
+	output    string
+	providers []string
+	osv       string
+	ecosystem string
+	refresh   bool
+	offline   bool
+	limits    []string
+	cache     string
+}
+
+func newEnrichCmd() *cobra.Command {
+	opts := enrichOpts{providers: slices.Clone(forges), cache: integrations.CacheFile}
+
+	cmd := &cobra.Command{
+		Use:   "enrich <graph.json>",
+		Short: "Add repository metadata and vulnerabilities to an existing graph",
+		Long: `Run the metadata providers of "parse --enrich" and "parse --osv" over a graph
+that was already parsed, merged or written by hand, without crawling the
+registries again.
+
+Each package is looked up by its "purl" meta, or else its label or ID and
+"version" meta in the registry of --ecosystem (default: the ecosystem the
+graph was parsed from). Repositories are found from "repo_url", "repository"
+or "homepage" meta, or searched for by package name.`,
+		Example: `  stacktower parse lockfile ./poetry.lock -o deps.json
+  stacktower enrich deps.json --providers github,osv -o enriched.json
+  stacktower enrich handmade.json --ecosystem python --osv all.zip`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEnrich(cmd, args[0], &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "output file (stdout if empty)")
+	cmd.Flags().StringSliceVar(&opts.providers, "providers", opts.providers, "metadata providers to run: "+strings.Join(forges, ", ")+", osv")
+	cmd.Flags().StringVar(&opts.osv, "osv", "", "OSV database export for the osv provider (directory, zip or JSON file), or \"online\" for the osv.dev API (the default); implies --providers osv")
+	cmd.Flags().StringVar(&opts.ecosystem, "ecosystem", "", "ecosystem of packages without purl meta: "+strings.Join(slices.Sorted(maps.Keys(ecosystemPURLTypes)), ", "))
+	cmd.Flags().BoolVar(&opts.refresh, "refresh", false, "bypass cache")
+	cmd.Flags().StringVar(&opts.cache, "cache", opts.cache, cacheUsage)
+	cmd.Flags().BoolVar(&opts.offline, "offline", false, "serve every request from the cache, expired entries included, and never use the network")
+	cmd.Flags().StringSliceVar(&opts.limits, "rate-limit", nil, "requests per second to allow a host, as host=rate[/burst] (e.g. api.github.com=10/20)")
+
+	return cmd
+}
+
+func runEnrich(cmd *cobra.Command, path string, opts *enrichOpts) error {
+	ctx := cmd.Context()
+	if opts.offline && opts.refresh {
+		return fmt.Errorf("--offline and --refresh cannot be used together")
+	}
+	if err := configureClients(ctx, &parseOpts{limits: opts.limits, cache: opts.cache}); err != nil {
+		return err
+	}
+
+	g, err := pkgio.ImportJSON(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	ecosystem := opts.ecosystem
+	if ecosystem == "" {
+		ecosystem, _ = g.Meta()["ecosystem"].(string)
+	}
+	purlType := ecosystemPURLTypes[ecosystem]
+	if opts.ecosystem != "" && purlType == "" {
+		return fmt.Errorf("unknown ecosystem %q", opts.ecosystem)
+	}
+
+	for _, name := range opts.providers {
+		if name != "osv" && !slices.Contains(forges, name) {
+			return fmt.Errorf("--providers: unknown metadata provider %q (want %s, osv)", name, strings.Join(forges, ", "))
+		}
+	}
+	names := slices.DeleteFunc(slices.Clone(opts.providers), func(n string) bool { return n == "osv" })
+	useOSV := opts.osv != "" || len(names) < len(opts.providers)
+	providers, err := buildMetadataProviders(configFromContext(ctx), names)
+	if err != nil {
+		return fmt.Errorf("--providers: %w", err)
+	}
+	logger := loggerFromContext(ctx)
+	cfg := configFromContext(ctx)
+	for _, forge := range []string{"github", "gitlab"} {
+		if slices.Contains(names, forge) && cfg.token(forge) == "" {
+			logger.Warnf("No %s_TOKEN: skipping %s", strings.ToUpper(forge), forge)
+		}
+	}
+	if useOSV {
+		provider, err := buildOSVProvider(ctx, cmp.Or(opts.osv, "online"))
+		if err != nil {
+			return fmt.Errorf("--osv: %w", err)
+		}
+		providers = append(providers, provider)
+		// The advisories found now replace those recorded before.
+		clearVulns(g)
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("no metadata providers to run")
+	}
+
+	logger.Infof("Enriching %d packages", g.NodeCount())
+	prog := newProgress(logger)
+	err = source.Enrich(ctx, g, source.Options{
+		MetadataProviders: providers,
+		PURLType:          purlType,
+		Refresh:           opts.refresh,
+		Offline:           opts.offline,
+		CacheTTL:          source.DefaultCacheTTL,
+		Logger:            func(msg string, args ...any) { logger.Warnf(msg, args...) },
+	})
+	if err != nil {
+		return err
+	}
+	prog.done("Enriched graph")
+	if n := countVulnerable(g); n > 0 {
+		logger.Warnf("%d packages have known vulnerabilities", n)
+	}
+	if err := stamp(g.Meta(), "enriched_at"); err != nil {
+		return err
+	}
+
+	out, err := openOutput(opts.output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := pkgio.WriteJSON(g, out); err != nil {
+		return err
+	}
+	if opts.output != "" {
+		logger.Infof("Wrote graph to %s", opts.output)
+	}
+	return nil
+}
+
+// clearVulns removes the vulns meta of every node.
+func clearVulns(g *dag.DAG) {
+	for _, n := range g.Nodes() {
+		delete(n.Meta, metadata.Vulns)
+	}
+}
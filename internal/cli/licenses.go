@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	pkgio "github.com/matzehuels/stacktower/pkg/io"
+	"github.com/matzehuels/stacktower/pkg/license"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func newLicensesCmd() *cobra.Command {
+	var output, policyPath, project string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "licenses <graph.json>",
+		Short: "Check the licenses of a dependency graph against a policy",
+		Long: `Check the licenses of the packages in a graph written by "stacktower parse".
+
+Reports dependencies whose license the policy denies or does not allow, that
+are incompatible with the project's license, or that have no known license,
+with the dependency paths that bring each in. Unless --project or the policy
+names the project's license, dependencies are checked for compatibility with
+the license of the packages the graph was parsed from.
+
+Exits with an error if any dependency breaks the policy.`,
+		Example: `  stacktower licenses graph.json --policy policy.yaml
+  stacktower licenses graph.json --json -o licenses.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var policy license.Policy
+			if policyPath != "" {
+				p, err := license.LoadPolicy(policyPath)
+				if err != nil {
+					return err
+				}
+				policy = *p
+			}
+			if project != "" {
+				policy.Project = project
+			}
+
+			g, err := pkgio.ImportJSON(args[0])
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			findings, err := license.Check(g, projectRoots(g), policy)
+			if err != nil {
+				return err
+			}
+
+			out, err := openOutput(output)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if findings == nil {
+					findings = []license.Finding{}
+				}
+				err = enc.Encode(findings)
+			} else {
+				err = writeFindings(out, findings)
+			}
+			if err != nil {
+				return err
+			}
+
+			violations := 0
+			for _, f := range findings {
+				if !f.Warning {
+					violations++
+				}
+			}
+			logger := loggerFromContext(cmd.Context())
+			if warnings := len(findings) - violations; warnings > 0 {
+				logger.Warnf("%d packages have no known license", warnings)
+			}
+			if violations > 0 {
+				return fmt.Errorf("%d license violations", violations)
+			}
+			logger.Infof("No license violations in %d packages", g.NodeCount())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output file (stdout if empty)")
+	cmd.Flags().StringVar(&policyPath, "policy", "", "license policy (YAML)")
+	cmd.Flags().StringVar(&project, "project", "", "license the project is distributed under (overrides the policy's)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "write the findings as JSON")
+
+	return cmd
+}
+
+// projectRoots returns the packages a graph was parsed from, leaving out
+// a synthetic root placed above them, which has no license.
+func projectRoots(g *dag.DAG) []string {
+	if roots := source.MetaStrings(g.Meta()["roots"]); len(roots) > 0 {
+		return roots
+	}
+	return graphRoots(g)
+}
+
+// writeFindings lists findings with the dependency paths that bring each
+// in, one per line.
+func writeFindings(w io.Writer, findings []license.Finding) error {
+	for _, f := range findings {
+		label := "error"
+		if f.Warning {
+			label = "warning"
+		}
+		name := f.Package
+		if f.Version != "" {
+			name += " " + f.Version
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s\n", label, name, f.Reason); err != nil {
+			return err
+		}
+		for _, path := range f.Paths {
+			if _, err := fmt.Fprintf(w, "    %s\n", strings.Join(path, " → ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/license"
+)
+
+func TestProjectRoots_SyntheticRoot(t *testing.T) {
+	// As written by parse --root monorepo app.
+	g := dag.New(dag.Metadata{"root": "monorepo", "roots": []any{"app"}})
+	g.AddNode(dag.Node{ID: "monorepo"})
+	g.AddNode(dag.Node{ID: "app", Meta: dag.Metadata{"license": "MIT"}})
+	g.AddNode(dag.Node{ID: "readline", Meta: dag.Metadata{"license": "GPL-3.0-only"}})
+	g.AddEdge(dag.Edge{From: "monorepo", To: "app"})
+	g.AddEdge(dag.Edge{From: "app", To: "readline"})
+
+	findings, err := license.Check(g, projectRoots(g), license.Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Package != "readline" || findings[0].Kind != license.KindIncompatible {
+		t.Errorf("findings = %+v, want readline incompatible with MIT", findings)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	pkgio "github.com/matzehuels/stacktower/pkg/io"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func newMergeCmd() *cobra.Command {
+	var output, root string
+
+	cmd := &cobra.Command{
+		Use:   "merge <graph.json>...",
+		Short: "Merge dependency graphs of several ecosystems into one",
+		Long: `Merge graphs written by "stacktower parse" into one graph that renders as a single tower.
+
+Nodes are identified by their Package URL, so a package of the same name in two
+ecosystems stays two blocks while a package shared by two graphs of the same
+ecosystem becomes one. Graphs parsed without --purl are relabelled on the fly.`,
+		Example: `  stacktower parse python ./backend -o py.json
+  stacktower parse javascript ./frontend -o js.json
+  stacktower merge py.json js.json --root monorepo -o merged.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := mergeGraphs(args, root)
+			if err != nil {
+				return err
+			}
+
+			out, err := openOutput(output)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			if err := pkgio.WriteJSON(g, out); err != nil {
+				return err
+			}
+
+			logger := loggerFromContext(cmd.Context())
+			logger.Infof("Merged %d graphs into %d packages with %d dependencies", len(args), g.NodeCount(), g.EdgeCount())
+			if output != "" {
+				logger.Infof("Wrote graph to %s", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output file (stdout if empty)")
+	cmd.Flags().StringVar(&root, "root", "", "name of a synthetic top node above the merged graphs (default: their roots form the top row)")
+
+	return cmd
+}
+
+// mergeGraphs reads the graphs at paths and merges them by Package URL.
+// The roots of every graph become the roots of the result, below root if
+// it is set.
+func mergeGraphs(paths []string, root string) (*dag.DAG, error) {
+	graphs := make([]*dag.DAG, len(paths))
+	var roots, ecosystems []string
+	for i, path := range paths {
+		g, err := pkgio.ImportJSON(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		g = source.RelabelPURLs(g)
+		graphs[i] = g
+
+		for _, r := range graphRoots(g) {
+			if !slices.Contains(roots, r) {
+				roots = append(roots, r)
+			}
+		}
+		if eco, ok := g.Meta()["ecosystem"].(string); ok && !slices.Contains(ecosystems, eco) {
+			ecosystems = append(ecosystems, eco)
+		}
+	}
+
+	merged := dag.Merge(graphs...)
+	meta := merged.Meta()
+	meta["merged_from"] = paths
+	meta["ecosystems"] = ecosystems
+	meta["roots"] = roots
+	meta["generator"] = "stacktower " + version
+	if err := stamp(meta, "parsed_at"); err != nil {
+		return nil, err
+	}
+
+	if root != "" {
+		if _, ok := merged.Node(root); ok {
+			return nil, fmt.Errorf("root name %q is already a node of the merged graphs", root)
+		}
+		_ = merged.AddNode(dag.Node{ID: root})
+		for _, r := range roots {
+			_ = merged.AddEdge(dag.Edge{From: root, To: r, Meta: map[string]any{"kind": source.KindRuntime}})
+		}
+		meta["root"] = root
+	}
+	return merged, nil
+}
+
+// graphRoots returns the top nodes of a parsed graph: its synthetic root
+// or single root if it has one, otherwise the roots it was parsed from.
+// Graphs without root meta fall back to the nodes nothing depends on.
+func graphRoots(g *dag.DAG) []string {
+	meta := g.Meta()
+	if root, ok := meta["root"].(string); ok {
+		return []string{root}
+	}
+	if roots := source.MetaStrings(meta["roots"]); len(roots) > 0 {
+		return roots
+	}
+	var roots []string
+	for _, n := range g.Nodes() {
+		if g.InDegree(n.ID) == 0 {
+			roots = append(roots, n.ID)
+		}
+	}
+	slices.Sort(roots)
+	return roots
+}
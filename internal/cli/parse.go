@@ -1,16 +1,31 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/httputil"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/integrations/osv"
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
 	pkgio "github.com/matzehuels/stacktower/pkg/io"
 	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/source/dotnet"
+	"github.com/matzehuels/stacktower/pkg/source/golang"
+	"github.com/matzehuels/stacktower/pkg/source/java"
 	"github.com/matzehuels/stacktower/pkg/source/javascript"
+	"github.com/matzehuels/stacktower/pkg/source/lockfile"
 	"github.com/matzehuels/stacktower/pkg/source/metadata"
 	"github.com/matzehuels/stacktower/pkg/source/php"
 	"github.com/matzehuels/stacktower/pkg/source/python"
@@ -23,36 +38,58 @@ type parseOpts struct {
 	maxNodes int
 	enrich   bool
 	refresh  bool
+	offline  bool
 	output   string
+	features []string
+	kinds    []string
+	root     string
+	purl     bool
+	limits   []string
+	urls     []string
+	headers  []string
+	osv      string
+	cache    string
 }
 
 type parserFactory func() (source.Parser, error)
 
 func newParseCmd() *cobra.Command {
-	opts := parseOpts{maxDepth: 10, maxNodes: 5000}
+	opts := parseOpts{maxDepth: 10, maxNodes: 5000, kinds: source.DefaultKinds, cache: integrations.CacheFile}
 
 	cmd := &cobra.Command{
 		Use:   "parse",
 		Short: "Parse dependency graphs from package managers",
-		Long:  `Parse dependency graphs from package managers (PyPI, crates.io, npm) and output as JSON.`,
+		Long:  `Parse dependency graphs from package managers (PyPI, crates.io, npm, Maven Central, NuGet, Go modules) or from a local lockfile and output as JSON.`,
 	}
 
 	cmd.PersistentFlags().IntVar(&opts.maxDepth, "max-depth", opts.maxDepth, "maximum dependency depth")
 	cmd.PersistentFlags().IntVar(&opts.maxNodes, "max-nodes", opts.maxNodes, "maximum nodes to fetch")
 	cmd.PersistentFlags().BoolVar(&opts.enrich, "enrich", false, "enrich with repository metadata")
 	cmd.PersistentFlags().BoolVar(&opts.refresh, "refresh", false, "bypass cache")
+	cmd.PersistentFlags().StringVar(&opts.cache, "cache", opts.cache, cacheUsage)
+	cmd.PersistentFlags().BoolVar(&opts.offline, "offline", false, "serve every request from the cache, expired entries included, and never use the network")
 	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", "", "output file (stdout if empty)")
+	cmd.PersistentFlags().StringVar(&opts.root, "root", "", "name of a synthetic top node above several packages (default: the packages form the top row)")
+	cmd.PersistentFlags().BoolVar(&opts.purl, "purl", false, "identify nodes by Package URL (pkg:pypi/requests@2.31.0) so graphs of different ecosystems can be merged")
+	cmd.PersistentFlags().StringSliceVar(&opts.limits, "rate-limit", nil, "requests per second to allow a host, shared by all workers, as host=rate[/burst] (e.g. crates.io=1,api.github.com=10/20)")
+	cmd.PersistentFlags().StringSliceVar(&opts.urls, "registry-url", nil, "base URL of a registry's API, as registry=url, for mirrors and self-hosted instances (e.g. pypi=https://nexus.example.com/repository/pypi/pypi)")
+	cmd.PersistentFlags().StringArrayVar(&opts.headers, "registry-header", nil, "header to send with every request to a registry, as registry=Name: value (e.g. \"npm=Authorization: Bearer $TOKEN\")")
+	cmd.PersistentFlags().StringVar(&opts.osv, "osv", "", "record known vulnerabilities from an OSV database export (directory, zip or JSON file), or from the osv.dev API with \"online\"")
+	cmd.PersistentFlags().StringSliceVar(&opts.kinds, "include-kinds", opts.kinds, "dependency kinds to crawl: "+strings.Join(source.Kinds, ", "))
 
-	cmd.AddCommand(newParserCmd("python <package>", "Parse Python package dependencies from PyPI",
-		func() (source.Parser, error) { return python.NewParser(source.DefaultCacheTTL) }, &opts))
-	cmd.AddCommand(newParserCmd("rust <crate>", "Parse Rust crate dependencies from crates.io",
-		func() (source.Parser, error) { return rust.NewParser(source.DefaultCacheTTL) }, &opts))
-	cmd.AddCommand(newParserCmd("javascript <package>", "Parse JavaScript package dependencies from npm",
+	cmd.AddCommand(newPythonParserCmd(&opts))
+	cmd.AddCommand(newRustParserCmd(&opts))
+	cmd.AddCommand(newParserCmd("javascript <package|dir>...", "Parse JavaScript package or project dependencies from npm",
 		func() (source.Parser, error) { return javascript.NewParser(source.DefaultCacheTTL) }, &opts))
-	cmd.AddCommand(newParserCmd("ruby <gem>", "Parse Ruby gem dependencies from RubyGems",
+	cmd.AddCommand(newParserCmd("ruby <gem|dir>...", "Parse Ruby gem or project dependencies from RubyGems",
 		func() (source.Parser, error) { return ruby.NewParser(source.DefaultCacheTTL) }, &opts))
-	cmd.AddCommand(newParserCmd("php <package>", "Parse PHP (Composer) package dependencies from Packagist",
+	cmd.AddCommand(newParserCmd("php <package|dir>...", "Parse PHP (Composer) package or project dependencies from Packagist",
 		func() (source.Parser, error) { return php.NewParser(source.DefaultCacheTTL) }, &opts))
+	cmd.AddCommand(newParserCmd("java <groupId:artifactId>...", "Parse Java artifact dependencies from Maven Central",
+		func() (source.Parser, error) { return java.NewParser(source.DefaultCacheTTL) }, &opts))
+	cmd.AddCommand(newDotnetParserCmd(&opts))
+	cmd.AddCommand(newGoParserCmd(&opts))
+	cmd.AddCommand(newLockfileParserCmd(&opts))
 
 	return cmd
 }
@@ -61,26 +98,143 @@ func newParserCmd(use, short string, factory parserFactory, opts *parseOpts) *co
 	return &cobra.Command{
 		Use:   use,
 		Short: short,
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureClients(cmd.Context(), opts); err != nil {
+				return err
+			}
 			p, err := factory()
 			if err != nil {
 				return err
 			}
-			return runParse(cmd.Context(), p, args[0], opts)
+			return runParse(cmd.Context(), p, cmd.Name(), args, opts)
 		},
 	}
 }
 
-func runParse(ctx context.Context, p source.Parser, pkg string, opts *parseOpts) error {
+func newLockfileParserCmd(opts *parseOpts) *cobra.Command {
+	cmd := newParserCmd("lockfile <path>", "Parse a lockfile (package-lock.json, poetry.lock, uv.lock, Cargo.lock, Gemfile.lock, composer.lock)",
+		func() (source.Parser, error) { return lockfile.NewParser(), nil }, opts)
+	cmd.Args = cobra.ExactArgs(1)
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// The lockfile bounds the graph; only limits the user sets apply.
+		if !cmd.Flags().Changed("max-depth") {
+			opts.maxDepth = 0
+		}
+		if !cmd.Flags().Changed("max-nodes") {
+			opts.maxNodes = 0
+		}
+		return run(cmd, args)
+	}
+	return cmd
+}
+
+func newPythonParserCmd(opts *parseOpts) *cobra.Command {
+	env := pypi.DefaultEnvironment
+	cmd := newParserCmd("python <package[extras]|dir>...", "Parse Python package or project dependencies from PyPI",
+		func() (source.Parser, error) { return python.NewParser(env, source.DefaultCacheTTL) }, opts)
+	cmd.Flags().StringSliceVar(&opts.features, "features", nil, "extras to enable on the root package (also accepted as package[extra,...])")
+	cmd.Flags().StringVar(&env.PythonVersion, "python-version", env.PythonVersion, "target Python version for environment markers")
+	cmd.Flags().StringVar(&env.Platform, "platform", env.Platform, "target platform for environment markers (linux, darwin, win32)")
+	cmd.Flags().StringVar(&env.Implementation, "implementation", env.Implementation, "target Python implementation for environment markers (cpython, pypy)")
+	return cmd
+}
+
+func newRustParserCmd(opts *parseOpts) *cobra.Command {
+	cmd := newParserCmd("rust <crate|dir>...", "Parse Rust crate or project dependencies from crates.io",
+		func() (source.Parser, error) { return rust.NewParser(source.DefaultCacheTTL) }, opts)
+	cmd.Flags().StringSliceVar(&opts.features, "features", nil, "Cargo features to enable on the root crate, in addition to its defaults")
+	return cmd
+}
+
+func newDotnetParserCmd(opts *parseOpts) *cobra.Command {
+	var framework string
+	cmd := newParserCmd("dotnet <package>...", "Parse .NET package dependencies from NuGet",
+		func() (source.Parser, error) { return dotnet.NewParser(framework, source.DefaultCacheTTL) }, opts)
+	cmd.Flags().StringVar(&framework, "framework", dotnet.DefaultFramework, "target framework moniker used to pick dependency groups (e.g. net8.0, netstandard2.0, net472)")
+	return cmd
+}
+
+func newGoParserCmd(opts *parseOpts) *cobra.Command {
+	var proxy string
+	cmd := newParserCmd("go <module>...", "Parse Go module dependencies from a module proxy",
+		func() (source.Parser, error) { return golang.NewParser(proxy, source.DefaultCacheTTL) }, opts)
+	cmd.Flags().StringVar(&proxy, "proxy", "", "module proxy URL, https:// or file:// (default: $GOPROXY or https://proxy.golang.org)")
+	return cmd
+}
+
+const cacheUsage = `where to cache HTTP responses: "file" (~/.cache/stacktower) or "memory" (for this run only)`
+
+// configureClients applies the cache backend and rate limits of opts, and
+// the registry endpoints of opts over those of the config, to the clients
+// created afterwards.
+func configureClients(ctx context.Context, opts *parseOpts) error {
+	if err := integrations.SetCacheBackend(cmp.Or(opts.cache, integrations.CacheFile)); err != nil {
+		return fmt.Errorf("--cache: %w", err)
+	}
+	for _, l := range opts.limits {
+		host, limit, err := httputil.ParseRateLimit(l)
+		if err != nil {
+			return fmt.Errorf("--rate-limit: %w", err)
+		}
+		httputil.SetRateLimit(host, limit.Rate, limit.Burst)
+	}
+	endpoints, err := configFromContext(ctx).endpoints()
+	if err != nil {
+		return err
+	}
+	flags, err := integrations.ParseEndpoints(opts.urls, opts.headers)
+	if err != nil {
+		return err
+	}
+	for registry, f := range flags {
+		e := endpoints[registry]
+		e.URL = cmp.Or(f.URL, e.URL)
+		if e.Headers == nil {
+			e.Headers = make(map[string]string)
+		}
+		maps.Copy(e.Headers, f.Headers)
+		endpoints[registry] = e
+	}
+	for registry, e := range endpoints {
+		integrations.SetEndpoint(registry, e)
+	}
+	return nil
+}
+
+func runParse(ctx context.Context, p source.Parser, ecosystem string, pkgs []string, opts *parseOpts) error {
+	if opts.offline && opts.refresh {
+		return fmt.Errorf("--offline and --refresh cannot be used together")
+	}
+	for _, k := range opts.kinds {
+		if !slices.Contains(source.Kinds, k) {
+			return fmt.Errorf("unknown dependency kind %q (want %s)", k, strings.Join(source.Kinds, ", "))
+		}
+	}
+
 	logger := loggerFromContext(ctx)
-	logger.Infof("Parsing %s dependencies", pkg)
+	logger.Infof("Parsing %s dependencies", strings.Join(pkgs, ", "))
 
-	providers, err := buildMetadataProviders(opts.enrich)
+	var names []string
+	if opts.enrich {
+		names = forges
+	}
+	providers, err := buildMetadataProviders(configFromContext(ctx), names)
 	if err != nil {
 		logger.Warnf("Metadata enrichment disabled: %v", err)
 	} else if len(providers) > 0 {
 		logger.Debugf("Metadata enrichment enabled (%d providers)", len(providers))
+		if cfg := configFromContext(ctx); cfg.token("github") == "" && cfg.token("gitlab") == "" {
+			logger.Warn("No GITHUB_TOKEN or GITLAB_TOKEN: only repositories on Codeberg, the gitea endpoint and Bitbucket are enriched")
+		}
+	}
+	if opts.osv != "" {
+		provider, err := buildOSVProvider(ctx, opts.osv)
+		if err != nil {
+			return fmt.Errorf("--osv: %w", err)
+		}
+		providers = append(providers, provider)
 	}
 
 	srcOpts := source.Options{
@@ -88,17 +242,35 @@ func runParse(ctx context.Context, p source.Parser, pkg string, opts *parseOpts)
 		MaxNodes:          opts.maxNodes,
 		MetadataProviders: providers,
 		Refresh:           opts.refresh,
+		Offline:           opts.offline,
+		Features:          opts.features,
+		IncludeKinds:      opts.kinds,
+		RootName:          opts.root,
+		PURLIDs:           opts.purl,
 		CacheTTL:          source.DefaultCacheTTL,
 		Logger:            func(msg string, args ...any) { logger.Warnf(msg, args...) },
 	}
 
 	logger.Info("Resolving dependency graph")
 	prog := newProgress(logger)
-	g, err := p.Parse(ctx, pkg, srcOpts)
+	g, err := p.Parse(ctx, pkgs, srcOpts)
 	if err != nil {
 		return err
 	}
 	prog.done(fmt.Sprintf("Resolved %d packages with %d dependencies", g.NodeCount(), g.EdgeCount()))
+	if failed, truncated := countIncomplete(g); failed+truncated > 0 {
+		logger.Warnf("Graph is incomplete: %d packages failed to fetch, %d not fully crawled (see --max-depth, --max-nodes)", failed, truncated)
+	}
+	if n := countVulnerable(g); n > 0 {
+		logger.Warnf("%d packages have known vulnerabilities", n)
+	}
+
+	meta := g.Meta()
+	meta["ecosystem"] = ecosystem
+	meta["generator"] = "stacktower " + version
+	if err := stamp(meta, "parsed_at"); err != nil {
+		return err
+	}
 
 	out, err := openOutput(opts.output)
 	if err != nil {
@@ -116,31 +288,101 @@ func runParse(ctx context.Context, p source.Parser, pkg string, opts *parseOpts)
 	return nil
 }
 
-func buildMetadataProviders(enrich bool) ([]source.MetadataProvider, error) {
-	if !enrich {
-		return nil, nil
+// countIncomplete counts the nodes marked with fetch_error and truncated.
+func countIncomplete(g *dag.DAG) (failed, truncated int) {
+	for _, n := range g.Nodes() {
+		if _, ok := n.Meta["fetch_error"]; ok {
+			failed++
+		} else if n.Meta["truncated"] == true {
+			truncated++
+		}
+	}
+	return failed, truncated
+}
+
+// countVulnerable counts the nodes with vulns meta.
+func countVulnerable(g *dag.DAG) int {
+	n := 0
+	for _, node := range g.Nodes() {
+		if _, ok := node.Meta[metadata.Vulns]; ok {
+			n++
+		}
 	}
+	return n
+}
+
+// forges are the metadata providers of --enrich, which look packages up
+// on the forge hosting their repository.
+var forges = []string{"github", "gitlab", "gitea", "bitbucket"}
 
+// buildMetadataProviders creates the forge providers of names. GitHub and
+// GitLab are left out without a token; Codeberg and Bitbucket answer
+// without one.
+func buildMetadataProviders(cfg *config, names []string) ([]source.MetadataProvider, error) {
 	var providers []source.MetadataProvider
-	if tok := os.Getenv("GITHUB_TOKEN"); tok != "" {
-		gh, err := metadata.NewGitHub(tok, source.DefaultCacheTTL)
+	for _, name := range names {
+		var p source.MetadataProvider
+		var err error
+		switch name {
+		case "github":
+			if tok := cfg.token(name); tok != "" {
+				p, err = metadata.NewGitHub(tok, source.DefaultCacheTTL)
+			}
+		case "gitlab":
+			if tok := cfg.token(name); tok != "" {
+				p, err = metadata.NewGitLab(tok, source.DefaultCacheTTL)
+			}
+		case "gitea":
+			p, err = metadata.NewGitea(cfg.token(name), source.DefaultCacheTTL)
+		case "bitbucket":
+			p, err = metadata.NewBitbucket(cfg.token(name), source.DefaultCacheTTL)
+		default:
+			return nil, fmt.Errorf("unknown metadata provider %q (want %s)", name, strings.Join(forges, ", "))
+		}
 		if err != nil {
-			return nil, fmt.Errorf("github: %w", err)
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if p != nil {
+			providers = append(providers, p)
 		}
-		providers = append(providers, gh)
 	}
-	if tok := os.Getenv("GITLAB_TOKEN"); tok != "" {
-		gl, err := metadata.NewGitLab(tok, source.DefaultCacheTTL)
+	return providers, nil
+}
+
+// buildOSVProvider looks up vulnerabilities in the OSV database export at
+// path, or queries the osv.dev API if path is "online".
+func buildOSVProvider(ctx context.Context, path string) (source.MetadataProvider, error) {
+	logger := loggerFromContext(ctx)
+	logf := func(msg string, args ...any) { logger.Warnf(msg, args...) }
+	if path == "online" {
+		c, err := osv.NewClient(source.DefaultCacheTTL)
 		if err != nil {
-			return nil, fmt.Errorf("gitlab: %w", err)
+			return nil, err
 		}
-		providers = append(providers, gl)
+		return metadata.NewOSV(c, logf), nil
 	}
+	db, err := osv.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("Loaded %d advisories from %s", db.Len(), path)
+	return metadata.NewOSV(db, logf), nil
+}
 
-	if len(providers) == 0 {
-		return nil, fmt.Errorf("no tokens found (GITHUB_TOKEN, GITLAB_TOKEN)")
+// stamp records the time of SOURCE_DATE_EPOCH under key in graph meta.
+// Without it graphs carry no timestamp, so parsing the same packages twice
+// writes the same file.
+func stamp(meta dag.Metadata, key string) error {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return nil
 	}
-	return providers, nil
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return fmt.Errorf("SOURCE_DATE_EPOCH: %w", err)
+	}
+	meta[key] = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+	return nil
 }
 
 type nopCloser struct{ io.Writer }
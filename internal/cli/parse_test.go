@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/source/lockfile"
+)
+
+const cargoLock = `
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = ["serde", "syn 2.0.48"]
+
+[[package]]
+name = "serde"
+version = "1.0.195"
+dependencies = ["syn 1.0.109"]
+
+[[package]]
+name = "syn"
+version = "1.0.109"
+
+[[package]]
+name = "syn"
+version = "2.0.48"
+`
+
+func quietContext() context.Context {
+	return withLogger(context.Background(), log.New(io.Discard))
+}
+
+func TestRunParse_Reproducible(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "Cargo.lock")
+	if err := os.WriteFile(lock, []byte(cargoLock), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(name string) []byte {
+		opts := &parseOpts{maxDepth: 10, maxNodes: 5000, kinds: source.DefaultKinds, output: filepath.Join(dir, name)}
+		if err := runParse(quietContext(), lockfile.NewParser(), "lockfile", []string{lock}, opts); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(opts.output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	first, second := parse("a.json"), parse("b.json")
+	if !bytes.Equal(first, second) {
+		t.Errorf("two parses differ:\n%s\n%s", first, second)
+	}
+	if bytes.Contains(first, []byte("parsed_at")) {
+		t.Error("parsed_at written without SOURCE_DATE_EPOCH")
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if got := parse("c.json"); !strings.Contains(string(got), `"parsed_at": "2023-11-14T22:13:20Z"`) {
+		t.Errorf("parsed_at not taken from SOURCE_DATE_EPOCH:\n%s", got)
+	}
+}
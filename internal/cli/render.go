@@ -44,6 +44,7 @@ type renderOpts struct {
 	nebraska     bool
 	popups       bool
 	topDown      bool
+	licenses     bool
 }
 
 func newRenderCmd() *cobra.Command {
@@ -83,6 +84,7 @@ func newRenderCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&opts.nebraska, "nebraska", false, "show Nebraska guy ranking (handdrawn)")
 	cmd.Flags().BoolVar(&opts.popups, "popups", false, "show hover popups (handdrawn)")
 	cmd.Flags().BoolVar(&opts.topDown, "top-down", false, "use top-down width flow (roots get equal width)")
+	cmd.Flags().BoolVar(&opts.licenses, "license-colors", false, "color blocks by license family (tower)")
 
 	return cmd
 }
@@ -337,6 +339,9 @@ func buildRenderOpts(g *dag.DAG, opts *renderOpts) []tower.RenderOption {
 	if opts.merge {
 		result = append(result, tower.WithMerged())
 	}
+	if opts.licenses {
+		result = append(result, tower.WithLicenseColors())
+	}
 	if opts.style == styleHanddrawn {
 		result = append(result, tower.WithStyle(handdrawn.New(defaultSeed)))
 		if opts.nebraska {
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
@@ -23,6 +24,7 @@ func SetVersion(v, c, d string) {
 
 func Execute() error {
 	var verbose bool
+	var configPath, profile string
 
 	root := &cobra.Command{
 		Use:          "stacktower",
@@ -30,21 +32,44 @@ func Execute() error {
 		Long:         `StackTower is a CLI tool for visualizing complex dependency graphs as tiered tower structures, making it easier to understand layering and flow.`,
 		Version:      version,
 		SilenceUsage: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			path, err := findConfig(cmp.Or(configPath, os.Getenv("STACKTOWER_CONFIG")))
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(path, cmp.Or(profile, os.Getenv("STACKTOWER_PROFILE")))
+			if err != nil {
+				return err
+			}
+			if err := applyConfig(cmd, cfg); err != nil {
+				return err
+			}
+
 			level := charmlog.InfoLevel
 			if verbose {
 				level = charmlog.DebugLevel
 			}
-			ctx := withLogger(cmd.Context(), newLogger(os.Stderr, level))
+			logger := newLogger(os.Stderr, level)
+			if path != "" {
+				logger.Debugf("Using config %s", path)
+			}
+			ctx := withConfig(withLogger(cmd.Context(), logger), cfg)
 			cmd.SetContext(ctx)
+			return nil
 		},
 	}
 
 	root.SetVersionTemplate(fmt.Sprintf("stacktower %s\ncommit: %s\nbuilt: %s\n", version, commit, date))
 	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose logging")
+	root.PersistentFlags().StringVar(&configPath, "config", "", "config file (default: stacktower.yaml in the current directory, then in ~/.config/stacktower)")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "named profile of the config file to apply")
 
 	root.AddCommand(newParseCmd())
 	root.AddCommand(newRenderCmd())
+	root.AddCommand(newMergeCmd())
+	root.AddCommand(newEnrichCmd())
+	root.AddCommand(newLicensesCmd())
+	root.AddCommand(newCacheCmd())
 	root.AddCommand(newPQTreeCmd())
 
 	return root.ExecuteContext(context.Background())
@@ -0,0 +1,65 @@
+package dag
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Relabel returns a copy of d in which every node's ID is replaced by
+// id(n). Nodes that end up with the same ID are merged as by Merge.
+func (d *DAG) Relabel(id func(n *Node) string) *DAG {
+	out := New(maps.Clone(d.meta))
+	ids := make(map[string]string, len(d.nodes))
+	for _, n := range d.sortedNodes() {
+		ids[n.ID] = id(n)
+		out.mergeNode(*n, ids[n.ID])
+	}
+	for _, e := range d.edges {
+		out.mergeEdge(Edge{From: ids[e.From], To: ids[e.To], Meta: e.Meta})
+	}
+	return out
+}
+
+// Merge combines graphs into one. Nodes with the same ID are merged, the
+// first graph's value winning for each meta key, and an edge between two
+// nodes is kept once. The result has empty graph meta.
+func Merge(graphs ...*DAG) *DAG {
+	out := New(nil)
+	for _, g := range graphs {
+		for _, n := range g.sortedNodes() {
+			out.mergeNode(*n, n.ID)
+		}
+		for _, e := range g.edges {
+			out.mergeEdge(e)
+		}
+	}
+	return out
+}
+
+func (d *DAG) mergeNode(n Node, id string) {
+	if existing, ok := d.nodes[id]; ok {
+		for k, v := range n.Meta {
+			if _, ok := existing.Meta[k]; !ok {
+				existing.Meta[k] = v
+			}
+		}
+		return
+	}
+	n.ID = id
+	n.Meta = maps.Clone(n.Meta)
+	_ = d.AddNode(n)
+}
+
+func (d *DAG) mergeEdge(e Edge) {
+	if e.From == e.To || slices.Contains(d.outgoing[e.From], e.To) {
+		return
+	}
+	_ = d.AddEdge(Edge{From: e.From, To: e.To, Meta: maps.Clone(e.Meta)})
+}
+
+func (d *DAG) sortedNodes() []*Node {
+	nodes := d.Nodes()
+	slices.SortFunc(nodes, func(a, b *Node) int { return strings.Compare(a.ID, b.ID) })
+	return nodes
+}
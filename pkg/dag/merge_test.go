@@ -0,0 +1,71 @@
+package dag
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRelabel(t *testing.T) {
+	g := New(Metadata{"root": "a"})
+	_ = g.AddNode(Node{ID: "a", Meta: Metadata{"version": "1"}})
+	_ = g.AddNode(Node{ID: "b"})
+	_ = g.AddNode(Node{ID: "B"})
+	_ = g.AddEdge(Edge{From: "a", To: "b", Meta: Metadata{"kind": "runtime"}})
+	_ = g.AddEdge(Edge{From: "a", To: "B"})
+
+	out := g.Relabel(func(n *Node) string { return "x:" + n.ID })
+	if out.Meta()["root"] != "a" {
+		t.Errorf("graph meta not copied: %v", out.Meta())
+	}
+	if n, ok := out.Node("x:a"); !ok || n.Meta["version"] != "1" {
+		t.Errorf("node x:a = %v, want version meta", n)
+	}
+	if _, ok := g.Node("a"); !ok {
+		t.Error("Relabel modified the original graph")
+	}
+
+	merged := g.Relabel(func(n *Node) string {
+		if n.ID == "B" {
+			return "b"
+		}
+		return n.ID
+	})
+	if merged.NodeCount() != 2 || merged.EdgeCount() != 1 {
+		t.Errorf("got %d nodes and %d edges, want 2 and 1", merged.NodeCount(), merged.EdgeCount())
+	}
+	if e := merged.Edges()[0]; e.Meta["kind"] != "runtime" {
+		t.Errorf("edge meta = %v, want the first edge's", e.Meta)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	py := New(Metadata{"ecosystem": "python"})
+	_ = py.AddNode(Node{ID: "pkg:pypi/app", Meta: Metadata{"label": "app"}})
+	_ = py.AddNode(Node{ID: "pkg:pypi/requests", Meta: Metadata{"label": "requests", "version": "2.31.0"}})
+	_ = py.AddEdge(Edge{From: "pkg:pypi/app", To: "pkg:pypi/requests"})
+
+	js := New(Metadata{"ecosystem": "javascript"})
+	_ = js.AddNode(Node{ID: "pkg:npm/web", Meta: Metadata{"label": "web"}})
+	_ = js.AddNode(Node{ID: "pkg:npm/requests", Meta: Metadata{"label": "requests"}})
+	_ = js.AddNode(Node{ID: "pkg:pypi/requests", Meta: Metadata{"version": "2.0.0", "license": "Apache-2.0"}})
+	_ = js.AddEdge(Edge{From: "pkg:npm/web", To: "pkg:npm/requests"})
+	_ = js.AddEdge(Edge{From: "pkg:npm/web", To: "pkg:pypi/requests"})
+
+	g := Merge(py, js)
+	if len(g.Meta()) != 0 {
+		t.Errorf("Meta() = %v, want empty", g.Meta())
+	}
+	if g.NodeCount() != 4 || g.EdgeCount() != 3 {
+		t.Errorf("got %d nodes and %d edges, want 4 and 3", g.NodeCount(), g.EdgeCount())
+	}
+	n, _ := g.Node("pkg:pypi/requests")
+	if n.Meta["version"] != "2.31.0" {
+		t.Errorf("version = %v, want the first graph's", n.Meta["version"])
+	}
+	if n.Meta["license"] != "Apache-2.0" {
+		t.Errorf("license = %v, want it filled in from the second graph", n.Meta["license"])
+	}
+	if parents := g.Parents("pkg:pypi/requests"); !slices.Equal(slices.Sorted(slices.Values(parents)), []string{"pkg:npm/web", "pkg:pypi/app"}) {
+		t.Errorf("Parents() = %v", parents)
+	}
+}
@@ -2,6 +2,7 @@ package transform
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/matzehuels/stacktower/pkg/dag"
 )
@@ -24,9 +25,9 @@ func subdivideLongEdges(g *dag.DAG, gen *idGen) {
 		toRemove = append(toRemove, e)
 		prevID := src.ID
 		for row := src.Row + 1; row < dst.Row; row++ {
-			prevID = addSubdivider(g, gen, prevID, src.ID, row)
+			prevID = addSubdivider(g, gen, prevID, src.ID, row, e.Meta)
 		}
-		if err := g.AddEdge(dag.Edge{From: prevID, To: dst.ID, Meta: e.Meta}); err != nil {
+		if err := g.AddEdge(dag.Edge{From: prevID, To: dst.ID, Meta: maps.Clone(e.Meta)}); err != nil {
 			panic(err)
 		}
 	}
@@ -36,7 +37,10 @@ func subdivideLongEdges(g *dag.DAG, gen *idGen) {
 	}
 }
 
-func addSubdivider(g *dag.DAG, gen *idGen, from, master string, row int) string {
+// addSubdivider appends a subdivider below from; the edge to it carries a
+// copy of meta, so every segment of a subdivided edge keeps the original's
+// and can be changed on its own.
+func addSubdivider(g *dag.DAG, gen *idGen, from, master string, row int, meta dag.Metadata) string {
 	id := gen.next(master, row)
 	if err := g.AddNode(dag.Node{
 		ID:       id,
@@ -46,7 +50,7 @@ func addSubdivider(g *dag.DAG, gen *idGen, from, master string, row int) string
 	}); err != nil {
 		panic(err)
 	}
-	if err := g.AddEdge(dag.Edge{From: from, To: id}); err != nil {
+	if err := g.AddEdge(dag.Edge{From: from, To: id, Meta: maps.Clone(meta)}); err != nil {
 		panic(err)
 	}
 	return id
@@ -60,7 +64,7 @@ func extendSinksToBottom(g *dag.DAG, gen *idGen) {
 		}
 		prevID := n.ID
 		for row := n.Row + 1; row <= maxRow; row++ {
-			prevID = addSubdivider(g, gen, prevID, n.EffectiveID(), row)
+			prevID = addSubdivider(g, gen, prevID, n.EffectiveID(), row, nil)
 		}
 	}
 }
@@ -326,3 +326,23 @@ func findPath(g *dag.DAG, from, to string) []string {
 
 	return nil
 }
+
+func TestSubdivide_SegmentsOwnMeta(t *testing.T) {
+	g := dag.New(nil)
+	_ = g.AddNode(dag.Node{ID: "a", Row: 0})
+	_ = g.AddNode(dag.Node{ID: "b", Row: 3})
+	_ = g.AddEdge(dag.Edge{From: "a", To: "b", Meta: dag.Metadata{"kind": "dev"}})
+
+	Subdivide(g)
+
+	edges := g.Edges()
+	if len(edges) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(edges))
+	}
+	edges[0].Meta["kind"] = "build"
+	for _, e := range edges[1:] {
+		if e.Meta["kind"] != "dev" {
+			t.Errorf("%s -> %s: kind = %v, want dev", e.From, e.To, e.Meta["kind"])
+		}
+	}
+}
@@ -191,10 +191,10 @@ func TestSubdivide_PreservesMetadata(t *testing.T) {
 
 	Subdivide(g)
 
-	edges := g.Edges()
-	finalEdge := edges[len(edges)-1]
-	if finalEdge.Meta["key"] != "value" {
-		t.Error("expected metadata preserved on final edge")
+	for _, e := range g.Edges() {
+		if e.Meta["key"] != "value" {
+			t.Errorf("expected metadata preserved on edge %s->%s", e.From, e.To)
+		}
 	}
 }
 
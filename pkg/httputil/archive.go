@@ -0,0 +1,114 @@
+package httputil
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Prune deletes the entries stored before now minus age and returns how
+// many it deleted.
+func Prune(c Cache, age time.Duration) (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-age)
+	n := 0
+	for _, e := range entries {
+		if !e.Stored.Before(cutoff) {
+			continue
+		}
+		if err := c.Delete(e.Key); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Export writes every entry of c to w as a gzipped tarball with one JSON
+// file per entry, and returns how many it wrote. Import reads it back into
+// any Cache.
+func Export(c Cache, w io.Writer) (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	n := 0
+	for _, meta := range entries {
+		e, ok, err := c.Load(meta.Key)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			continue // removed since listing
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return n, err
+		}
+		h := sha256.Sum256([]byte(e.Key))
+		hdr := &tar.Header{
+			Name:    hex.EncodeToString(h[:]) + ".json",
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: e.Stored,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return n, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := tw.Close(); err != nil {
+		return n, err
+	}
+	return n, gz.Close()
+}
+
+// Import stores the entries of a tarball written by Export in c, keeping
+// the time each was stored, and returns how many it stored.
+func Import(c Cache, r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	n := 0
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		var e Entry
+		if err := json.NewDecoder(tr).Decode(&e); err != nil {
+			return n, fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+		if e.Key == "" {
+			return n, fmt.Errorf("%s: entry without a key", hdr.Name)
+		}
+		if err := c.Store(e); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
@@ -0,0 +1,40 @@
+package httputil
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	src := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
+	old := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	if err := src.Store(Entry{Key: "pypi:package:old", Stored: old, Value: []byte(`"old"`)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Set("pypi:package:new", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := Export(src, &buf); err != nil || n != 2 {
+		t.Fatalf("Export() = %d, %v; want 2 entries", n, err)
+	}
+
+	dst := NewMemoryCache(time.Hour)
+	if n, err := Import(dst, &buf); err != nil || n != 2 {
+		t.Fatalf("Import() = %d, %v; want 2 entries", n, err)
+	}
+	e, ok, _ := dst.Load("pypi:package:old")
+	if !ok || !e.Stored.Equal(old) || string(e.Value) != `"old"` {
+		t.Errorf("imported entry = %+v, want the exported one", e)
+	}
+
+	if n, err := Prune(dst, 24*time.Hour); err != nil || n != 1 {
+		t.Fatalf("Prune() = %d, %v; want 1 entry", n, err)
+	}
+	var v string
+	if ok, _ := dst.Get("pypi:package:new", &v); !ok || v != "new" {
+		t.Errorf("Prune() removed a recent entry")
+	}
+}
@@ -7,60 +7,188 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 )
 
 var ErrExpired = errors.New("cache entry expired")
 
-type Cache struct {
+// Cache stores JSON-encoded responses by key. Get treats entries older
+// than the cache's TTL as expired; GetStale does not. Entries lists what
+// is stored without the values, which Load and Store read and write as
+// they are, for moving entries between caches.
+type Cache interface {
+	Get(key string, v any) (bool, error)
+	GetStale(key string, v any) (bool, error)
+	Set(key string, v any) error
+	Delete(key string) error
+	Entries() ([]Entry, error)
+	Load(key string) (Entry, bool, error)
+	Store(e Entry) error
+	Clear() error
+}
+
+// Entry is a cached value with the key it is stored under. Size is the
+// length of the encoded value, which Entries fills in without Value.
+//
+// URL, ETag and LastModified are the validators of the response the value
+// was made from, if it was made from one, for revalidating it once it has
+// expired.
+type Entry struct {
+	Key          string          `json:"key"`
+	Stored       time.Time       `json:"stored_at"`
+	Value        json.RawMessage `json:"value"`
+	Size         int             `json:"-"`
+	URL          string          `json:"url,omitempty"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+}
+
+// DefaultCacheDir is where FileCache keeps its entries unless told
+// otherwise.
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "stacktower"), nil
+}
+
+// FileCache keeps one file per entry in Dir, named by the SHA-256 of the
+// key. Each file holds the Entry, so keys can be listed. Files written
+// before entries carried their key are misses and only removed by Clear.
+type FileCache struct {
 	Dir string
 	TTL time.Duration
 }
 
-func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+func NewFileCache(dir string, ttl time.Duration) (*FileCache, error) {
 	if dir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
 			return nil, err
 		}
-		dir = filepath.Join(home, ".cache", "stacktower")
 	}
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, err
 	}
-	return &Cache{Dir: dir, TTL: ttl}, nil
+	return &FileCache{Dir: dir, TTL: ttl}, nil
+}
+
+func (c *FileCache) Get(key string, v any) (bool, error) {
+	return c.get(key, v, c.TTL)
+}
+
+// GetStale is Get without the TTL: an expired entry is still a hit.
+func (c *FileCache) GetStale(key string, v any) (bool, error) {
+	return c.get(key, v, 0)
 }
 
-func (c *Cache) Get(key string, v any) (bool, error) {
-	path := c.path(key)
-	info, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		return false, nil
+func (c *FileCache) get(key string, v any, ttl time.Duration) (bool, error) {
+	e, ok, err := c.Load(key)
+	if !ok || err != nil {
+		return false, err
 	}
-	if err != nil {
+	if ttl > 0 && time.Since(e.Stored) > ttl {
+		return false, ErrExpired
+	}
+	// An entry written in an older shape is a miss, not a hit.
+	if err := json.Unmarshal(e.Value, v); err != nil {
 		return false, err
 	}
+	return true, nil
+}
 
-	if c.TTL > 0 && time.Since(info.ModTime()) > c.TTL {
-		return false, ErrExpired
+func (c *FileCache) Set(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Store(Entry{Key: key, Stored: time.Now(), Value: data})
+}
+
+func (c *FileCache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
 	}
+	return err
+}
+
+func (c *FileCache) Entries() ([]Entry, error) {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		e, ok, _ := c.read(filepath.Join(c.Dir, f.Name()))
+		if !ok {
+			continue
+		}
+		e.Size, e.Value = len(e.Value), nil
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Key, b.Key) })
+	return entries, nil
+}
+
+func (c *FileCache) Load(key string) (Entry, bool, error) {
+	e, ok, err := c.read(c.path(key))
+	if !ok || e.Key != key {
+		return Entry{}, false, err
+	}
+	e.Size = len(e.Value)
+	return e, true, nil
+}
 
+// read decodes the entry in a file. A missing file, or one in an older
+// shape, is not an entry.
+func (c *FileCache) read(path string) (Entry, bool, error) {
 	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Entry{}, false, nil
+	}
 	if err != nil {
-		return false, err
+		return Entry{}, false, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key == "" {
+		return Entry{}, false, nil
 	}
-	return true, json.Unmarshal(data, v)
+	return e, true, nil
 }
 
-func (c *Cache) Set(key string, v any) error {
-	data, err := json.Marshal(v)
+func (c *FileCache) Store(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(e.Key), data, 0o644)
+}
+
+// Clear removes every file in Dir, including those of older versions.
+func (c *FileCache) Clear() error {
+	files, err := os.ReadDir(c.Dir)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(c.path(key), data, 0o644)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.Dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (c *Cache) path(key string) string {
+func (c *FileCache) path(key string) string {
 	h := sha256.Sum256([]byte(key))
 	return filepath.Join(c.Dir, hex.EncodeToString(h[:]))
 }
@@ -4,12 +4,13 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
 
 func TestCache_GetSet(t *testing.T) {
-	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	c := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
 
 	tests := []struct {
 		name  string
@@ -49,7 +50,7 @@ func TestCache_GetSet(t *testing.T) {
 }
 
 func TestCache_Miss(t *testing.T) {
-	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	c := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
 	var result string
 	ok, err := c.Get("missing", &result)
 	if err != nil {
@@ -61,7 +62,7 @@ func TestCache_Miss(t *testing.T) {
 }
 
 func TestCache_Expiration(t *testing.T) {
-	c := &Cache{Dir: t.TempDir(), TTL: 10 * time.Millisecond}
+	c := &FileCache{Dir: t.TempDir(), TTL: 10 * time.Millisecond}
 
 	if err := c.Set("key", "value"); err != nil {
 		t.Fatalf("Set() failed: %v", err)
@@ -82,10 +83,15 @@ func TestCache_Expiration(t *testing.T) {
 	if ok {
 		t.Error("Get() returned true for expired key")
 	}
+
+	ok, err = c.GetStale("key", &res)
+	if err != nil || !ok || res != "value" {
+		t.Errorf("GetStale() = %v, %v, %q; want true, nil, value", ok, err, res)
+	}
 }
 
 func TestCache_KeyStability(t *testing.T) {
-	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	c := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
 	p1 := c.path("test")
 	p2 := c.path("test")
 	if p1 != p2 {
@@ -97,15 +103,15 @@ func TestCache_KeyStability(t *testing.T) {
 	}
 }
 
-func TestNewCache_DefaultDir(t *testing.T) {
+func TestNewFileCache_DefaultDir(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		t.Skip("cannot determine home directory")
 	}
 
-	c, err := NewCache("", time.Hour)
+	c, err := NewFileCache("", time.Hour)
 	if err != nil {
-		t.Fatalf("NewCache() failed: %v", err)
+		t.Fatalf("NewFileCache() failed: %v", err)
 	}
 
 	want := filepath.Join(home, ".cache", "stacktower")
@@ -119,3 +125,86 @@ func TestNewCache_DefaultDir(t *testing.T) {
 		t.Errorf("directory not created: %v", err)
 	}
 }
+
+func TestCache_StaleShape(t *testing.T) {
+	c := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
+	if err := c.Set("key", []string{"a", "b"}); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	var res []struct{ Name string }
+	ok, err := c.Get("key", &res)
+	if err == nil {
+		t.Error("expected decode error")
+	}
+	if ok {
+		t.Error("Get() returned true for undecodable entry")
+	}
+}
+
+func TestCache_Entries(t *testing.T) {
+	caches := map[string]Cache{
+		"file":   &FileCache{Dir: t.TempDir(), TTL: time.Hour},
+		"memory": NewMemoryCache(time.Hour),
+	}
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"pypi:package:b", "npm:manifest:a", "pypi:package:a"} {
+				if err := c.Set(key, "v"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := c.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, e := range entries {
+				keys = append(keys, e.Key)
+				if e.Size != len(`"v"`) || e.Value != nil || e.Stored.IsZero() {
+					t.Errorf("entry %+v", e)
+				}
+			}
+			if want := []string{"npm:manifest:a", "pypi:package:a", "pypi:package:b"}; !slices.Equal(keys, want) {
+				t.Errorf("keys = %v, want %v", keys, want)
+			}
+
+			if err := c.Delete("pypi:package:a"); err != nil {
+				t.Fatal(err)
+			}
+			var v string
+			if ok, _ := c.Get("pypi:package:a", &v); ok {
+				t.Error("deleted entry still cached")
+			}
+
+			if err := c.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := c.Entries(); len(entries) != 0 {
+				t.Errorf("%d entries after Clear", len(entries))
+			}
+		})
+	}
+}
+
+func TestFileCache_LegacyFiles(t *testing.T) {
+	c := &FileCache{Dir: t.TempDir(), TTL: time.Hour}
+	if err := os.WriteFile(c.path("old"), []byte(`{"name":"requests"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]string
+	if ok, err := c.Get("old", &v); ok || err != nil {
+		t.Errorf("Get() = %v, %v; want a miss", ok, err)
+	}
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("Entries() = %v, want none", entries)
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("old")); !os.IsNotExist(err) {
+		t.Error("Clear() kept a legacy file")
+	}
+}
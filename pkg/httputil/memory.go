@@ -0,0 +1,94 @@
+package httputil
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryCache is a Cache that lives as long as the process, for tests and
+// for runs that should leave nothing on disk.
+type MemoryCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{TTL: ttl, entries: make(map[string]Entry)}
+}
+
+func (c *MemoryCache) Get(key string, v any) (bool, error) {
+	return c.get(key, v, c.TTL)
+}
+
+func (c *MemoryCache) GetStale(key string, v any) (bool, error) {
+	return c.get(key, v, 0)
+}
+
+func (c *MemoryCache) get(key string, v any, ttl time.Duration) (bool, error) {
+	e, ok, _ := c.Load(key)
+	if !ok {
+		return false, nil
+	}
+	if ttl > 0 && time.Since(e.Stored) > ttl {
+		return false, ErrExpired
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *MemoryCache) Set(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Store(Entry{Key: key, Stored: time.Now(), Value: data})
+}
+
+func (c *MemoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *MemoryCache) Entries() ([]Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, key := range slices.Sorted(maps.Keys(c.entries)) {
+		e := c.entries[key]
+		e.Value = nil
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (c *MemoryCache) Load(key string) (Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	return e, ok, nil
+}
+
+func (c *MemoryCache) Store(e Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.Value = slices.Clone(e.Value)
+	e.Size = len(e.Value)
+	c.entries[e.Key] = e
+	return nil
+}
+
+func (c *MemoryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	return nil
+}
@@ -0,0 +1,183 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket: it lets Rate requests per second through on
+// average, and up to Burst at once. A Rate of zero or less lets every
+// request through. PauseUntil holds all requests back until a given time,
+// for servers that say when an exhausted quota resets.
+type Limiter struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	paused time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	burst = max(burst, 1)
+	return &Limiter{Rate: rate, Burst: burst, tokens: float64(burst)}
+}
+
+// Wait blocks until a request may be made or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		d := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
+
+// reserve takes a token and returns 0, or returns how long to wait before
+// trying again.
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.paused) {
+		return l.paused.Sub(now)
+	}
+	if l.Rate <= 0 {
+		return 0
+	}
+	if !l.last.IsZero() {
+		l.tokens = math.Min(float64(l.Burst), l.tokens+now.Sub(l.last).Seconds()*l.Rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.Rate * float64(time.Second))
+}
+
+// PauseUntil holds requests back until t.
+func (l *Limiter) PauseUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.paused) {
+		l.paused = t
+	}
+}
+
+// PausedUntil returns the time PauseUntil last held requests back to.
+func (l *Limiter) PausedUntil() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+// RateLimit is the rate, in requests per second, and burst of a host.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultRateLimits apply to hosts no SetRateLimit call configured. Other
+// hosts are not limited, but are still paused when they report an
+// exhausted quota.
+var DefaultRateLimits = map[string]RateLimit{
+	"api.github.com": {Rate: 10, Burst: 10},
+	"gitlab.com":     {Rate: 5, Burst: 10},
+}
+
+var limiters = struct {
+	sync.Mutex
+	m map[string]*Limiter
+}{m: make(map[string]*Limiter)}
+
+// SetRateLimit replaces the limiter of host. Later requests to the host
+// from every client in the process share it.
+func SetRateLimit(host string, rate float64, burst int) {
+	limiters.Lock()
+	defer limiters.Unlock()
+	limiters.m[host] = NewLimiter(rate, burst)
+}
+
+// LimiterFor returns the limiter shared by every request to host.
+func LimiterFor(host string) *Limiter {
+	limiters.Lock()
+	defer limiters.Unlock()
+	if l, ok := limiters.m[host]; ok {
+		return l
+	}
+	d := DefaultRateLimits[host]
+	l := NewLimiter(d.Rate, d.Burst)
+	limiters.m[host] = l
+	return l
+}
+
+// ParseRateLimit parses a rate limit given as host=rate, optionally
+// followed by /burst, such as crates.io=1 or api.github.com=10/20. The
+// burst defaults to the rate rounded up.
+func ParseRateLimit(s string) (string, RateLimit, error) {
+	invalid := fmt.Errorf("invalid rate limit %q, want host=rate or host=rate/burst", s)
+	host, spec, ok := strings.Cut(s, "=")
+	if !ok || host == "" {
+		return "", RateLimit{}, invalid
+	}
+	rateStr, burstStr, hasBurst := strings.Cut(spec, "/")
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate < 0 {
+		return "", RateLimit{}, invalid
+	}
+	burst := int(math.Ceil(rate))
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+			return "", RateLimit{}, invalid
+		}
+	}
+	return host, RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// RetryAfter returns how long a response asks to wait before the next
+// request: its Retry-After header, in seconds or as a date, or else the
+// time until X-RateLimit-Reset (or RateLimit-Reset) once
+// X-RateLimit-Remaining has reached zero. It returns 0 if the response
+// says nothing.
+func RetryAfter(h http.Header, now time.Time) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(max(secs, 0)) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+	if reset, ok := RateLimitReset(h); ok {
+		return max(reset.Sub(now), 0)
+	}
+	return 0
+}
+
+// RateLimitReset returns when an exhausted quota resets, if the response
+// reports that none is left. GitHub and GitLab send the reset as a Unix
+// time.
+func RateLimitReset(h http.Header) (time.Time, bool) {
+	for _, prefix := range []string{"X-", ""} {
+		if h.Get(prefix+"RateLimit-Remaining") != "0" {
+			continue
+		}
+		if secs, err := strconv.ParseInt(h.Get(prefix+"RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(secs, 0), true
+		}
+	}
+	return time.Time{}, false
+}
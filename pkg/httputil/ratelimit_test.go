@@ -0,0 +1,130 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 4 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two requests pass as a burst, the other two wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 requests took %v, want >= 100ms at 20/s with a burst of 2", elapsed)
+	}
+
+	l.PauseUntil(time.Now().Add(50 * time.Millisecond))
+	start = time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("paused limiter let a request through after %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	l.PauseUntil(time.Now().Add(time.Hour))
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := NewLimiter(0, 0)
+	start := time.Now()
+	for range 100 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unlimited limiter took %v", elapsed)
+	}
+}
+
+func TestLimiterFor(t *testing.T) {
+	if LimiterFor("example.test") != LimiterFor("example.test") {
+		t.Error("requests to one host got different limiters")
+	}
+	SetRateLimit("example.test", 3, 5)
+	if l := LimiterFor("example.test"); l.Rate != 3 || l.Burst != 5 {
+		t.Errorf("got rate %v burst %d, want 3 and 5", l.Rate, l.Burst)
+	}
+	if l := LimiterFor("api.github.com"); l.Rate != DefaultRateLimits["api.github.com"].Rate {
+		t.Errorf("got rate %v for api.github.com, want the default", l.Rate)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		host    string
+		want    RateLimit
+		wantErr bool
+	}{
+		{in: "crates.io=1", host: "crates.io", want: RateLimit{Rate: 1, Burst: 1}},
+		{in: "pypi.org=2.5", host: "pypi.org", want: RateLimit{Rate: 2.5, Burst: 3}},
+		{in: "api.github.com=10/20", host: "api.github.com", want: RateLimit{Rate: 10, Burst: 20}},
+		{in: "crates.io", wantErr: true},
+		{in: "=1", wantErr: true},
+		{in: "crates.io=fast", wantErr: true},
+		{in: "crates.io=1/0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			host, got, err := ParseRateLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if host != tt.host || got != tt.want {
+				t.Errorf("got %q %+v, want %q %+v", host, got, tt.host, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "none", header: http.Header{}, want: 0},
+		{name: "seconds", header: http.Header{"Retry-After": {"30"}}, want: 30 * time.Second},
+		{name: "date", header: http.Header{"Retry-After": {"Mon, 01 Jan 2024 12:01:00 GMT"}}, want: time.Minute},
+		{name: "pastDate", header: http.Header{"Retry-After": {"Mon, 01 Jan 2024 11:00:00 GMT"}}, want: 0},
+		{
+			name:   "githubReset",
+			header: http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1704110520"}},
+			want:   2 * time.Minute,
+		},
+		{
+			name:   "gitlabReset",
+			header: http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"1704110460"}},
+			want:   time.Minute,
+		},
+		{
+			name:   "quotaLeft",
+			header: http.Header{"X-Ratelimit-Remaining": {"12"}, "X-Ratelimit-Reset": {"1704110520"}},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,16 +3,27 @@ package httputil
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
 	"time"
 )
 
+// MaxRetryAfter is the longest server-provided delay Retry waits out. A
+// request asked to wait longer fails instead.
+const MaxRetryAfter = 5 * time.Minute
+
+// RetryableError marks an error worth another attempt. After is the delay
+// the server asked for, if any; Retry uses it instead of its backoff.
 type RetryableError struct {
-	Err error
+	Err   error
+	After time.Duration
 }
 
 func (e *RetryableError) Error() string { return e.Err.Error() }
 func (e *RetryableError) Unwrap() error { return e.Err }
 
+// Retry calls fn up to max times while it returns a *RetryableError. The
+// delay between attempts starts at delay and doubles each time, plus up to
+// half of it again at random so that concurrent callers spread out.
 func Retry(ctx context.Context, max int, delay time.Duration, fn func() error) error {
 	if max < 1 {
 		max = 1
@@ -20,16 +31,27 @@ func Retry(ctx context.Context, max int, delay time.Duration, fn func() error) e
 
 	var lastErr error
 	for attempt := 0; attempt < max; attempt++ {
-		if err := fn(); err == nil {
+		err := fn()
+		if err == nil {
 			return nil
-		} else if lastErr = err; !errors.As(err, new(*RetryableError)) {
+		}
+		lastErr = err
+		var retryable *RetryableError
+		if !errors.As(err, &retryable) || retryable.After > MaxRetryAfter {
 			return err
 		}
+		if attempt == max-1 {
+			break
+		}
 
+		wait := retryable.After
+		if wait == 0 {
+			wait = delay + rand.N(delay/2+1)
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(delay):
+		case <-time.After(wait):
 			delay *= 2
 		}
 	}
@@ -135,6 +135,34 @@ func TestRetry_ExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestRetry_After(t *testing.T) {
+	attempts := 0
+	start := time.Now()
+
+	err := Retry(context.Background(), 2, time.Hour, func() error {
+		attempts++
+		if attempts < 2 {
+			return &RetryableError{Err: errors.New("retry"), After: 20 * time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("elapsed %v, want the server's 20ms instead of the backoff", elapsed)
+	}
+
+	attempts = 0
+	err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		attempts++
+		return &RetryableError{Err: errors.New("retry"), After: MaxRetryAfter + time.Second}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("got %v after %d attempts, want to give up on a delay over MaxRetryAfter", err, attempts)
+	}
+}
+
 func TestRetryWithBackoff(t *testing.T) {
 	attempts := 0
 	err := RetryWithBackoff(context.Background(), func() error {
@@ -0,0 +1,152 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+var repoURLPattern = regexp.MustCompile(`https?://bitbucket\.org/([^/]+)/([^/#?]+?)(?:\.git)?(?:[/#?]|$)`)
+
+// Client reads repositories from the Bitbucket Cloud API. Bitbucket has no
+// stars, so watchers are counted instead.
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+	headers map[string]string
+}
+
+func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	ep := integrations.EndpointFor("bitbucket", "https://api.bitbucket.org/2.0")
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: ep.URL,
+		headers: headers,
+	}, nil
+}
+
+func ExtractURL(projectURLs map[string]string, homepage string) (workspace, repo string, ok bool) {
+	return integrations.ExtractRepoURL(repoURLPattern, projectURLs, homepage)
+}
+
+func (c *Client) Fetch(ctx context.Context, workspace, repo string, refresh bool) (*integrations.RepoMetrics, error) {
+	cacheKey := "bitbucket:" + workspace + "/" + repo
+
+	var m integrations.RepoMetrics
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchMetrics(ctx, workspace, repo, &m)
+	}, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *Client) fetchMetrics(ctx context.Context, workspace, repo string, m *integrations.RepoMetrics) error {
+	base := fmt.Sprintf("%s/repositories/%s/%s", c.baseURL, workspace, repo)
+
+	var data repoResponse
+	if err := c.DoRequest(ctx, base, c.headers, &data); err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return fmt.Errorf("%w: bitbucket repo %s/%s", err, workspace, repo)
+		}
+		return err
+	}
+
+	*m = integrations.RepoMetrics{
+		RepoURL:      data.Links.HTML.Href,
+		Owner:        data.Workspace.Slug,
+		SizeKB:       data.Size / 1024,
+		LastCommitAt: data.UpdatedOn,
+		Language:     data.Language,
+	}
+	if m.RepoURL == "" {
+		m.RepoURL = fmt.Sprintf("https://bitbucket.org/%s/%s", workspace, repo)
+	}
+
+	var watchers pageResponse[struct{}]
+	if err := c.DoRequest(ctx, base+"/watchers?pagelen=1", c.headers, &watchers); err == nil {
+		m.Stars = watchers.Size
+	}
+	// Bitbucket has no releases; the newest tag stands in for the latest
+	// one.
+	var tags pageResponse[tagResponse]
+	if err := c.DoRequest(ctx, base+"/refs/tags?sort=-target.date&pagelen=1", c.headers, &tags); err == nil && len(tags.Values) > 0 {
+		m.LastReleaseAt = tags.Values[0].Target.Date
+	}
+	var commits pageResponse[commitResponse]
+	if err := c.DoRequest(ctx, base+"/commits?pagelen=50", c.headers, &commits); err == nil && len(commits.Values) > 0 {
+		m.LastCommitAt = &commits.Values[0].Date
+		m.Contributors = topAuthors(commits.Values)
+	}
+	return nil
+}
+
+// topAuthors returns the five authors with the most of the given commits,
+// identified by their account if Bitbucket knows it and by the raw author
+// line otherwise.
+func topAuthors(commits []commitResponse) []integrations.Contributor {
+	logins := make([]string, len(commits))
+	for i, c := range commits {
+		logins[i] = c.Author.Raw
+		if c.Author.User != nil && c.Author.User.Nickname != "" {
+			logins[i] = c.Author.User.Nickname
+		}
+	}
+	return integrations.TopContributors(logins, 5)
+}
+
+type repoResponse struct {
+	Size      int        `json:"size"`
+	UpdatedOn *time.Time `json:"updated_on"`
+	Language  string     `json:"language"`
+	Links     struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+}
+
+// pageResponse is a page of a Bitbucket collection; Size is the number of
+// items in the whole collection.
+type pageResponse[T any] struct {
+	Size   int `json:"size"`
+	Values []T `json:"values"`
+}
+
+type tagResponse struct {
+	Target struct {
+		Date *time.Time `json:"date"`
+	} `json:"target"`
+}
+
+type commitResponse struct {
+	Date   time.Time `json:"date"`
+	Author struct {
+		Raw  string `json:"raw"`
+		User *struct {
+			Nickname string `json:"nickname"`
+		} `json:"user"`
+	} `json:"author"`
+}
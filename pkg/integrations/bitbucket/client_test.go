@@ -0,0 +1,84 @@
+package bitbucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repositories/team/repo":
+			w.Write([]byte(`{
+				"size": 2048000,
+				"updated_on": "2024-01-01T00:00:00Z",
+				"language": "python",
+				"links": {"html": {"href": "https://bitbucket.org/team/repo"}},
+				"workspace": {"slug": "team"}
+			}`))
+		case "/repositories/team/repo/watchers":
+			w.Write([]byte(`{"size": 7, "values": [{}]}`))
+		case "/repositories/team/repo/refs/tags":
+			w.Write([]byte(`{"values": [{"target": {"date": "2024-02-01T00:00:00Z"}}]}`))
+		case "/repositories/team/repo/commits":
+			w.Write([]byte(`{"values": [
+				{"date": "2024-03-01T00:00:00Z", "author": {"raw": "Ada <ada@example.com>", "user": {"nickname": "ada"}}},
+				{"date": "2024-02-01T00:00:00Z", "author": {"raw": "Bob <bob@example.com>"}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTP = server.Client()
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+	c.baseURL = server.URL
+
+	m, err := c.Fetch(context.Background(), "team", "repo", false)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if m.Stars != 7 || m.Owner != "team" || m.SizeKB != 2000 || m.RepoURL != "https://bitbucket.org/team/repo" {
+		t.Errorf("got %+v", m)
+	}
+	if m.LastCommitAt == nil || m.LastCommitAt.Month() != time.March {
+		t.Errorf("got last commit %v", m.LastCommitAt)
+	}
+	if m.LastReleaseAt == nil || m.LastReleaseAt.Month() != time.February {
+		t.Errorf("got last release %v", m.LastReleaseAt)
+	}
+	if len(m.Contributors) != 2 || m.Contributors[0].Login != "Bob <bob@example.com>" || m.Contributors[1].Login != "ada" {
+		t.Errorf("got contributors %+v", m.Contributors)
+	}
+}
+
+func TestExtractURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantOK    bool
+	}{
+		{url: "https://bitbucket.org/team/repo", wantOwner: "team", wantRepo: "repo", wantOK: true},
+		{url: "https://bitbucket.org/team/repo.git", wantOwner: "team", wantRepo: "repo", wantOK: true},
+		{url: "https://bitbucket.org/team/repo/src/master/", wantOwner: "team", wantRepo: "repo", wantOK: true},
+		{url: "https://gitlab.com/team/repo", wantOK: false},
+	}
+	for _, tt := range tests {
+		owner, repo, ok := ExtractURL(map[string]string{"Source": tt.url}, "")
+		if ok != tt.wantOK || owner != tt.wantOwner || repo != tt.wantRepo {
+			t.Errorf("%s: got %q %q %v, want %q %q %v", tt.url, owner, repo, ok, tt.wantOwner, tt.wantRepo, tt.wantOK)
+		}
+	}
+}
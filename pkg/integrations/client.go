@@ -1,61 +1,236 @@
 package integrations
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"time"
 
 	"github.com/matzehuels/stacktower/pkg/httputil"
 )
 
+// BaseClient makes the requests of a registry client. Headers are added
+// to every request, before the headers of the request itself.
 type BaseClient struct {
-	HTTP  *http.Client
-	Cache *httputil.Cache
+	HTTP    *http.Client
+	Cache   httputil.Cache
+	Headers map[string]string
 }
 
-func (c *BaseClient) FetchWithCache(ctx context.Context, key string, refresh bool, fetch func() error, v any) error {
+// FetchWithCache serves v from the cache, or calls fetch and caches the
+// result. Offline, expired entries are served too and a miss is a
+// *NotCachedError.
+//
+// fetch must make its requests with the context it is given. When it
+// makes a single one, the response's ETag and Last-Modified are kept with
+// the entry; once the entry has expired, or on refresh, they are sent
+// back, and a 304 Not Modified renews the entry without a download. An
+// entry that no longer decodes into v is downloaded again instead.
+func (c *BaseClient) FetchWithCache(ctx context.Context, key string, refresh bool, fetch func(ctx context.Context) error, v any) error {
+	if IsOffline(ctx) {
+		if ok, _ := c.Cache.GetStale(key, v); ok {
+			return nil
+		}
+		return &NotCachedError{Key: key}
+	}
 	if !refresh {
 		if ok, _ := c.Cache.Get(key, v); ok {
 			return nil
 		}
 	}
 
-	if err := httputil.RetryWithBackoff(ctx, fetch); err != nil {
+	stale, hasStale, _ := c.Cache.Load(key)
+	rv := &revalidation{}
+	if hasStale {
+		rv.cached = validators{url: stale.URL, etag: stale.ETag, lastModified: stale.LastModified}
+	}
+	run := func(rv *revalidation) error {
+		ctx := context.WithValue(ctx, revalidationKey{}, rv)
+		return httputil.RetryWithBackoff(ctx, func() error {
+			rv.requests = 0
+			return fetch(ctx)
+		})
+	}
+	err := run(rv)
+	if errors.Is(err, errNotModified) {
+		if json.Unmarshal(stale.Value, v) == nil {
+			stale.Stored = time.Now()
+			_ = c.Cache.Store(stale)
+			return nil
+		}
+		// The entry was stored in a shape v no longer has: download the
+		// response again rather than renew it.
+		reflect.ValueOf(v).Elem().SetZero()
+		rv = &revalidation{}
+		err = run(rv)
+	}
+	if err != nil {
 		return err
 	}
 
-	_ = c.Cache.Set(key, v)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e := httputil.Entry{Key: key, Stored: time.Now(), Value: data}
+	if rv.requests == 1 {
+		e.URL, e.ETag, e.LastModified = rv.received.url, rv.received.etag, rv.received.lastModified
+	}
+	_ = c.Cache.Store(e)
 	return nil
 }
 
+// errNotModified ends a fetch whose only request was answered with 304.
+var errNotModified = errors.New("not modified")
+
+type revalidationKey struct{}
+
+// revalidation tracks the requests of one FetchWithCache call: the
+// validators of the cached response going in, those of the last response
+// coming out.
+type revalidation struct {
+	cached   validators
+	received validators
+	requests int
+}
+
+type validators struct {
+	url, etag, lastModified string
+}
+
+// conditional reports whether a request for url may be answered with 304.
+func (rv *revalidation) conditional(url string) bool {
+	return rv != nil && rv.requests == 0 && rv.cached.url == url && (rv.cached.etag != "" || rv.cached.lastModified != "")
+}
+
 func (c *BaseClient) DoRequest(ctx context.Context, url string, headers map[string]string, v any) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := c.get(ctx, url, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+func (c *BaseClient) DoRequestRaw(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	resp, err := c.get(ctx, url, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &httputil.RetryableError{Err: fmt.Errorf("%w: %w", ErrNetwork, err)}
+	}
+	return body, nil
+}
+
+// DoPost sends body as JSON to url and decodes the JSON response into v,
+// for APIs queried with POST. Responses to it are never revalidated.
+func (c *BaseClient) DoPost(ctx context.Context, url string, headers map[string]string, body, v any) error {
+	data, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
+	resp, err := c.send(ctx, http.MethodPost, url, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+func (c *BaseClient) get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	return c.send(ctx, http.MethodGet, url, nil, headers)
+}
+
+func (c *BaseClient) send(ctx context.Context, method, url string, body []byte, headers map[string]string) (*http.Response, error) {
+	if IsOffline(ctx) {
+		return nil, &NotCachedError{Key: url}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	rv, _ := ctx.Value(revalidationKey{}).(*revalidation)
+	conditional := method == http.MethodGet && rv.conditional(url)
+	if conditional {
+		if rv.cached.etag != "" {
+			req.Header.Set("If-None-Match", rv.cached.etag)
+		}
+		if rv.cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", rv.cached.lastModified)
+		}
+	}
+	if rv != nil {
+		rv.requests++
+	}
 
+	limiter := httputil.LimiterFor(req.URL.Host)
+	if until := limiter.PausedUntil(); time.Until(until) > httputil.MaxRetryAfter {
+		return nil, fmt.Errorf("%w: %s until %s", ErrRateLimited, req.URL.Host, until.Format(time.TimeOnly))
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return &httputil.RetryableError{Err: fmt.Errorf("%w: %v", ErrNetwork, err)}
+		return nil, &httputil.RetryableError{Err: fmt.Errorf("%w: %w", ErrNetwork, err)}
+	}
+	// Hold back every worker's requests to the host once its quota is
+	// spent, not just the retries of this one.
+	reset, exhausted := httputil.RateLimitReset(resp.Header)
+	if exhausted {
+		limiter.PauseUntil(reset)
 	}
-	defer resp.Body.Close()
 
 	switch {
-	case resp.StatusCode == http.StatusNotFound:
-		return ErrNotFound
+	case resp.StatusCode == http.StatusNotModified && conditional:
+		resp.Body.Close()
+		return nil, errNotModified
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusForbidden && exhausted:
+		resp.Body.Close()
+		return nil, &httputil.RetryableError{
+			Err:   fmt.Errorf("%w: %d", ErrRateLimited, resp.StatusCode),
+			After: httputil.RetryAfter(resp.Header, time.Now()),
+		}
 	case resp.StatusCode >= 500:
-		return &httputil.RetryableError{Err: fmt.Errorf("%w: %d", ErrNetwork, resp.StatusCode)}
+		resp.Body.Close()
+		return nil, &httputil.RetryableError{Err: fmt.Errorf("%w: %d", ErrNetwork, resp.StatusCode)}
 	case resp.StatusCode != http.StatusOK:
-		return fmt.Errorf("%w: %d", ErrNetwork, resp.StatusCode)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d", ErrNetwork, resp.StatusCode)
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-		return fmt.Errorf("decode: %w", err)
+	if rv != nil && method == http.MethodGet {
+		rv.received = validators{url: url, etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
 	}
-	return nil
+	return resp, nil
 }
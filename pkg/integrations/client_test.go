@@ -0,0 +1,187 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+)
+
+func TestFetchWithCache_Offline(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`"fresh"`))
+	}))
+	defer srv.Close()
+
+	c := &BaseClient{HTTP: srv.Client(), Cache: &httputil.FileCache{Dir: t.TempDir(), TTL: time.Nanosecond}}
+	if err := c.Cache.Set("cached", "stale"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	ctx := WithOffline(context.Background())
+	fetch := func(key string, v *string) error {
+		return c.FetchWithCache(ctx, key, false, func(ctx context.Context) error { return c.DoRequest(ctx, srv.URL, nil, v) }, v)
+	}
+
+	var got string
+	if err := fetch("cached", &got); err != nil || got != "stale" {
+		t.Errorf("cached: got %q, %v; want the expired entry", got, err)
+	}
+
+	err := fetch("missing", &got)
+	var nc *NotCachedError
+	if !errors.As(err, &nc) || nc.Key != "missing" || !errors.Is(err, ErrNotCached) {
+		t.Errorf("missing: got %v, want NotCachedError for the key", err)
+	}
+
+	if err := c.DoRequest(ctx, srv.URL, nil, &got); !errors.Is(err, ErrNotCached) {
+		t.Errorf("DoRequest: got %v, want ErrNotCached", err)
+	}
+	if requests != 0 {
+		t.Errorf("made %d requests offline", requests)
+	}
+}
+
+func TestFetchWithCache_Revalidate(t *testing.T) {
+	var conditional, full int
+	body := `{"version":"1.0"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") != "" {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cache := httputil.NewMemoryCache(time.Hour)
+	c := &BaseClient{HTTP: srv.Client(), Cache: cache}
+	fetch := func(refresh bool) string {
+		var v struct{ Version string }
+		err := c.FetchWithCache(context.Background(), "pkg", refresh, func(ctx context.Context) error {
+			return c.DoRequest(ctx, srv.URL, nil, &v)
+		}, &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.Version
+	}
+
+	if got := fetch(false); got != "1.0" {
+		t.Fatalf("first fetch = %q", got)
+	}
+	e, _, _ := cache.Load("pkg")
+	if e.ETag != `"v1"` || e.URL != srv.URL {
+		t.Errorf("validators not stored: %+v", e)
+	}
+
+	before := e.Stored
+	time.Sleep(time.Millisecond)
+	if got := fetch(true); got != "1.0" {
+		t.Errorf("revalidated fetch = %q, want the cached value", got)
+	}
+	if conditional != 1 || full != 1 {
+		t.Errorf("got %d conditional and %d full requests, want 1 and 1", conditional, full)
+	}
+	if e, _, _ := cache.Load("pkg"); !e.Stored.After(before) || e.ETag != `"v1"` {
+		t.Errorf("304 did not renew the entry: %+v", e)
+	}
+
+	cache.TTL = time.Nanosecond
+	body = `{"version":"2.0"}`
+	_ = cache.Store(httputil.Entry{Key: "pkg", Stored: before, Value: []byte(`{"version":"1.0"}`), URL: srv.URL, ETag: `"v0"`})
+	if got := fetch(false); got != "2.0" {
+		t.Errorf("fetch with a stale ETag = %q, want the new body", got)
+	}
+}
+
+func TestFetchWithCache_RevalidateOldShape(t *testing.T) {
+	var conditional, full int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"versions":["1.0","2.0"]}`))
+	}))
+	defer srv.Close()
+
+	// An entry written when Versions was a single string.
+	cache := httputil.NewMemoryCache(time.Hour)
+	_ = cache.Store(httputil.Entry{Key: "pkg", Stored: time.Now(), Value: []byte(`{"versions":"1.0"}`), URL: srv.URL, ETag: `"v1"`})
+
+	c := &BaseClient{HTTP: srv.Client(), Cache: cache}
+	var v struct{ Versions []string }
+	err := c.FetchWithCache(context.Background(), "pkg", true, func(ctx context.Context) error {
+		return c.DoRequest(ctx, srv.URL, nil, &v)
+	}, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Versions) != 2 {
+		t.Errorf("versions = %v, want the downloaded ones", v.Versions)
+	}
+	if conditional != 1 || full != 1 {
+		t.Errorf("got %d conditional and %d full requests, want 1 and 1", conditional, full)
+	}
+	if e, _, _ := cache.Load("pkg"); string(e.Value) != `{"Versions":["1.0","2.0"]}` {
+		t.Errorf("entry = %s, want the new shape", e.Value)
+	}
+}
+
+func TestDoRequest_RateLimited(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			// GitHub's answer once the quota is spent.
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Write([]byte(`"ok"`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &BaseClient{HTTP: srv.Client(), Cache: httputil.NewMemoryCache(time.Hour)}
+	var got string
+	err := c.FetchWithCache(context.Background(), "key", false, func(ctx context.Context) error {
+		return c.DoRequest(ctx, srv.URL, nil, &got)
+	}, &got)
+	if err != nil || got != "ok" {
+		t.Fatalf("got %q, %v; want the third response", got, err)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	httputil.LimiterFor(host).PauseUntil(time.Now().Add(time.Hour))
+	if err := c.DoRequest(context.Background(), srv.URL, nil, &got); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("paused host: got %v, want ErrRateLimited without waiting", err)
+	}
+	if requests != 3 {
+		t.Errorf("paused host: made %d requests, want none", requests-3)
+	}
+}
@@ -1,11 +1,16 @@
 package integrations
 
 import (
+	"cmp"
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/matzehuels/stacktower/pkg/httputil"
@@ -14,10 +19,65 @@ import (
 const httpTimeout = 10 * time.Second
 
 var (
-	ErrNotFound = errors.New("resource not found")
-	ErrNetwork  = errors.New("network error")
+	ErrNotFound  = errors.New("resource not found")
+	ErrNetwork   = errors.New("network error")
+	ErrNotCached = errors.New("not cached")
+	// ErrRateLimited wraps ErrNetwork: the server refused a request for
+	// coming too soon or too often.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrNetwork)
 )
 
+// NotCachedError is returned in offline mode for a response that is not in
+// the cache. Key is the cache key, or the URL of a request made outside
+// the cache.
+type NotCachedError struct {
+	Key string
+}
+
+func (e *NotCachedError) Error() string        { return "not cached: " + e.Key }
+func (e *NotCachedError) Is(target error) bool { return target == ErrNotCached }
+
+type offlineKey struct{}
+
+// WithOffline returns a context in which clients serve cached responses
+// only, expired ones included, and never touch the network.
+func WithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, offlineKey{}, true)
+}
+
+// IsOffline reports whether ctx was made by WithOffline.
+func IsOffline(ctx context.Context) bool {
+	offline, _ := ctx.Value(offlineKey{}).(bool)
+	return offline
+}
+
+// Dependency kinds. A dependency without a kind is a runtime dependency.
+const (
+	KindRuntime  = "runtime"
+	KindDev      = "dev"
+	KindPeer     = "peer"
+	KindOptional = "optional"
+	KindBuild    = "build"
+)
+
+// Dependency is a requirement declared by a package: the dependency's
+// name and the version constraint in the registry's own syntax. An empty
+// constraint allows any version.
+//
+// Extras are the optional features requested on the dependency (Python
+// extras, Cargo features). Feature names the declaring package's own extra
+// or feature that pulled the dependency in; Optional marks dependencies
+// that are only installed when such a feature is enabled. Kind says what
+// the dependency is needed for.
+type Dependency struct {
+	Name       string   `json:"name"`
+	Constraint string   `json:"constraint,omitempty"`
+	Extras     []string `json:"extras,omitempty"`
+	Feature    string   `json:"feature,omitempty"`
+	Optional   bool     `json:"optional,omitempty"`
+	Kind       string   `json:"kind,omitempty"`
+}
+
 type RepoMetrics struct {
 	RepoURL       string        `json:"repo_url"`
 	Owner         string        `json:"owner"`
@@ -37,6 +97,25 @@ type Contributor struct {
 	Contributions int    `json:"contributions"`
 }
 
+// TopContributors counts the occurrences of each login, such as the
+// authors of a list of commits, and returns the n most frequent.
+func TopContributors(logins []string, n int) []Contributor {
+	counts := make(map[string]int)
+	for _, login := range logins {
+		if login != "" {
+			counts[login]++
+		}
+	}
+	contributors := make([]Contributor, 0, len(counts))
+	for login, count := range counts {
+		contributors = append(contributors, Contributor{Login: login, Contributions: count})
+	}
+	slices.SortFunc(contributors, func(a, b Contributor) int {
+		return cmp.Or(cmp.Compare(b.Contributions, a.Contributions), strings.Compare(a.Login, b.Login))
+	})
+	return contributors[:min(n, len(contributors))]
+}
+
 var repoURLKeys = []string{"Source", "Repository", "Code", "Homepage"}
 
 func ExtractRepoURL(re *regexp.Regexp, projectURLs map[string]string, homepage string) (owner, repo string, ok bool) {
@@ -74,8 +153,40 @@ func NewHTTPClient() *http.Client {
 	return &http.Client{Timeout: httpTimeout}
 }
 
-func NewCache(ttl time.Duration) (*httputil.Cache, error) {
-	return httputil.NewCache("", ttl)
+// Cache backends, where clients keep the responses they fetch.
+const (
+	CacheFile   = "file"   // ~/.cache/stacktower, shared by runs
+	CacheMemory = "memory" // the process only, leaving nothing on disk
+)
+
+var CacheBackends = []string{CacheFile, CacheMemory}
+
+var cacheBackend = struct {
+	sync.RWMutex
+	name string
+}{name: CacheFile}
+
+// SetCacheBackend sets the backend of the caches NewCache creates from
+// then on, such as those of clients created afterwards.
+func SetCacheBackend(name string) error {
+	if !slices.Contains(CacheBackends, name) {
+		return fmt.Errorf("unknown cache backend %q (want %s)", name, strings.Join(CacheBackends, ", "))
+	}
+	cacheBackend.Lock()
+	defer cacheBackend.Unlock()
+	cacheBackend.name = name
+	return nil
+}
+
+// NewCache returns a cache of the backend set by SetCacheBackend,
+// CacheFile by default.
+func NewCache(ttl time.Duration) (httputil.Cache, error) {
+	cacheBackend.RLock()
+	defer cacheBackend.RUnlock()
+	if cacheBackend.name == CacheMemory {
+		return httputil.NewMemoryCache(ttl), nil
+	}
+	return httputil.NewFileCache("", ttl)
 }
 
 func URLEncode(s string) string {
@@ -1,8 +1,10 @@
 package integrations
 
 import (
+	"fmt"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestExtractRepoURL(t *testing.T) {
@@ -67,3 +69,35 @@ func TestExtractRepoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestTopContributors(t *testing.T) {
+	got := TopContributors([]string{"ada", "bob", "ada", "", "cy", "bob", "ada"}, 2)
+	want := []Contributor{{Login: "ada", Contributions: 3}, {Login: "bob", Contributions: 2}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := TopContributors(nil, 5); len(got) != 0 {
+		t.Errorf("got %+v for no logins", got)
+	}
+}
+
+func TestSetCacheBackend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { _ = SetCacheBackend(CacheFile) })
+
+	if err := SetCacheBackend("redis"); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+	for backend, want := range map[string]string{CacheMemory: "*httputil.MemoryCache", CacheFile: "*httputil.FileCache"} {
+		if err := SetCacheBackend(backend); err != nil {
+			t.Fatal(err)
+		}
+		c, err := NewCache(time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprintf("%T", c); got != want {
+			t.Errorf("%s: NewCache returned %s, want %s", backend, got, want)
+		}
+	}
+}
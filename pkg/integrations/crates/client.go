@@ -12,7 +12,8 @@ import (
 type CrateInfo struct {
 	Name         string
 	Version      string
-	Dependencies []string
+	Dependencies []integrations.Dependency
+	Features     map[string][]string
 	Repository   string
 	HomePage     string
 	Description  string
@@ -31,22 +32,36 @@ func NewClient(cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("crates", "https://crates.io/api/v1")
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		baseURL: "https://crates.io/api/v1",
+		baseURL: ep.URL,
 		headers: map[string]string{"User-Agent": "github.com/matzehuels/stacktower/1.0 (https://github.com/stacktower)"},
 	}, nil
 }
 
 func (c *Client) FetchCrate(ctx context.Context, crate string, refresh bool) (*CrateInfo, error) {
-	cacheKey := "crates:" + crate
+	return c.FetchCrateVersion(ctx, crate, "", refresh)
+}
+
+// FetchCrateVersion fetches a specific release; an empty version means the
+// crate's max_version. Optional dependencies are included and marked so;
+// which of them a build uses depends on the enabled Features. A dependency
+// that keeps its default features lists "default" among its Extras. Dev and
+// build dependencies are included with their Kind set.
+func (c *Client) FetchCrateVersion(ctx context.Context, crate, version string, refresh bool) (*CrateInfo, error) {
+	cacheKey := "crates:crate:" + crate
+	if version != "" {
+		cacheKey += "@" + version
+	}
 
 	var info CrateInfo
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
-		return c.fetchCrate(ctx, crate, &info)
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchCrate(ctx, crate, version, refresh, &info)
 	}, &info)
 	if err != nil {
 		return nil, err
@@ -54,52 +69,119 @@ func (c *Client) FetchCrate(ctx context.Context, crate string, refresh bool) (*C
 	return &info, nil
 }
 
-func (c *Client) fetchCrate(ctx context.Context, crate string, info *CrateInfo) error {
-	var crateData crateResponse
-	if err := c.DoRequest(ctx, fmt.Sprintf("%s/crates/%s", c.baseURL, crate), c.headers, &crateData); err != nil {
+// FetchVersions lists the releases of a crate that were not yanked.
+func (c *Client) FetchVersions(ctx context.Context, crate string, refresh bool) ([]string, error) {
+	data, err := c.fetchCrateData(ctx, crate, refresh)
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, v := range data.Versions {
+		if !v.Yanked {
+			versions = append(versions, v.Num)
+		}
+	}
+	return versions, nil
+}
+
+// fetchCrateData fetches the crate's summary and releases, which both
+// FetchVersions and FetchCrateVersion read, so resolving and fetching a
+// crate makes one request for them.
+func (c *Client) fetchCrateData(ctx context.Context, crate string, refresh bool) (*crateResponse, error) {
+	var data crateResponse
+	err := c.FetchWithCache(ctx, "crates:data:"+crate, refresh, func(ctx context.Context) error {
+		return c.DoRequest(ctx, fmt.Sprintf("%s/crates/%s", c.baseURL, crate), c.headers, &data)
+	}, &data)
+	if err != nil {
 		if errors.Is(err, integrations.ErrNotFound) {
-			return fmt.Errorf("%w: crate %s", err, crate)
+			return nil, fmt.Errorf("%w: crate %s", err, crate)
 		}
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (c *Client) fetchCrate(ctx context.Context, crate, version string, refresh bool, info *CrateInfo) error {
+	crateData, err := c.fetchCrateData(ctx, crate, refresh)
+	if err != nil {
 		return err
 	}
+	if version == "" {
+		version = crateData.Crate.MaxVersion
+	}
 
-	deps, err := c.fetchDependencies(ctx, crate, crateData.Crate.MaxVersion)
+	deps, err := c.fetchDependencies(ctx, crate, version)
 	if err != nil {
 		return err
 	}
 
+	var features map[string][]string
+	for _, v := range crateData.Versions {
+		if v.Num == version {
+			features = v.Features
+			break
+		}
+	}
+
 	*info = CrateInfo{
 		Name:         crateData.Crate.Name,
-		Version:      crateData.Crate.MaxVersion,
+		Version:      version,
 		Description:  crateData.Crate.Description,
 		License:      crateData.Crate.License,
 		Repository:   crateData.Crate.Repository,
 		HomePage:     crateData.Crate.HomePage,
 		Downloads:    crateData.Crate.Downloads,
 		Dependencies: deps,
+		Features:     features,
 	}
 	return nil
 }
 
-func (c *Client) fetchDependencies(ctx context.Context, crate, version string) ([]string, error) {
+func (c *Client) fetchDependencies(ctx context.Context, crate, version string) ([]integrations.Dependency, error) {
 	url := fmt.Sprintf("%s/crates/%s/%s/dependencies", c.baseURL, crate, version)
 
 	var data depsResponse
 	if err := c.DoRequest(ctx, url, c.headers, &data); err != nil {
-		return nil, nil
+		return nil, fmt.Errorf("dependencies of %s %s: %w", crate, version, err)
 	}
 
-	var deps []string
+	var deps []integrations.Dependency
 	for _, d := range data.Dependencies {
-		if d.Kind == "normal" && !d.Optional {
-			deps = append(deps, d.CrateID)
+		kind, ok := depKinds[d.Kind]
+		if !ok {
+			continue
+		}
+		features := d.Features
+		if d.DefaultFeatures {
+			features = append([]string{"default"}, features...)
 		}
+		deps = append(deps, integrations.Dependency{
+			Name:       d.CrateID,
+			Constraint: d.Req,
+			Extras:     features,
+			Optional:   d.Optional,
+			Kind:       kind,
+		})
 	}
 	return deps, nil
 }
 
+// depKinds maps the crates.io dependency kinds to integrations kinds.
+var depKinds = map[string]string{
+	"normal": integrations.KindRuntime,
+	"dev":    integrations.KindDev,
+	"build":  integrations.KindBuild,
+}
+
 type crateResponse struct {
-	Crate crateData `json:"crate"`
+	Crate    crateData      `json:"crate"`
+	Versions []crateVersion `json:"versions"`
+}
+
+type crateVersion struct {
+	Num      string              `json:"num"`
+	Yanked   bool                `json:"yanked"`
+	Features map[string][]string `json:"features"`
 }
 
 type crateData struct {
@@ -117,7 +199,10 @@ type depsResponse struct {
 }
 
 type dependency struct {
-	CrateID  string `json:"crate_id"`
-	Kind     string `json:"kind"`
-	Optional bool   `json:"optional"`
+	CrateID         string   `json:"crate_id"`
+	Req             string   `json:"req"`
+	Kind            string   `json:"kind"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Features        []string `json:"features"`
 }
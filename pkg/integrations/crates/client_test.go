@@ -3,10 +3,15 @@ package crates
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+	"github.com/matzehuels/stacktower/pkg/integrations"
 )
 
 func TestNewClient(t *testing.T) {
@@ -29,11 +34,15 @@ func TestClient_FetchCrate(t *testing.T) {
 			Repository:  "https://github.com/serde-rs/serde",
 			Downloads:   1000000,
 		},
+		Versions: []crateVersion{
+			{Num: "1.0.0", Features: map[string][]string{"default": {"std"}, "derive": {"dep:optional_dep"}}},
+		},
 	}
 	depsResp := depsResponse{
 		Dependencies: []dependency{
-			{CrateID: "serde_derive", Kind: "normal", Optional: false},
+			{CrateID: "serde_derive", Kind: "normal", DefaultFeatures: true, Features: []string{"full"}},
 			{CrateID: "test_dep", Kind: "dev", Optional: false},
+			{CrateID: "cc", Kind: "build", DefaultFeatures: true},
 			{CrateID: "optional_dep", Kind: "normal", Optional: true},
 		},
 	}
@@ -64,11 +73,17 @@ func TestClient_FetchCrate(t *testing.T) {
 	if info.Version != "1.0.0" {
 		t.Errorf("expected version 1.0.0, got %s", info.Version)
 	}
-	if len(info.Dependencies) != 1 {
-		t.Errorf("expected 1 dependency, got %d", len(info.Dependencies))
+	want := []integrations.Dependency{
+		{Name: "serde_derive", Extras: []string{"default", "full"}, Kind: integrations.KindRuntime},
+		{Name: "test_dep", Kind: integrations.KindDev},
+		{Name: "cc", Extras: []string{"default"}, Kind: integrations.KindBuild},
+		{Name: "optional_dep", Optional: true, Kind: integrations.KindRuntime},
 	}
-	if info.Dependencies[0] != "serde_derive" {
-		t.Errorf("expected serde_derive, got %s", info.Dependencies[0])
+	if !reflect.DeepEqual(info.Dependencies, want) {
+		t.Errorf("dependencies = %+v, want %+v", info.Dependencies, want)
+	}
+	if got := info.Features["derive"]; len(got) != 1 || got[0] != "dep:optional_dep" {
+		t.Errorf("features = %v", info.Features)
 	}
 }
 
@@ -89,3 +104,79 @@ func TestClient_FetchCrate_NotFound(t *testing.T) {
 		t.Error("expected error for nonexistent crate")
 	}
 }
+
+func TestClient_FetchCrate_DependenciesFail(t *testing.T) {
+	throttled := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crates/serde":
+			json.NewEncoder(w).Encode(crateResponse{Crate: crateData{Name: "serde", MaxVersion: "1.0.0"}})
+		case "/crates/serde/1.0.0/dependencies":
+			if throttled {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			json.NewEncoder(w).Encode(depsResponse{Dependencies: []dependency{{CrateID: "serde_derive", Kind: "normal"}}})
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+
+	if _, err := c.FetchCrate(context.Background(), "serde", false); !errors.Is(err, integrations.ErrRateLimited) {
+		t.Fatalf("err = %v, want %v", err, integrations.ErrRateLimited)
+	}
+
+	// The failure was not cached as a crate without dependencies.
+	throttled = false
+	info, err := c.FetchCrate(context.Background(), "serde", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Dependencies) != 1 {
+		t.Errorf("dependencies = %+v, want serde_derive", info.Dependencies)
+	}
+}
+
+func TestClient_SharesCrateData(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crates/serde":
+			requests++
+			json.NewEncoder(w).Encode(crateResponse{
+				Crate:    crateData{Name: "serde", MaxVersion: "1.0.1"},
+				Versions: []crateVersion{{Num: "1.0.1"}, {Num: "1.0.0", Yanked: true}},
+			})
+		case "/crates/serde/1.0.1/dependencies":
+			json.NewEncoder(w).Encode(depsResponse{})
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+
+	versions, err := c.FetchVersions(context.Background(), "serde", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.0.1"}) {
+		t.Errorf("versions = %v, want [1.0.1]", versions)
+	}
+	if _, err := c.FetchCrateVersion(context.Background(), "serde", "1.0.1", false); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("crate fetched %d times, want once", requests)
+	}
+}
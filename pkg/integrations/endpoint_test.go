@@ -0,0 +1,89 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEndpointFor(t *testing.T) {
+	const def = "https://registry.npmjs.org"
+	t.Cleanup(func() { SetEndpoint("npm", Endpoint{}) })
+
+	if got := EndpointFor("npm", def); got.URL != def || len(got.Headers) != 0 {
+		t.Errorf("default: got %+v", got)
+	}
+
+	t.Setenv("STACKTOWER_NPM_URL", "https://verdaccio.example.com/")
+	t.Setenv("STACKTOWER_NPM_TOKEN", "secret")
+	got := EndpointFor("npm", def)
+	want := Endpoint{URL: "https://verdaccio.example.com", Headers: map[string]string{"Authorization": "Bearer secret"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("env: got %+v, want %+v", got, want)
+	}
+
+	// An override without a URL only adds headers.
+	SetEndpoint("npm", Endpoint{Headers: map[string]string{"X-Team": "infra"}})
+	got = EndpointFor("npm", def)
+	want.Headers["X-Team"] = "infra"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("headers: got %+v, want %+v", got, want)
+	}
+
+	SetEndpoint("npm", Endpoint{URL: "https://nexus.example.com/npm", Headers: map[string]string{"Authorization": "Basic abc"}})
+	got = EndpointFor("npm", def)
+	want = Endpoint{URL: "https://nexus.example.com/npm", Headers: map[string]string{"Authorization": "Basic abc"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("override: got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseEndpoints(t *testing.T) {
+	got, err := ParseEndpoints(
+		[]string{"pypi=https://devpi.example.com/root/pypi", "github=https://ghe.example.com/api/v3"},
+		[]string{"pypi=Authorization: Basic dXNlcjpwYXNz", "pypi=X-Trace: a:b"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Endpoint{
+		"pypi": {
+			URL:     "https://devpi.example.com/root/pypi",
+			Headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz", "X-Trace": "a:b"},
+		},
+		"github": {URL: "https://ghe.example.com/api/v3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, tt := range []struct{ urls, headers []string }{
+		{urls: []string{"https://example.com"}},
+		{urls: []string{"cargo=https://example.com"}},
+		{headers: []string{"npm=Authorization"}},
+	} {
+		if _, err := ParseEndpoints(tt.urls, tt.headers); err == nil {
+			t.Errorf("ParseEndpoints(%q, %q): want error", tt.urls, tt.headers)
+		}
+	}
+}
+
+func TestBaseClient_Headers(t *testing.T) {
+	var auth, accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, accept = r.Header.Get("Authorization"), r.Header.Get("Accept")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := &BaseClient{HTTP: srv.Client(), Headers: map[string]string{"Authorization": "Bearer mirror", "Accept": "*/*"}}
+	var v map[string]any
+	if err := c.DoRequest(context.Background(), srv.URL, map[string]string{"Accept": "application/json"}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer mirror" || accept != "application/json" {
+		t.Errorf("got Authorization %q and Accept %q, want the client's and the request's", auth, accept)
+	}
+}
@@ -0,0 +1,195 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+// DefaultURL is the API of Codeberg, the largest public Forgejo instance.
+const DefaultURL = "https://codeberg.org/api/v1"
+
+// Client reads repositories from the API of a Gitea-compatible forge:
+// Gitea, Forgejo or Codeberg.
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+	headers map[string]string
+	repoURL *regexp.Regexp
+}
+
+// NewClient creates a client for the forge at the gitea endpoint (see
+// integrations.EndpointFor), Codeberg unless configured otherwise.
+func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
+	return newClient(integrations.EndpointFor("gitea", DefaultURL), token, cacheTTL)
+}
+
+// NewClients creates a client for the gitea endpoint and, when that is a
+// self-hosted forge, another for Codeberg. The token and the endpoint's
+// headers are only sent to the endpoint.
+func NewClients(token string, cacheTTL time.Duration) ([]*Client, error) {
+	ep := integrations.EndpointFor("gitea", DefaultURL)
+	c, err := newClient(ep, token, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	if ep.URL == DefaultURL {
+		return []*Client{c}, nil
+	}
+	codeberg, err := newClient(integrations.Endpoint{URL: DefaultURL}, "", cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return []*Client{c, codeberg}, nil
+}
+
+func newClient(ep integrations.Endpoint, token string, cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "token " + token
+	}
+
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: ep.URL,
+		headers: headers,
+		repoURL: regexp.MustCompile(`https?://` + regexp.QuoteMeta(webHost(ep.URL)) + `/([^/]+)/([^/#?]+?)(?:\.git)?(?:[/#?]|$)`),
+	}, nil
+}
+
+// webHost returns the host of the forge whose API is at apiURL.
+func webHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "codeberg.org"
+	}
+	return u.Host
+}
+
+// ExtractURL finds the owner and name of a repository on the client's
+// forge among a package's URLs.
+func (c *Client) ExtractURL(projectURLs map[string]string, homepage string) (owner, repo string, ok bool) {
+	return integrations.ExtractRepoURL(c.repoURL, projectURLs, homepage)
+}
+
+func (c *Client) Fetch(ctx context.Context, owner, repo string, refresh bool) (*integrations.RepoMetrics, error) {
+	cacheKey := "gitea:" + webHost(c.baseURL) + ":" + owner + "/" + repo
+
+	var m integrations.RepoMetrics
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchMetrics(ctx, owner, repo, &m)
+	}, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *Client) fetchMetrics(ctx context.Context, owner, repo string, m *integrations.RepoMetrics) error {
+	var data repoResponse
+	if err := c.DoRequest(ctx, fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo), c.headers, &data); err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return fmt.Errorf("%w: gitea repo %s/%s", err, owner, repo)
+		}
+		return err
+	}
+
+	*m = integrations.RepoMetrics{
+		RepoURL:      data.HTMLURL,
+		Owner:        data.Owner.Login,
+		Stars:        data.Stars,
+		SizeKB:       data.Size,
+		LastCommitAt: data.UpdatedAt,
+		Language:     data.Language,
+		Topics:       data.Topics,
+		Archived:     data.Archived,
+	}
+	if len(data.Licenses) > 0 {
+		m.License = data.Licenses[0]
+	}
+	if release, err := c.fetchLatestRelease(ctx, owner, repo); err == nil {
+		m.LastReleaseAt = &release.PublishedAt
+	}
+	if commits, err := c.fetchCommits(ctx, owner, repo); err == nil && len(commits) > 0 {
+		m.LastCommitAt = &commits[0].Commit.Author.Date
+		m.Contributors = topAuthors(commits)
+	}
+	return nil
+}
+
+func (c *Client) fetchLatestRelease(ctx context.Context, owner, repo string) (*releaseResponse, error) {
+	var data releaseResponse
+	if err := c.DoRequest(ctx, fmt.Sprintf("%s/repos/%s/%s/releases/latest", c.baseURL, owner, repo), c.headers, &data); err != nil {
+		return nil, fmt.Errorf("no releases")
+	}
+	return &data, nil
+}
+
+// fetchCommits returns the latest commits of the default branch, newest
+// first. Gitea has no contributors endpoint, so their authors stand in for
+// the maintainers.
+func (c *Client) fetchCommits(ctx context.Context, owner, repo string) ([]commitResponse, error) {
+	var data []commitResponse
+	url := fmt.Sprintf("%s/repos/%s/%s/commits?limit=50&stat=false&verification=false&files=false", c.baseURL, owner, repo)
+	if err := c.DoRequest(ctx, url, c.headers, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// topAuthors returns the five authors with the most commits, identified by
+// their account if the forge knows it and by name otherwise.
+func topAuthors(commits []commitResponse) []integrations.Contributor {
+	logins := make([]string, len(commits))
+	for i, c := range commits {
+		logins[i] = c.Commit.Author.Name
+		if c.Author != nil && c.Author.Login != "" {
+			logins[i] = c.Author.Login
+		}
+	}
+	return integrations.TopContributors(logins, 5)
+}
+
+type repoResponse struct {
+	HTMLURL   string     `json:"html_url"`
+	Stars     int        `json:"stars_count"`
+	Size      int        `json:"size"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	Language  string     `json:"language"`
+	Topics    []string   `json:"topics"`
+	Licenses  []string   `json:"licenses"`
+	Archived  bool       `json:"archived"`
+	Owner     struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type releaseResponse struct {
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type commitResponse struct {
+	Commit struct {
+		Author struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
@@ -0,0 +1,129 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo":
+			w.Write([]byte(`{
+				"html_url": "https://codeberg.org/owner/repo",
+				"stars_count": 12,
+				"size": 300,
+				"updated_at": "2024-01-01T00:00:00Z",
+				"language": "Rust",
+				"topics": ["cli"],
+				"licenses": ["MPL-2.0"],
+				"archived": false,
+				"owner": {"login": "owner"}
+			}`))
+		case "/repos/owner/repo/releases/latest":
+			w.Write([]byte(`{"published_at": "2024-02-01T00:00:00Z"}`))
+		case "/repos/owner/repo/commits":
+			w.Write([]byte(`[
+				{"commit": {"author": {"name": "Ada", "date": "2024-03-01T00:00:00Z"}}, "author": {"login": "ada"}},
+				{"commit": {"author": {"name": "Bob", "date": "2024-02-20T00:00:00Z"}}, "author": null},
+				{"commit": {"author": {"name": "Ada L.", "date": "2024-02-10T00:00:00Z"}}, "author": {"login": "ada"}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTP = server.Client()
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+	c.baseURL = server.URL
+
+	m, err := c.Fetch(context.Background(), "owner", "repo", false)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if m.Stars != 12 || m.License != "MPL-2.0" || m.Language != "Rust" || m.Owner != "owner" {
+		t.Errorf("got %+v", m)
+	}
+	if m.LastCommitAt == nil || m.LastCommitAt.Month() != time.March {
+		t.Errorf("got last commit %v, want the newest commit's date", m.LastCommitAt)
+	}
+	if m.LastReleaseAt == nil || m.LastReleaseAt.Month() != time.February {
+		t.Errorf("got last release %v", m.LastReleaseAt)
+	}
+	if len(m.Contributors) != 2 || m.Contributors[0].Login != "ada" || m.Contributors[0].Contributions != 2 || m.Contributors[1].Login != "Bob" {
+		t.Errorf("got contributors %+v", m.Contributors)
+	}
+}
+
+func TestClient_ExtractURL(t *testing.T) {
+	c, err := NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantOK    bool
+	}{
+		{url: "https://codeberg.org/forgejo/forgejo", wantOwner: "forgejo", wantRepo: "forgejo", wantOK: true},
+		{url: "https://codeberg.org/foo/bar.git", wantOwner: "foo", wantRepo: "bar", wantOK: true},
+		{url: "https://codeberg.org/foo/bar/src/branch/main", wantOwner: "foo", wantRepo: "bar", wantOK: true},
+		{url: "https://github.com/foo/bar", wantOK: false},
+	}
+	for _, tt := range tests {
+		owner, repo, ok := c.ExtractURL(nil, tt.url)
+		if ok != tt.wantOK || owner != tt.wantOwner || repo != tt.wantRepo {
+			t.Errorf("%s: got %q %q %v, want %q %q %v", tt.url, owner, repo, ok, tt.wantOwner, tt.wantRepo, tt.wantOK)
+		}
+	}
+
+	t.Setenv("STACKTOWER_GITEA_URL", "https://git.example.com/api/v1")
+	c, err = NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.ExtractURL(nil, "https://git.example.com/team/lib"); !ok {
+		t.Error("self-hosted client did not match its own host")
+	}
+}
+
+func TestNewClients(t *testing.T) {
+	clients, err := NewClients("secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 1 || clients[0].baseURL != DefaultURL {
+		t.Fatalf("without an endpoint, want only Codeberg, got %d clients", len(clients))
+	}
+
+	t.Setenv("STACKTOWER_GITEA_URL", "https://git.example.com/api/v1")
+	clients, err = NewClients("secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 2 {
+		t.Fatalf("with a self-hosted endpoint, want 2 clients, got %d", len(clients))
+	}
+	selfHosted, codeberg := clients[0], clients[1]
+	if _, _, ok := selfHosted.ExtractURL(nil, "https://git.example.com/team/lib"); !ok {
+		t.Error("self-hosted client did not match its own host")
+	}
+	if _, _, ok := codeberg.ExtractURL(nil, "https://codeberg.org/forgejo/forgejo"); !ok {
+		t.Error("Codeberg is not enriched next to a self-hosted endpoint")
+	}
+	if selfHosted.headers["Authorization"] == "" || codeberg.headers["Authorization"] != "" {
+		t.Errorf("token should only go to the endpoint: self-hosted %v, codeberg %v", selfHosted.headers, codeberg.headers)
+	}
+}
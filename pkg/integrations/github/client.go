@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"time"
 
@@ -16,7 +17,9 @@ type Client struct {
 	integrations.BaseClient
 	token   string
 	baseURL string
+	webURL  string
 	headers map[string]string
+	repoURL *regexp.Regexp
 }
 
 func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
@@ -24,6 +27,13 @@ func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("github", "https://api.github.com")
+
+	host := webHost(ep.URL)
+	pattern := repoURLPattern
+	if host != "github.com" {
+		pattern = regexp.MustCompile(`https?://` + regexp.QuoteMeta(host) + `/([^/]+)/([^/]+)`)
+	}
 
 	headers := map[string]string{"Accept": "application/vnd.github.v3+json"}
 	if token != "" {
@@ -32,20 +42,34 @@ func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
 
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
 		token:   token,
-		baseURL: "https://api.github.com",
+		baseURL: ep.URL,
+		webURL:  "https://" + host,
 		headers: headers,
+		repoURL: pattern,
 	}, nil
 }
 
+// webHost returns the host the repositories served by the API at apiURL
+// are browsed on: github.com for the public API, the API's own host for
+// GitHub Enterprise Server (https://ghe.example.com/api/v3).
+func webHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" || u.Host == "api.github.com" {
+		return "github.com"
+	}
+	return u.Host
+}
+
 func (c *Client) Fetch(ctx context.Context, owner, repo string, refresh bool) (*integrations.RepoMetrics, error) {
 	cacheKey := "github:" + owner + "/" + repo
 
 	var m integrations.RepoMetrics
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
 		return c.fetchMetrics(ctx, owner, repo, &m)
 	}, &m)
 	if err != nil {
@@ -61,7 +85,7 @@ func (c *Client) fetchMetrics(ctx context.Context, owner, repo string, m *integr
 	}
 
 	*m = integrations.RepoMetrics{
-		RepoURL:  fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+		RepoURL:  fmt.Sprintf("%s/%s/%s", c.webURL, owner, repo),
 		Owner:    owner,
 		Stars:    repoData.Stars,
 		SizeKB:   repoData.Size,
@@ -133,7 +157,7 @@ func (c *Client) SearchPackageRepo(ctx context.Context, pkgName, manifestFile st
 	cacheKey := fmt.Sprintf("github:search:%s:%s", manifestFile, pkgName)
 
 	var result searchCacheEntry
-	err := c.FetchWithCache(ctx, cacheKey, false, func() error {
+	err := c.FetchWithCache(ctx, cacheKey, false, func(ctx context.Context) error {
 		o, r, found := c.doCodeSearch(ctx, pkgName, manifestFile)
 		result = searchCacheEntry{Owner: o, Repo: r, Found: found}
 		return nil
@@ -165,6 +189,12 @@ func ExtractURL(projectURLs map[string]string, homepage string) (owner, repo str
 	return integrations.ExtractRepoURL(repoURLPattern, projectURLs, homepage)
 }
 
+// ExtractURL is the package's ExtractURL for the client's host, which is
+// not github.com for GitHub Enterprise.
+func (c *Client) ExtractURL(projectURLs map[string]string, homepage string) (owner, repo string, ok bool) {
+	return integrations.ExtractRepoURL(c.repoURL, projectURLs, homepage)
+}
+
 type repoResponse struct {
 	Stars    int        `json:"stargazers_count"`
 	Size     int        `json:"size"`
@@ -116,3 +116,22 @@ func TestNewClient(t *testing.T) {
 		t.Error("expected http client to be initialized")
 	}
 }
+
+func TestClient_Enterprise(t *testing.T) {
+	t.Setenv("STACKTOWER_GITHUB_URL", "https://ghe.example.com/api/v3")
+	c, err := NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("got base URL %s", c.baseURL)
+	}
+
+	owner, repo, ok := c.ExtractURL(map[string]string{"Source": "https://ghe.example.com/platform/auth"}, "")
+	if !ok || owner != "platform" || repo != "auth" {
+		t.Errorf("got %s/%s, %v; want platform/auth", owner, repo, ok)
+	}
+	if _, _, ok := c.ExtractURL(nil, "https://github.com/owner/repo"); ok {
+		t.Error("enterprise client matched a github.com URL")
+	}
+}
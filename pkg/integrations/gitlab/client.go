@@ -1,18 +1,33 @@
 package gitlab
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/matzehuels/stacktower/pkg/integrations"
 )
 
-var repoURLPattern = regexp.MustCompile(`https?://gitlab\.com/([^/]+)/([^/]+)`)
+var repoURLPattern = projectURLPattern("gitlab.com")
+
+// projectURLPattern matches the URL of a project on host, which may sit in
+// nested groups (group/subgroup/project), up to any /-/ page of it. The
+// first group is the namespace, the second the project.
+func projectURLPattern(host string) *regexp.Regexp {
+	return regexp.MustCompile(`https?://` + regexp.QuoteMeta(host) +
+		`/((?:[\w.-]+/)*?[\w.-]+)/([\w.-]+?)(?:\.git)?(?:/-/|/?(?:[#?]|$))`)
+}
 
 type Client struct {
 	integrations.BaseClient
-	token   string
 	baseURL string
+	webURL  string
+	headers map[string]string
+	repoURL *regexp.Regexp
 }
 
 func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
@@ -20,16 +35,200 @@ func NewClient(token string, cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("gitlab", "https://gitlab.com/api/v4")
+
+	host := webHost(ep.URL)
+	pattern := repoURLPattern
+	if host != "gitlab.com" {
+		pattern = projectURLPattern(host)
+	}
+
+	headers := map[string]string{}
+	if token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		token:   token,
-		baseURL: "https://gitlab.com/api/v4",
+		baseURL: ep.URL,
+		webURL:  "https://" + host,
+		headers: headers,
+		repoURL: pattern,
 	}, nil
 }
 
+// webHost returns the host of the instance whose API is at apiURL
+// (https://gitlab.example.com/api/v4).
+func webHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "gitlab.com"
+	}
+	return u.Host
+}
+
+// Fetch returns the metrics of the project at namespace/project, where
+// the namespace may be a nested group path.
+func (c *Client) Fetch(ctx context.Context, namespace, project string, refresh bool) (*integrations.RepoMetrics, error) {
+	path := namespace + "/" + project
+	cacheKey := "gitlab:" + path
+
+	var m integrations.RepoMetrics
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchMetrics(ctx, path, &m)
+	}, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *Client) fetchMetrics(ctx context.Context, path string, m *integrations.RepoMetrics) error {
+	p, err := c.fetchProject(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	*m = integrations.RepoMetrics{
+		RepoURL:      p.WebURL,
+		Owner:        p.Namespace.FullPath,
+		Stars:        p.Stars,
+		LastCommitAt: p.LastActivityAt,
+		License:      licenseID(p.License),
+		Topics:       p.Topics,
+		Archived:     p.Archived,
+	}
+	if m.RepoURL == "" {
+		m.RepoURL = c.webURL + "/" + path
+	}
+	if release, err := c.fetchLatestRelease(ctx, path); err == nil {
+		m.LastReleaseAt = release.ReleasedAt
+	}
+	if contributors, err := c.fetchContributors(ctx, path); err == nil {
+		m.Contributors = contributors
+	}
+	if language, err := c.fetchLanguage(ctx, path); err == nil {
+		m.Language = language
+	}
+	return nil
+}
+
+// projectURL returns the API URL of a project's resource. Projects are
+// addressed by their URL-encoded path.
+func (c *Client) projectURL(path, resource string) string {
+	return fmt.Sprintf("%s/projects/%s%s", c.baseURL, url.PathEscape(path), resource)
+}
+
+func (c *Client) fetchProject(ctx context.Context, path string) (*projectResponse, error) {
+	var data projectResponse
+	if err := c.DoRequest(ctx, c.projectURL(path, "?license=true"), c.headers, &data); err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return nil, fmt.Errorf("%w: gitlab project %s", err, path)
+		}
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (c *Client) fetchLatestRelease(ctx context.Context, path string) (*releaseResponse, error) {
+	var data []releaseResponse
+	if err := c.DoRequest(ctx, c.projectURL(path, "/releases?per_page=1"), c.headers, &data); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0].ReleasedAt == nil {
+		return nil, fmt.Errorf("no releases")
+	}
+	return &data[0], nil
+}
+
+func (c *Client) fetchContributors(ctx context.Context, path string) ([]integrations.Contributor, error) {
+	var data []contributorResponse
+	if err := c.DoRequest(ctx, c.projectURL(path, "/repository/contributors?order_by=commits&sort=desc&per_page=5"), c.headers, &data); err != nil {
+		return nil, fmt.Errorf("no contributors")
+	}
+
+	contributors := make([]integrations.Contributor, 0, len(data))
+	for _, c := range data {
+		contributors = append(contributors, integrations.Contributor{
+			Login:         c.Name,
+			Contributions: c.Commits,
+		})
+	}
+	return contributors, nil
+}
+
+// fetchLanguage returns the language with the largest share of the
+// project's code.
+func (c *Client) fetchLanguage(ctx context.Context, path string) (string, error) {
+	var data map[string]float64
+	if err := c.DoRequest(ctx, c.projectURL(path, "/languages"), c.headers, &data); err != nil {
+		return "", err
+	}
+	var language string
+	for name, share := range data {
+		if language == "" || share > data[language] || share == data[language] && name < language {
+			language = name
+		}
+	}
+	return language, nil
+}
+
+// spdxIDs are the SPDX identifiers of the licenses GitLab detects, whose
+// keys are the identifiers in lower case.
+var spdxIDs = []string{
+	"0BSD", "AGPL-3.0", "Apache-2.0", "Artistic-2.0", "BSD-2-Clause", "BSD-3-Clause", "BSD-3-Clause-Clear",
+	"BSL-1.0", "CC0-1.0", "CC-BY-4.0", "CC-BY-SA-4.0", "EPL-1.0", "EPL-2.0", "EUPL-1.2", "GPL-2.0", "GPL-3.0",
+	"ISC", "LGPL-2.1", "LGPL-3.0", "MIT", "MPL-2.0", "MS-PL", "NCSA", "OFL-1.1", "Unlicense", "WTFPL", "Zlib",
+}
+
+func licenseID(l *licenseResponse) string {
+	if l == nil || l.Key == "" || l.Key == "other" {
+		return ""
+	}
+	for _, id := range spdxIDs {
+		if strings.EqualFold(id, l.Key) {
+			return id
+		}
+	}
+	return l.Name
+}
+
 func ExtractURL(projectURLs map[string]string, homepage string) (owner, repo string, ok bool) {
 	return integrations.ExtractRepoURL(repoURLPattern, projectURLs, homepage)
 }
+
+// ExtractURL is the package's ExtractURL for the client's instance. The
+// owner it returns is the project's namespace.
+func (c *Client) ExtractURL(projectURLs map[string]string, homepage string) (owner, repo string, ok bool) {
+	return integrations.ExtractRepoURL(c.repoURL, projectURLs, homepage)
+}
+
+type projectResponse struct {
+	WebURL         string           `json:"web_url"`
+	Stars          int              `json:"star_count"`
+	Archived       bool             `json:"archived"`
+	LastActivityAt *time.Time       `json:"last_activity_at"`
+	Topics         []string         `json:"topics"`
+	License        *licenseResponse `json:"license"`
+	Namespace      struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+}
+
+type licenseResponse struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type releaseResponse struct {
+	ReleasedAt *time.Time `json:"released_at"`
+}
+
+type contributorResponse struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
@@ -0,0 +1,117 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	var token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("PRIVATE-TOKEN")
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.EscapedPath() {
+		case "/projects/group%2Fsub%2Fproject":
+			w.Write([]byte(`{
+				"web_url": "https://gitlab.com/group/sub/project",
+				"star_count": 42,
+				"archived": true,
+				"last_activity_at": "2024-03-01T10:00:00Z",
+				"topics": ["parser"],
+				"license": {"key": "apache-2.0", "name": "Apache License 2.0"},
+				"namespace": {"full_path": "group/sub"}
+			}`))
+		case "/projects/group%2Fsub%2Fproject/releases":
+			w.Write([]byte(`[{"released_at": "2024-02-01T00:00:00Z"}]`))
+		case "/projects/group%2Fsub%2Fproject/repository/contributors":
+			w.Write([]byte(`[{"name": "Ada", "commits": 120}, {"name": "Grace", "commits": 30}]`))
+		case "/projects/group%2Fsub%2Fproject/languages":
+			w.Write([]byte(`{"Go": 80.5, "Shell": 19.5}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient("secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTP = server.Client()
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+	c.baseURL = server.URL
+
+	m, err := c.Fetch(context.Background(), "group/sub", "project", false)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	if token != "secret" {
+		t.Errorf("got PRIVATE-TOKEN %q, want the token", token)
+	}
+	if m.Stars != 42 || !m.Archived || m.Owner != "group/sub" {
+		t.Errorf("got stars %d, archived %v, owner %q", m.Stars, m.Archived, m.Owner)
+	}
+	if m.License != "Apache-2.0" {
+		t.Errorf("got license %q, want Apache-2.0", m.License)
+	}
+	if m.Language != "Go" {
+		t.Errorf("got language %q, want Go", m.Language)
+	}
+	if m.LastCommitAt == nil || m.LastReleaseAt == nil || m.LastReleaseAt.Month() != time.February {
+		t.Errorf("got last commit %v, last release %v", m.LastCommitAt, m.LastReleaseAt)
+	}
+	if len(m.Contributors) != 2 || m.Contributors[0].Login != "Ada" || m.Contributors[0].Contributions != 120 {
+		t.Errorf("got contributors %+v", m.Contributors)
+	}
+
+	if _, err := c.Fetch(context.Background(), "group", "missing", false); err == nil {
+		t.Error("expected an error for a missing project")
+	}
+}
+
+func TestExtractURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantOK    bool
+	}{
+		{url: "https://gitlab.com/foo/bar", wantOwner: "foo", wantRepo: "bar", wantOK: true},
+		{url: "https://gitlab.com/foo/bar.git", wantOwner: "foo", wantRepo: "bar", wantOK: true},
+		{url: "https://gitlab.com/foo/bar/", wantOwner: "foo", wantRepo: "bar", wantOK: true},
+		{url: "https://gitlab.com/group/subgroup/project", wantOwner: "group/subgroup", wantRepo: "project", wantOK: true},
+		{url: "https://gitlab.com/a/b/c/-/tree/main/src", wantOwner: "a/b", wantRepo: "c", wantOK: true},
+		{url: "https://gitlab.com/foo/my.lib#readme", wantOwner: "foo", wantRepo: "my.lib", wantOK: true},
+		{url: "https://gitlab.com/foo", wantOK: false},
+		{url: "https://github.com/foo/bar", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		owner, repo, ok := ExtractURL(map[string]string{"Source": tt.url}, "")
+		if ok != tt.wantOK || owner != tt.wantOwner || repo != tt.wantRepo {
+			t.Errorf("%s: got %q %q %v, want %q %q %v", tt.url, owner, repo, ok, tt.wantOwner, tt.wantRepo, tt.wantOK)
+		}
+	}
+}
+
+func TestClient_SelfHosted(t *testing.T) {
+	t.Setenv("STACKTOWER_GITLAB_URL", "https://git.example.com/api/v4")
+	c, err := NewClient("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != "https://git.example.com/api/v4" {
+		t.Errorf("got base URL %s", c.baseURL)
+	}
+	owner, repo, ok := c.ExtractURL(nil, "https://git.example.com/platform/libs/auth")
+	if !ok || owner != "platform/libs" || repo != "auth" {
+		t.Errorf("got %q %q %v, want platform/libs auth", owner, repo, ok)
+	}
+}
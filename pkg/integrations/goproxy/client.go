@@ -0,0 +1,194 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+const defaultProxyURL = "https://proxy.golang.org"
+
+type ModuleInfo struct {
+	Path         string
+	Version      string
+	Time         *time.Time
+	Dependencies []integrations.Dependency
+}
+
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+}
+
+// NewClient creates a module proxy client. An empty proxyURL falls back to the
+// goproxy endpoint (see integrations.EndpointFor), then to the first usable
+// entry of $GOPROXY, then to proxy.golang.org. file:// URLs are served
+// straight from disk, like the go command does.
+func NewClient(proxyURL string, cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	ep := integrations.EndpointFor("goproxy", proxyFromEnv(os.Getenv("GOPROXY")))
+	if proxyURL == "" {
+		proxyURL = ep.URL
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	httpClient := integrations.NewHTTPClient()
+	httpClient.Transport = transport
+
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    httpClient,
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: strings.TrimSuffix(proxyURL, "/"),
+	}, nil
+}
+
+// FetchModule returns the latest version of a module and its requirements.
+// Responses are cached per proxy, as proxies can disagree on what a module
+// holds.
+func (c *Client) FetchModule(ctx context.Context, path string, refresh bool) (*ModuleInfo, error) {
+	path = strings.TrimSpace(path)
+	cacheKey := "goproxy:" + c.baseURL + ":" + path
+
+	var info ModuleInfo
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchModule(ctx, path, &info)
+	}, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) fetchModule(ctx context.Context, path string, info *ModuleInfo) error {
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return fmt.Errorf("invalid module path %s: %w", path, err)
+	}
+
+	latest, err := c.resolveLatest(ctx, escaped)
+	if err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return fmt.Errorf("%w: go module %s", err, path)
+		}
+		return err
+	}
+
+	deps, err := c.fetchRequires(ctx, escaped, latest.Version)
+	if err != nil {
+		return err
+	}
+
+	*info = ModuleInfo{
+		Path:         path,
+		Version:      latest.Version,
+		Time:         latest.Time,
+		Dependencies: deps,
+	}
+	return nil
+}
+
+// resolveLatest mirrors the go command's @latest query: the highest release
+// in /@v/list, else the highest pre-release, else whatever /@latest reports
+// (which covers modules that only have pseudo-versions).
+func (c *Client) resolveLatest(ctx context.Context, escaped string) (*versionInfo, error) {
+	list, err := c.DoRequestRaw(ctx, fmt.Sprintf("%s/%s/@v/list", c.baseURL, escaped), nil)
+	if err != nil && !errors.Is(err, integrations.ErrNotFound) {
+		return nil, err
+	}
+
+	if v := pickLatest(strings.Fields(string(list))); v != "" {
+		return &versionInfo{Version: v}, nil
+	}
+
+	var data versionInfo
+	if err := c.DoRequest(ctx, fmt.Sprintf("%s/%s/@latest", c.baseURL, escaped), nil, &data); err != nil {
+		return nil, err
+	}
+	if data.Version == "" {
+		return nil, fmt.Errorf("%w: no versions", integrations.ErrNotFound)
+	}
+	return &data, nil
+}
+
+func (c *Client) fetchRequires(ctx context.Context, escaped, version string) ([]integrations.Dependency, error) {
+	ev, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %w", version, err)
+	}
+
+	data, err := c.DoRequestRaw(ctx, fmt.Sprintf("%s/%s/@v/%s.mod", c.baseURL, escaped, ev), nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseRequires(data)
+}
+
+func parseRequires(data []byte) ([]integrations.Dependency, error) {
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var deps []integrations.Dependency
+	for _, r := range f.Require {
+		if r.Indirect || seen[r.Mod.Path] {
+			continue
+		}
+		seen[r.Mod.Path] = true
+		deps = append(deps, integrations.Dependency{Name: r.Mod.Path, Constraint: r.Mod.Version})
+	}
+	return deps, nil
+}
+
+func pickLatest(versions []string) string {
+	var release, pre string
+	for _, v := range versions {
+		if !semver.IsValid(v) || module.IsPseudoVersion(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			if release == "" || semver.Compare(v, release) > 0 {
+				release = v
+			}
+		} else if pre == "" || semver.Compare(v, pre) > 0 {
+			pre = v
+		}
+	}
+	if release != "" {
+		return release
+	}
+	return pre
+}
+
+func proxyFromEnv(env string) string {
+	for _, entry := range strings.FieldsFunc(env, func(r rune) bool { return r == ',' || r == '|' }) {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && entry != "direct" && entry != "off" {
+			return entry
+		}
+	}
+	return defaultProxyURL
+}
+
+type versionInfo struct {
+	Version string     `json:"Version"`
+	Time    *time.Time `json:"Time"`
+}
@@ -0,0 +1,195 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+const cobraMod = `module github.com/spf13/cobra
+
+go 1.15
+
+require (
+	github.com/cpuguy83/go-md2man/v2 v2.0.6
+	github.com/inconshreveable/mousetrap v1.1.0
+	github.com/spf13/pflag v1.0.9
+	go.yaml.in/yaml/v3 v3.0.4
+	github.com/russross/blackfriday/v2 v2.1.0 // indirect
+)
+`
+
+func TestClient_FetchModule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/spf13/cobra/@v/list":
+			w.Write([]byte("v1.9.1\nv1.10.1\nv1.10.2-rc.1\nv1.2.0\n"))
+		case "/github.com/spf13/cobra/@v/v1.10.1.mod":
+			w.Write([]byte(cobraMod))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := c.FetchModule(context.Background(), "github.com/spf13/cobra", true)
+	if err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+
+	if info.Version != "v1.10.1" {
+		t.Errorf("expected version v1.10.1, got %s", info.Version)
+	}
+	if len(info.Dependencies) != 4 {
+		t.Errorf("expected 4 direct dependencies, got %d: %v", len(info.Dependencies), info.Dependencies)
+	}
+}
+
+func TestClient_FetchModule_LatestEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/!pseudo/@v/list":
+			w.Write(nil)
+		case "/example.com/!pseudo/@latest":
+			w.Write([]byte(`{"Version":"v0.0.0-20240101000000-abcdefabcdef","Time":"2024-01-01T00:00:00Z"}`))
+		case "/example.com/!pseudo/@v/v0.0.0-20240101000000-abcdefabcdef.mod":
+			w.Write([]byte("module example.com/Pseudo\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := c.FetchModule(context.Background(), "example.com/Pseudo", true)
+	if err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+	if info.Version != "v0.0.0-20240101000000-abcdefabcdef" {
+		t.Errorf("unexpected version %s", info.Version)
+	}
+	if info.Time == nil {
+		t.Error("expected time from @latest")
+	}
+}
+
+func TestClient_FetchModule_FileProxy(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, "github.com", "spf13", "cobra", "@v")
+	if err := os.MkdirAll(modDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(modDir, "list"), []byte("v1.10.1\n"), 0o644)
+	os.WriteFile(filepath.Join(modDir, "v1.10.1.mod"), []byte(cobraMod), 0o644)
+
+	c, err := NewClient("file://"+filepath.ToSlash(dir), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := c.FetchModule(context.Background(), "github.com/spf13/cobra", true)
+	if err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+	if info.Version != "v1.10.1" {
+		t.Errorf("expected version v1.10.1, got %s", info.Version)
+	}
+
+	_, err = c.FetchModule(context.Background(), "github.com/missing/module", true)
+	if !errors.Is(err, integrations.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClient_FetchModule_CachePerProxy(t *testing.T) {
+	proxy := func(version string) string {
+		dir := t.TempDir()
+		modDir := filepath.Join(dir, "github.com", "spf13", "cobra", "@v")
+		if err := os.MkdirAll(modDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(modDir, "list"), []byte(version+"\n"), 0o644)
+		os.WriteFile(filepath.Join(modDir, version+".mod"), []byte(cobraMod), 0o644)
+		return "file://" + filepath.ToSlash(dir)
+	}
+
+	first, err := NewClient(proxy("v1.9.1"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.FetchModule(context.Background(), "github.com/spf13/cobra", true); err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+
+	second, err := NewClient(proxy("v1.10.1"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Cache = first.Cache
+	info, err := second.FetchModule(context.Background(), "github.com/spf13/cobra", false)
+	if err != nil {
+		t.Fatalf("FetchModule failed: %v", err)
+	}
+	if info.Version != "v1.10.1" {
+		t.Errorf("expected v1.10.1 from the second proxy, got %s cached from the first", info.Version)
+	}
+}
+
+func TestPickLatest(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+	}{
+		{"releases", []string{"v1.2.0", "v1.10.0", "v1.9.9"}, "v1.10.0"},
+		{"prefers release", []string{"v2.0.0-beta.1", "v1.0.0"}, "v1.0.0"},
+		{"only prereleases", []string{"v0.1.0-alpha", "v0.1.0-beta"}, "v0.1.0-beta"},
+		{"ignores invalid", []string{"latest", "v1.0.0"}, "v1.0.0"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickLatest(tt.versions); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", defaultProxyURL},
+		{"direct", defaultProxyURL},
+		{"https://goproxy.corp,direct", "https://goproxy.corp"},
+		{"off", defaultProxyURL},
+		{"direct|https://mirror", "https://mirror"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			if got := proxyFromEnv(tt.env); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
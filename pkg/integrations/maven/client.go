@@ -0,0 +1,328 @@
+package maven
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+const maxModelDepth = 16
+
+type ArtifactInfo struct {
+	Name         string
+	Version      string
+	Dependencies []integrations.Dependency
+	Repository   string
+	HomePage     string
+	Description  string
+	License      string
+}
+
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+}
+
+func NewClient(cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	ep := integrations.EndpointFor("maven", "https://repo1.maven.org/maven2")
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: ep.URL,
+	}, nil
+}
+
+// FetchArtifact resolves the latest release of a groupId:artifactId
+// coordinate and returns its effective dependencies.
+func (c *Client) FetchArtifact(ctx context.Context, coord string, refresh bool) (*ArtifactInfo, error) {
+	return c.FetchArtifactVersion(ctx, coord, "", refresh)
+}
+
+// FetchArtifactVersion returns the effective dependencies of one release of
+// a groupId:artifactId coordinate; an empty version means the highest
+// release.
+func (c *Client) FetchArtifactVersion(ctx context.Context, coord, version string, refresh bool) (*ArtifactInfo, error) {
+	groupID, artifactID, err := splitCoordinate(coord)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := "maven:artifact:" + groupID + ":" + artifactID
+	if version != "" {
+		cacheKey += ":" + version
+	}
+
+	var info ArtifactInfo
+	err = c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchArtifact(ctx, groupID, artifactID, version, refresh, &info)
+	}, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// FetchVersions lists the releases of a groupId:artifactId coordinate,
+// snapshots left out, in the order maven-metadata.xml lists them.
+func (c *Client) FetchVersions(ctx context.Context, coord string, refresh bool) ([]string, error) {
+	groupID, artifactID, err := splitCoordinate(coord)
+	if err != nil {
+		return nil, err
+	}
+	md, err := c.fetchMetadata(ctx, groupID, artifactID, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return md.releases(), nil
+}
+
+func (c *Client) fetchArtifact(ctx context.Context, groupID, artifactID, version string, refresh bool, info *ArtifactInfo) error {
+	if version == "" {
+		latest, err := c.latestVersion(ctx, groupID, artifactID, refresh)
+		if err != nil {
+			return err
+		}
+		version = latest
+	}
+
+	m, err := c.effectiveModel(ctx, groupID, artifactID, version, refresh, 0)
+	if err != nil {
+		return err
+	}
+
+	licenses := make([]string, 0, len(m.Licenses))
+	for _, l := range m.Licenses {
+		if name := strings.TrimSpace(l.Name); name != "" {
+			licenses = append(licenses, name)
+		}
+	}
+
+	description := strings.TrimSpace(m.Description)
+	if description == "" {
+		description = strings.TrimSpace(m.Name)
+	}
+
+	repo := m.SCM.URL
+	if repo == "" {
+		repo = m.SCM.Connection
+	}
+
+	*info = ArtifactInfo{
+		Name:         groupID + ":" + artifactID,
+		Version:      version,
+		Description:  description,
+		License:      strings.Join(licenses, ", "),
+		Repository:   normalizeRepoURL(repo),
+		HomePage:     strings.TrimSpace(m.URL),
+		Dependencies: m.declaredDependencies(),
+	}
+	return nil
+}
+
+// latestVersion returns the highest release of an artifact. The metadata
+// lists versions in publish order, so a backported patch can come last.
+func (c *Client) latestVersion(ctx context.Context, groupID, artifactID string, refresh bool) (string, error) {
+	md, err := c.fetchMetadata(ctx, groupID, artifactID, refresh)
+	if err != nil {
+		return "", err
+	}
+	if v, err := version.Highest(version.Maven, md.releases(), ""); err == nil {
+		return v, nil
+	}
+	if md.Versioning.Latest != "" {
+		return md.Versioning.Latest, nil
+	}
+	return "", fmt.Errorf("%w: no released versions", integrations.ErrNotFound)
+}
+
+func (c *Client) fetchMetadata(ctx context.Context, groupID, artifactID string, refresh bool) (*metadataResponse, error) {
+	cacheKey := "maven:metadata:" + groupID + ":" + artifactID
+
+	var md metadataResponse
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		url := fmt.Sprintf("%s/%s/%s/maven-metadata.xml", c.baseURL, groupPath(groupID), artifactID)
+		data, err := c.DoRequestRaw(ctx, url, nil)
+		if err != nil {
+			return err
+		}
+		if err := xml.Unmarshal(data, &md); err != nil {
+			return fmt.Errorf("parse maven-metadata.xml: %w", err)
+		}
+		return nil
+	}, &md)
+	if err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return nil, fmt.Errorf("%w: maven artifact %s:%s", err, groupID, artifactID)
+		}
+		return nil, err
+	}
+	return &md, nil
+}
+
+// effectiveModel builds the effective POM: parent inheritance, property
+// interpolation, BOM imports and dependencyManagement, in that order.
+func (c *Client) effectiveModel(ctx context.Context, groupID, artifactID, version string, refresh bool, depth int) (*pom, error) {
+	m, err := c.inheritedModel(ctx, groupID, artifactID, version, refresh, depth)
+	if err != nil {
+		return nil, err
+	}
+	m.interpolate()
+	c.importBOMs(ctx, m, refresh, depth)
+	m.applyManagement()
+	return m, nil
+}
+
+func (c *Client) inheritedModel(ctx context.Context, groupID, artifactID, version string, refresh bool, depth int) (*pom, error) {
+	if depth > maxModelDepth {
+		return nil, fmt.Errorf("pom hierarchy of %s:%s too deep", groupID, artifactID)
+	}
+
+	p, err := c.fetchPOM(ctx, groupID, artifactID, version, refresh)
+	if err != nil {
+		return nil, err
+	}
+	if p.GroupID == "" && p.Parent == nil {
+		p.GroupID = groupID
+	}
+	if p.Parent == nil {
+		return p, nil
+	}
+
+	parentGroup, parentArtifact, parentVersion := p.expand(p.Parent.GroupID), p.expand(p.Parent.ArtifactID), p.expand(p.Parent.Version)
+	parent, err := c.inheritedModel(ctx, parentGroup, parentArtifact, parentVersion, refresh, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("parent %s:%s:%s: %w", parentGroup, parentArtifact, parentVersion, err)
+	}
+	p.inherit(parent)
+	return p, nil
+}
+
+// importBOMs replaces scope=import entries in dependencyManagement with the
+// managed dependencies of the referenced BOM. Entries declared directly win
+// over imported ones, and earlier imports win over later ones. BOMs that
+// cannot be fetched are skipped rather than failing the whole artifact.
+func (c *Client) importBOMs(ctx context.Context, m *pom, refresh bool, depth int) {
+	declared := make(map[string]bool)
+	var managed, imports []dependency
+	for _, d := range m.DependencyManagement {
+		if d.isBOMImport() {
+			imports = append(imports, d)
+			continue
+		}
+		declared[d.key()] = true
+		managed = append(managed, d)
+	}
+
+	for _, imp := range imports {
+		bom, err := c.effectiveModel(ctx, imp.GroupID, imp.ArtifactID, imp.Version, refresh, depth+1)
+		if err != nil {
+			continue
+		}
+		for _, d := range bom.DependencyManagement {
+			if !declared[d.key()] {
+				declared[d.key()] = true
+				managed = append(managed, d)
+			}
+		}
+	}
+	m.DependencyManagement = managed
+}
+
+func (c *Client) fetchPOM(ctx context.Context, groupID, artifactID, version string, refresh bool) (*pom, error) {
+	if groupID == "" || artifactID == "" || version == "" || strings.Contains(version, "${") {
+		return nil, fmt.Errorf("incomplete coordinate %s:%s:%s", groupID, artifactID, version)
+	}
+	cacheKey := fmt.Sprintf("maven:pom:%s:%s:%s", groupID, artifactID, version)
+
+	var p pom
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		url := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", c.baseURL, groupPath(groupID), artifactID, version, artifactID, version)
+		data, err := c.DoRequestRaw(ctx, url, nil)
+		if err != nil {
+			if errors.Is(err, integrations.ErrNotFound) {
+				return fmt.Errorf("%w: pom %s:%s:%s", err, groupID, artifactID, version)
+			}
+			return err
+		}
+		parsed, err := parsePOM(data)
+		if err != nil {
+			return err
+		}
+		p = *parsed
+		return nil
+	}, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func splitCoordinate(coord string) (groupID, artifactID string, err error) {
+	parts := strings.Split(strings.TrimSpace(coord), ":")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid maven coordinate %q (want groupId:artifactId)", coord)
+	}
+	return parts[0], parts[1], nil
+}
+
+func groupPath(groupID string) string {
+	return strings.ReplaceAll(groupID, ".", "/")
+}
+
+func isSnapshot(v string) bool {
+	return strings.HasSuffix(v, "-SNAPSHOT")
+}
+
+func normalizeRepoURL(url string) string {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return ""
+	}
+	for _, prefix := range []string{"scm:git:", "scm:svn:", "scm:hg:", "git+"} {
+		url = strings.TrimPrefix(url, prefix)
+	}
+	url = strings.ReplaceAll(url, "git@github.com:", "https://github.com/")
+	url = strings.ReplaceAll(url, "ssh://git@github.com/", "https://github.com/")
+	url = strings.ReplaceAll(url, "git://github.com/", "https://github.com/")
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return ""
+	}
+	return url
+}
+
+type metadataResponse struct {
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+// releases returns the listed versions that are not snapshots, with the
+// release version added if the list misses it.
+func (md *metadataResponse) releases() []string {
+	var versions []string
+	for _, v := range md.Versioning.Versions {
+		if !isSnapshot(v) {
+			versions = append(versions, v)
+		}
+	}
+	if v := md.Versioning.Release; v != "" && !isSnapshot(v) && !slices.Contains(versions, v) {
+		versions = append(versions, v)
+	}
+	return versions
+}
@@ -0,0 +1,285 @@
+package maven
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+var testRepo = map[string]string{
+	"/org/acme/widget/maven-metadata.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>org.acme</groupId>
+  <artifactId>widget</artifactId>
+  <versioning>
+    <latest>2.1.0-SNAPSHOT</latest>
+    <release>2.0.0</release>
+    <versions><version>1.0.0</version><version>2.0.0</version><version>2.1.0-SNAPSHOT</version></versions>
+  </versioning>
+</metadata>`,
+
+	"/org/acme/widget/2.0.0/widget-2.0.0.pom": `<?xml version="1.0" encoding="ISO-8859-1"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>org.acme</groupId>
+    <artifactId>acme-parent</artifactId>
+    <version>5</version>
+  </parent>
+  <artifactId>widget</artifactId>
+  <version>2.0.0</version>
+  <description>Widgets for everyone</description>
+  <properties>
+    <jackson.version>2.17.0</jackson.version>
+  </properties>
+  <dependencies>
+    <dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId></dependency>
+    <dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId><version>${guava.version}</version></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13</version><scope>test</scope></dependency>
+    <dependency><groupId>org.projectlombok</groupId><artifactId>lombok</artifactId><version>1.18</version><scope>provided</scope></dependency>
+    <dependency><groupId>org.acme</groupId><artifactId>extras</artifactId><version>${project.version}</version><optional>true</optional></dependency>
+    <dependency><groupId>org.apache.commons</groupId><artifactId>commons-lang3</artifactId></dependency>
+  </dependencies>
+</project>`,
+
+	"/org/acme/widget/1.0.0/widget-1.0.0.pom": `<project>
+  <groupId>org.acme</groupId>
+  <artifactId>widget</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>1.7.36</version></dependency>
+  </dependencies>
+</project>`,
+
+	// Versions in publish order: 1.9.5 was backported after 2.0.0.
+	"/org/acme/gadget/maven-metadata.xml": `<metadata>
+  <versioning>
+    <release>1.9.5</release>
+    <versions><version>1.9.0</version><version>2.0.0</version><version>1.9.5</version></versions>
+  </versioning>
+</metadata>`,
+
+	"/org/acme/gadget/2.0.0/gadget-2.0.0.pom": `<project>
+  <groupId>org.acme</groupId>
+  <artifactId>gadget</artifactId>
+  <version>2.0.0</version>
+</project>`,
+
+	"/org/acme/acme-parent/5/acme-parent-5.pom": `<project>
+  <groupId>org.acme</groupId>
+  <artifactId>acme-parent</artifactId>
+  <version>5</version>
+  <packaging>pom</packaging>
+  <url>https://acme.org</url>
+  <scm><url>scm:git:git@github.com:acme/widget.git</url></scm>
+  <licenses><license><name>Apache-2.0</name></license></licenses>
+  <properties>
+    <guava.version>33.0-jre</guava.version>
+    <jackson.version>2.15.0</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson</groupId><artifactId>jackson-bom</artifactId>
+        <version>${jackson.version}</version><type>pom</type><scope>import</scope>
+      </dependency>
+      <dependency><groupId>org.apache.commons</groupId><artifactId>commons-lang3</artifactId><version>3.14.0</version><scope>test</scope></dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.9</version></dependency>
+  </dependencies>
+</project>`,
+
+	"/com/fasterxml/jackson/jackson-bom/2.17.0/jackson-bom-2.17.0.pom": `<project>
+  <groupId>com.fasterxml.jackson</groupId>
+  <artifactId>jackson-bom</artifactId>
+  <version>2.17.0</version>
+  <dependencyManagement>
+    <dependencies>
+      <dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>${project.version}</version></dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`,
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := testRepo[r.URL.Path]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+	return c
+}
+
+func TestClient_FetchArtifact(t *testing.T) {
+	c := newTestClient(t)
+
+	info, err := c.FetchArtifact(context.Background(), "org.acme:widget", true)
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+
+	if info.Version != "2.0.0" {
+		t.Errorf("expected version 2.0.0, got %s", info.Version)
+	}
+	if info.Repository != "https://github.com/acme/widget" {
+		t.Errorf("expected inherited scm url, got %q", info.Repository)
+	}
+	if info.License != "Apache-2.0" {
+		t.Errorf("expected inherited license, got %q", info.License)
+	}
+
+	want := []integrations.Dependency{
+		{Name: "org.slf4j:slf4j-api", Constraint: "2.0.9", Kind: integrations.KindRuntime},
+		{Name: "com.fasterxml.jackson.core:jackson-databind", Constraint: "2.17.0", Kind: integrations.KindRuntime},
+		{Name: "com.google.guava:guava", Constraint: "33.0-jre", Kind: integrations.KindRuntime},
+		{Name: "junit:junit", Constraint: "4.13", Kind: integrations.KindDev},
+		{Name: "org.apache.commons:commons-lang3", Constraint: "3.14.0", Kind: integrations.KindDev},
+	}
+	if !reflect.DeepEqual(info.Dependencies, want) {
+		t.Errorf("dependencies = %v, want %v", info.Dependencies, want)
+	}
+}
+
+func TestClient_FetchArtifactVersion(t *testing.T) {
+	c := newTestClient(t)
+
+	info, err := c.FetchArtifactVersion(context.Background(), "org.acme:widget", "1.0.0", true)
+	if err != nil {
+		t.Fatalf("FetchArtifactVersion failed: %v", err)
+	}
+	if info.Version != "1.0.0" {
+		t.Errorf("expected version 1.0.0, got %s", info.Version)
+	}
+	want := []integrations.Dependency{{Name: "org.slf4j:slf4j-api", Constraint: "1.7.36", Kind: integrations.KindRuntime}}
+	if !reflect.DeepEqual(info.Dependencies, want) {
+		t.Errorf("dependencies = %v, want %v", info.Dependencies, want)
+	}
+
+	// The latest release is cached apart from the pinned one.
+	latest, err := c.FetchArtifact(context.Background(), "org.acme:widget", false)
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+	if latest.Version != "2.0.0" {
+		t.Errorf("expected latest version 2.0.0, got %s", latest.Version)
+	}
+}
+
+func TestClient_LatestVersionPublishOrder(t *testing.T) {
+	c := newTestClient(t)
+
+	versions, err := c.FetchVersions(context.Background(), "org.acme:gadget", true)
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if want := []string{"1.9.0", "2.0.0", "1.9.5"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("versions = %v, want %v", versions, want)
+	}
+
+	info, err := c.FetchArtifact(context.Background(), "org.acme:gadget", true)
+	if err != nil {
+		t.Fatalf("FetchArtifact failed: %v", err)
+	}
+	if info.Version != "2.0.0" {
+		t.Errorf("expected highest version 2.0.0, got %s", info.Version)
+	}
+}
+
+func TestClient_EffectiveModel(t *testing.T) {
+	c := newTestClient(t)
+
+	m, err := c.effectiveModel(context.Background(), "org.acme", "widget", "2.0.0", true, 0)
+	if err != nil {
+		t.Fatalf("effectiveModel failed: %v", err)
+	}
+
+	versions := make(map[string]string)
+	for _, d := range m.Dependencies {
+		versions[d.ArtifactID] = d.Version
+	}
+
+	tests := map[string]string{
+		"jackson-databind": "2.17.0", // BOM version bound to the child's property
+		"guava":            "33.0-jre",
+		"extras":           "2.0.0",
+		"commons-lang3":    "3.14.0",
+	}
+	for artifact, want := range tests {
+		if got := versions[artifact]; got != want {
+			t.Errorf("%s version = %q, want %q", artifact, got, want)
+		}
+	}
+}
+
+func TestClient_FetchArtifact_NotFound(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.FetchArtifact(context.Background(), "org.acme:missing", true)
+	if !errors.Is(err, integrations.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSplitCoordinate(t *testing.T) {
+	tests := []struct {
+		coord    string
+		group    string
+		artifact string
+		wantErr  bool
+	}{
+		{"org.slf4j:slf4j-api", "org.slf4j", "slf4j-api", false},
+		{"org.slf4j:slf4j-api:2.0.9", "org.slf4j", "slf4j-api", false},
+		{"slf4j-api", "", "", true},
+		{":x", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coord, func(t *testing.T) {
+			g, a, err := splitCoordinate(tt.coord)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if g != tt.group || a != tt.artifact {
+				t.Errorf("got %s:%s, want %s:%s", g, a, tt.group, tt.artifact)
+			}
+		})
+	}
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"scm:git:git@github.com:FasterXML/jackson-databind.git", "https://github.com/FasterXML/jackson-databind"},
+		{"scm:git:https://github.com/google/guava.git", "https://github.com/google/guava"},
+		{"https://github.com/qos-ch/slf4j/", "https://github.com/qos-ch/slf4j"},
+		{"scm:svn:svn://svn.apache.org/repos", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := normalizeRepoURL(tt.input); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package maven
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+const maxInterpolationPasses = 10
+
+var propertyRE = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+var skippedScopes = map[string]bool{
+	"provided": true,
+	"system":   true,
+	"import":   true,
+}
+
+type pom struct {
+	Parent               *parentRef   `xml:"parent"`
+	GroupID              string       `xml:"groupId"`
+	ArtifactID           string       `xml:"artifactId"`
+	Version              string       `xml:"version"`
+	Packaging            string       `xml:"packaging"`
+	Name                 string       `xml:"name"`
+	Description          string       `xml:"description"`
+	URL                  string       `xml:"url"`
+	SCM                  scm          `xml:"scm"`
+	Licenses             []license    `xml:"licenses>license"`
+	Properties           properties   `xml:"properties"`
+	DependencyManagement []dependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []dependency `xml:"dependencies>dependency"`
+}
+
+type parentRef struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type scm struct {
+	URL        string `xml:"url"`
+	Connection string `xml:"connection"`
+}
+
+type license struct {
+	Name string `xml:"name"`
+}
+
+type dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+func (d dependency) key() string { return d.GroupID + ":" + d.ArtifactID }
+
+func (d dependency) isBOMImport() bool {
+	return d.Scope == "import" && d.Type == "pom"
+}
+
+// properties captures the free-form <properties> block, where every child
+// element name is a property key.
+type properties map[string]string
+
+func (p *properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = properties{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func parsePOM(data []byte) (*pom, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	var p pom
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parse pom: %w", err)
+	}
+	return &p, nil
+}
+
+// inherit merges a parent model into p following Maven's inheritance rules:
+// the child wins for scalars, properties and managed/declared dependencies
+// with the same key, and everything else is inherited. Values are left
+// uninterpolated so that parent expressions bind to the child's properties.
+func (p *pom) inherit(parent *pom) {
+	if p.GroupID == "" {
+		p.GroupID = parent.GroupID
+	}
+	if p.Version == "" {
+		p.Version = parent.Version
+	}
+	if p.URL == "" {
+		p.URL = parent.URL
+	}
+	if p.SCM.URL == "" && p.SCM.Connection == "" {
+		p.SCM = parent.SCM
+	}
+	if len(p.Licenses) == 0 {
+		p.Licenses = parent.Licenses
+	}
+
+	props := maps.Clone(parent.Properties)
+	if props == nil {
+		props = properties{}
+	}
+	maps.Copy(props, p.Properties)
+	p.Properties = props
+
+	p.DependencyManagement = mergeDeps(parent.DependencyManagement, p.DependencyManagement)
+	p.Dependencies = mergeDeps(parent.Dependencies, p.Dependencies)
+}
+
+func mergeDeps(inherited, own []dependency) []dependency {
+	declared := make(map[string]bool, len(own))
+	for _, d := range own {
+		declared[d.key()] = true
+	}
+	merged := make([]dependency, 0, len(inherited)+len(own))
+	for _, d := range inherited {
+		if !declared[d.key()] {
+			merged = append(merged, d)
+		}
+	}
+	return append(merged, own...)
+}
+
+func (p *pom) interpolate() {
+	resolve := func(s string) string { return p.expand(s) }
+
+	p.GroupID = resolve(p.GroupID)
+	p.Version = resolve(p.Version)
+	p.URL = resolve(p.URL)
+	p.SCM.URL = resolve(p.SCM.URL)
+	p.SCM.Connection = resolve(p.SCM.Connection)
+	for i := range p.DependencyManagement {
+		p.DependencyManagement[i] = interpolateDep(p.DependencyManagement[i], resolve)
+	}
+	for i := range p.Dependencies {
+		p.Dependencies[i] = interpolateDep(p.Dependencies[i], resolve)
+	}
+}
+
+func interpolateDep(d dependency, resolve func(string) string) dependency {
+	d.GroupID = resolve(d.GroupID)
+	d.ArtifactID = resolve(d.ArtifactID)
+	d.Version = resolve(d.Version)
+	d.Type = resolve(d.Type)
+	d.Scope = resolve(d.Scope)
+	d.Optional = resolve(d.Optional)
+	return d
+}
+
+func (p *pom) expand(s string) string {
+	for range maxInterpolationPasses {
+		if !strings.Contains(s, "${") {
+			return s
+		}
+		next := propertyRE.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := p.lookup(m[2 : len(m)-1]); ok {
+				return v
+			}
+			return m
+		})
+		if next == s {
+			return s
+		}
+		s = next
+	}
+	return s
+}
+
+func (p *pom) lookup(key string) (string, bool) {
+	switch key {
+	case "project.groupId", "pom.groupId", "groupId":
+		return p.GroupID, p.GroupID != ""
+	case "project.artifactId", "pom.artifactId", "artifactId":
+		return p.ArtifactID, p.ArtifactID != ""
+	case "project.version", "pom.version", "version":
+		return p.Version, p.Version != ""
+	case "project.parent.groupId", "parent.groupId":
+		if p.Parent != nil {
+			return p.Parent.GroupID, p.Parent.GroupID != ""
+		}
+	case "project.parent.version", "parent.version":
+		if p.Parent != nil {
+			return p.Parent.Version, p.Parent.Version != ""
+		}
+	}
+	v, ok := p.Properties[key]
+	return v, ok
+}
+
+// applyManagement fills in missing versions and scopes from
+// dependencyManagement, as Maven does before resolving the dependency tree.
+func (p *pom) applyManagement() {
+	managed := make(map[string]dependency, len(p.DependencyManagement))
+	for _, d := range p.DependencyManagement {
+		if _, exists := managed[d.key()]; !exists {
+			managed[d.key()] = d
+		}
+	}
+	for i, d := range p.Dependencies {
+		m, ok := managed[d.key()]
+		if !ok {
+			continue
+		}
+		if d.Version == "" {
+			d.Version = m.Version
+		}
+		if d.Scope == "" {
+			d.Scope = m.Scope
+		}
+		if d.Optional == "" {
+			d.Optional = m.Optional
+		}
+		p.Dependencies[i] = d
+	}
+}
+
+// declaredDependencies returns the dependencies a build of the artifact
+// uses, with test-scoped ones as dev dependencies. Optional dependencies
+// are left out, as Maven never resolves them transitively.
+func (p *pom) declaredDependencies() []integrations.Dependency {
+	seen := make(map[string]bool)
+	var deps []integrations.Dependency
+	for _, d := range p.Dependencies {
+		if skippedScopes[d.Scope] || strings.EqualFold(d.Optional, "true") {
+			continue
+		}
+		if d.GroupID == "" || d.ArtifactID == "" || strings.Contains(d.key(), "${") {
+			continue
+		}
+		if k := d.key(); !seen[k] {
+			seen[k] = true
+			kind := integrations.KindRuntime
+			if d.Scope == "test" {
+				kind = integrations.KindDev
+			}
+			deps = append(deps, integrations.Dependency{Name: k, Constraint: d.Version, Kind: kind})
+		}
+	}
+	return deps
+}
@@ -2,6 +2,7 @@ package npm
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"maps"
@@ -15,7 +16,7 @@ import (
 type PackageInfo struct {
 	Name         string
 	Version      string
-	Dependencies []string
+	Dependencies []integrations.Dependency
 	Repository   string
 	HomePage     string
 	Description  string
@@ -33,21 +34,23 @@ func NewClient(cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("npm", "https://registry.npmjs.org")
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		baseURL: "https://registry.npmjs.org",
+		baseURL: ep.URL,
 	}, nil
 }
 
 func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*PackageInfo, error) {
 	pkg = normalizeName(pkg)
-	cacheKey := "npm:" + pkg
+	cacheKey := "npm:manifest:" + pkg
 
 	var info PackageInfo
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
 		return c.fetchPackage(ctx, pkg, &info)
 	}, &info)
 	if err != nil {
@@ -56,6 +59,60 @@ func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*P
 	return &info, nil
 }
 
+// FetchPackageVersion fetches the manifest of one published version; an
+// empty version means the latest dist-tag.
+func (c *Client) FetchPackageVersion(ctx context.Context, pkg, version string, refresh bool) (*PackageInfo, error) {
+	if version == "" {
+		return c.FetchPackage(ctx, pkg, refresh)
+	}
+	pkg = normalizeName(pkg)
+	cacheKey := "npm:manifest:" + pkg + "@" + version
+
+	var info PackageInfo
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		var vd versionDetails
+		if err := c.DoRequest(ctx, c.baseURL+"/"+pkg+"/"+version, nil, &vd); err != nil {
+			if errors.Is(err, integrations.ErrNotFound) {
+				return fmt.Errorf("%w: npm package %s@%s", err, pkg, version)
+			}
+			return err
+		}
+		info = newPackageInfo(vd.Name, vd.Version, vd)
+		return nil
+	}, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// FetchVersions lists all published versions using the abbreviated
+// install metadata, which is much smaller than the full document.
+func (c *Client) FetchVersions(ctx context.Context, pkg string, refresh bool) ([]string, error) {
+	pkg = normalizeName(pkg)
+	cacheKey := "npm:versions:" + pkg
+
+	var versions []string
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		var data struct {
+			Versions map[string]json.RawMessage `json:"versions"`
+		}
+		headers := map[string]string{"Accept": "application/vnd.npm.install-v1+json"}
+		if err := c.DoRequest(ctx, c.baseURL+"/"+pkg, headers, &data); err != nil {
+			if errors.Is(err, integrations.ErrNotFound) {
+				return fmt.Errorf("%w: npm package %s", err, pkg)
+			}
+			return err
+		}
+		versions = slices.Sorted(maps.Keys(data.Versions))
+		return nil
+	}, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
 func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo) error {
 	var data registryResponse
 	if err := c.DoRequest(ctx, c.baseURL+"/"+pkg, nil, &data); err != nil {
@@ -71,17 +128,53 @@ func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo
 		return fmt.Errorf("version %s not found in registry data", v)
 	}
 
-	*info = PackageInfo{
-		Name:         data.Name,
-		Version:      v,
+	*info = newPackageInfo(data.Name, v, vd)
+	return nil
+}
+
+func newPackageInfo(name, version string, vd versionDetails) PackageInfo {
+	deps := ExtractDeps(vd.Dependencies, vd.OptionalDependencies, vd.PeerDependencies, vd.DevDependencies)
+	return PackageInfo{
+		Name:         name,
+		Version:      version,
 		Description:  vd.Description,
 		License:      extractString(vd.License, "type"),
 		Author:       extractString(vd.Author, "name"),
 		Repository:   normalizeRepoURL(extractString(vd.Repository, "url")),
 		HomePage:     vd.HomePage,
-		Dependencies: slices.Collect(maps.Keys(vd.Dependencies)),
+		Dependencies: deps,
 	}
-	return nil
+}
+
+// ExtractDeps merges the dependency maps of a package manifest into one
+// list sorted by name, each dependency tagged with its kind. npm publishes
+// optional dependencies in both dependencies and optionalDependencies, and
+// a package often lists a peer again as a dev dependency; the kind that
+// matters at install time wins.
+func ExtractDeps(runtime, optional, peer, dev map[string]string) []integrations.Dependency {
+	kinds := []struct {
+		kind string
+		deps map[string]string
+	}{
+		{integrations.KindOptional, optional},
+		{integrations.KindRuntime, runtime},
+		{integrations.KindPeer, peer},
+		{integrations.KindDev, dev},
+	}
+	byName := make(map[string]integrations.Dependency)
+	for _, k := range kinds {
+		for dep, constraint := range k.deps {
+			dep = normalizeName(dep)
+			if _, ok := byName[dep]; !ok {
+				byName[dep] = integrations.Dependency{Name: dep, Constraint: constraint, Kind: k.kind}
+			}
+		}
+	}
+	deps := make([]integrations.Dependency, 0, len(byName))
+	for _, dep := range slices.Sorted(maps.Keys(byName)) {
+		deps = append(deps, byName[dep])
+	}
+	return deps
 }
 
 func extractString(v any, field string) string {
@@ -122,10 +215,15 @@ type distTags struct {
 }
 
 type versionDetails struct {
-	Description  string            `json:"description"`
-	License      any               `json:"license"`
-	Author       any               `json:"author"`
-	Repository   any               `json:"repository"`
-	HomePage     string            `json:"homepage"`
-	Dependencies map[string]string `json:"dependencies"`
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Description          string            `json:"description"`
+	License              any               `json:"license"`
+	Author               any               `json:"author"`
+	Repository           any               `json:"repository"`
+	HomePage             string            `json:"homepage"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
 }
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
 )
 
 func TestNewClient(t *testing.T) {
@@ -95,6 +98,54 @@ func TestClient_FetchPackage_NotFound(t *testing.T) {
 	}
 }
 
+func TestClient_FetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/express":
+			if r.Header.Get("Accept") != "application/vnd.npm.install-v1+json" {
+				t.Errorf("Accept = %q, want abbreviated metadata", r.Header.Get("Accept"))
+			}
+			w.Write([]byte(`{"name":"express","versions":{"4.17.3":{},"4.18.0":{},"5.0.0":{}}}`))
+		case "/express/4.17.3":
+			w.Write([]byte(`{"name":"express","version":"4.17.3","dependencies":{"cookie":"0.4.2","accepts":"~1.3.8"},"optionalDependencies":{"cookie":"0.4.x"},"peerDependencies":{"react":"^18"},"devDependencies":{"react":"^18.2.0","mocha":"^10"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+
+	versions, err := c.FetchVersions(context.Background(), "express", true)
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[0] != "4.17.3" {
+		t.Errorf("versions = %v", versions)
+	}
+
+	info, err := c.FetchPackageVersion(context.Background(), "express", "4.17.3", true)
+	if err != nil {
+		t.Fatalf("FetchPackageVersion failed: %v", err)
+	}
+	if info.Version != "4.17.3" {
+		t.Errorf("expected version 4.17.3, got %s", info.Version)
+	}
+	want := []integrations.Dependency{
+		{Name: "accepts", Constraint: "~1.3.8", Kind: integrations.KindRuntime},
+		{Name: "cookie", Constraint: "0.4.x", Kind: integrations.KindOptional},
+		{Name: "mocha", Constraint: "^10", Kind: integrations.KindDev},
+		{Name: "react", Constraint: "^18", Kind: integrations.KindPeer},
+	}
+	if !reflect.DeepEqual(info.Dependencies, want) {
+		t.Errorf("dependencies = %#v", info.Dependencies)
+	}
+}
+
 func TestNormalizeRepoURL(t *testing.T) {
 	tests := []struct {
 		name     string
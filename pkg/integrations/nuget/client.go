@@ -0,0 +1,224 @@
+package nuget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+type PackageInfo struct {
+	Name             string
+	Version          string
+	DependencyGroups []DependencyGroup
+	ProjectURL       string
+	Description      string
+	License          string
+	Authors          string
+}
+
+type DependencyGroup struct {
+	TargetFramework string
+	Dependencies    []Dependency
+}
+
+type Dependency struct {
+	ID    string
+	Range string
+}
+
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+}
+
+func NewClient(cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	ep := integrations.EndpointFor("nuget", "https://api.nuget.org/v3/registration5-gz-semver2")
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: ep.URL,
+	}, nil
+}
+
+// FetchPackage fetches the highest listed stable version of a package, or
+// its highest pre-release if it has no stable one.
+func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*PackageInfo, error) {
+	return c.FetchPackageVersion(ctx, pkg, "", refresh)
+}
+
+// FetchPackageVersion fetches one listed version of a package; an empty
+// version means the one FetchPackage picks.
+func (c *Client) FetchPackageVersion(ctx context.Context, pkg, version string, refresh bool) (*PackageInfo, error) {
+	pkg = NormalizeName(pkg)
+	cacheKey := "nuget:" + pkg
+	if version != "" {
+		cacheKey += "@" + version
+	}
+
+	var info PackageInfo
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchPackage(ctx, pkg, version, refresh, &info)
+	}, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// FetchVersions lists the listed versions of a package.
+func (c *Client) FetchVersions(ctx context.Context, pkg string, refresh bool) ([]string, error) {
+	entries, err := c.fetchEntries(ctx, NormalizeName(pkg), refresh)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, len(entries))
+	for i, e := range entries {
+		versions[i] = e.Version
+	}
+	return versions, nil
+}
+
+// fetchEntries fetches the listed catalog entries of a package, which both
+// FetchVersions and FetchPackageVersion read. Pages of large packages are
+// not inlined in the registration index and have to be fetched separately.
+func (c *Client) fetchEntries(ctx context.Context, pkg string, refresh bool) ([]catalogEntry, error) {
+	var entries []catalogEntry
+	err := c.FetchWithCache(ctx, "nuget:registration:"+pkg, refresh, func(ctx context.Context) error {
+		var index registrationIndex
+		if err := c.DoRequest(ctx, fmt.Sprintf("%s/%s/index.json", c.baseURL, pkg), nil, &index); err != nil {
+			return err
+		}
+		entries = nil
+		for _, page := range index.Items {
+			if len(page.Items) == 0 && page.ID != "" {
+				if err := c.DoRequest(ctx, page.ID, nil, &page); err != nil {
+					return err
+				}
+			}
+			for _, leaf := range page.Items {
+				if e := leaf.CatalogEntry; e.Listed == nil || *e.Listed {
+					entries = append(entries, e)
+				}
+			}
+		}
+		return nil
+	}, &entries)
+	if err != nil {
+		if errors.Is(err, integrations.ErrNotFound) {
+			return nil, fmt.Errorf("%w: nuget package %s", err, pkg)
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *Client) fetchPackage(ctx context.Context, pkg, version string, refresh bool, info *PackageInfo) error {
+	entries, err := c.fetchEntries(ctx, pkg, refresh)
+	if err != nil {
+		return err
+	}
+	entry, err := findEntry(entries, version)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pkg, err)
+	}
+
+	groups := make([]DependencyGroup, 0, len(entry.DependencyGroups))
+	for _, g := range entry.DependencyGroups {
+		deps := make([]Dependency, 0, len(g.Dependencies))
+		for _, d := range g.Dependencies {
+			deps = append(deps, Dependency{ID: NormalizeName(d.ID), Range: d.Range})
+		}
+		groups = append(groups, DependencyGroup{TargetFramework: g.TargetFramework, Dependencies: deps})
+	}
+
+	license := entry.LicenseExpression
+	if license == "" {
+		license = entry.LicenseURL
+	}
+
+	*info = PackageInfo{
+		Name:             entry.ID,
+		Version:          entry.Version,
+		DependencyGroups: groups,
+		ProjectURL:       entry.ProjectURL,
+		Description:      entry.Description,
+		License:          license,
+		Authors:          entry.Authors,
+	}
+	return nil
+}
+
+// findEntry returns the entry of version, or of the highest stable version
+// when version is empty, falling back to the highest pre-release.
+func findEntry(entries []catalogEntry, v string) (*catalogEntry, error) {
+	if v == "" {
+		versions := make([]string, len(entries))
+		for i, e := range entries {
+			versions[i] = e.Version
+		}
+		highest, err := version.Highest(version.NuGet, versions, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: no listed versions", integrations.ErrNotFound)
+		}
+		v = highest
+	}
+	for i, e := range entries {
+		if strings.EqualFold(e.Version, v) {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: version %s not listed", integrations.ErrNotFound, v)
+}
+
+// NormalizeName returns the form in which NuGet package IDs, which are
+// case-insensitive, are requested and identified in graphs.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+type registrationIndex struct {
+	Items []registrationPage `json:"items"`
+}
+
+type registrationPage struct {
+	ID    string             `json:"@id"`
+	Items []registrationLeaf `json:"items"`
+}
+
+type registrationLeaf struct {
+	CatalogEntry catalogEntry `json:"catalogEntry"`
+}
+
+type catalogEntry struct {
+	ID                string            `json:"id"`
+	Version           string            `json:"version"`
+	Description       string            `json:"description"`
+	Authors           string            `json:"authors"`
+	LicenseExpression string            `json:"licenseExpression"`
+	LicenseURL        string            `json:"licenseUrl"`
+	ProjectURL        string            `json:"projectUrl"`
+	Listed            *bool             `json:"listed"`
+	DependencyGroups  []dependencyGroup `json:"dependencyGroups"`
+}
+
+type dependencyGroup struct {
+	TargetFramework string              `json:"targetFramework"`
+	Dependencies    []packageDependency `json:"dependencies"`
+}
+
+type packageDependency struct {
+	ID    string `json:"id"`
+	Range string `json:"range"`
+}
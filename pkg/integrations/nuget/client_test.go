@@ -0,0 +1,108 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+func TestClient_FetchPackage(t *testing.T) {
+	unlisted := false
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/serilog/index.json":
+			json.NewEncoder(w).Encode(registrationIndex{Items: []registrationPage{
+				{Items: []registrationLeaf{{CatalogEntry: catalogEntry{ID: "Serilog", Version: "3.1.1"}}}},
+				{ID: serverURL + "/serilog/page/4.0.0/4.1.0.json"},
+			}})
+		case "/serilog/page/4.0.0/4.1.0.json":
+			json.NewEncoder(w).Encode(registrationPage{Items: []registrationLeaf{
+				{CatalogEntry: catalogEntry{
+					ID:                "Serilog",
+					Version:           "4.0.0",
+					LicenseExpression: "Apache-2.0",
+					ProjectURL:        "https://serilog.net",
+					DependencyGroups: []dependencyGroup{
+						{TargetFramework: ".NETStandard2.0", Dependencies: []packageDependency{
+							{ID: "System.Diagnostics.DiagnosticSource", Range: "[8.0.1, )"},
+						}},
+						{TargetFramework: "net8.0"},
+					},
+				}},
+				{CatalogEntry: catalogEntry{ID: "Serilog", Version: "4.1.0-dev-02235"}},
+				{CatalogEntry: catalogEntry{ID: "Serilog", Version: "4.0.1", Listed: &unlisted}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+
+	info, err := c.FetchPackage(context.Background(), "Serilog", true)
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if info.Version != "4.0.0" {
+		t.Errorf("expected latest listed stable 4.0.0, got %s", info.Version)
+	}
+	if info.License != "Apache-2.0" {
+		t.Errorf("expected license Apache-2.0, got %s", info.License)
+	}
+	if len(info.DependencyGroups) != 2 {
+		t.Fatalf("expected 2 dependency groups, got %d", len(info.DependencyGroups))
+	}
+	if got := info.DependencyGroups[0].Dependencies[0].ID; got != "system.diagnostics.diagnosticsource" {
+		t.Errorf("expected normalized dependency id, got %s", got)
+	}
+
+	versions, err := c.FetchVersions(context.Background(), "Serilog", false)
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if want := []string{"3.1.1", "4.0.0", "4.1.0-dev-02235"}; !slices.Equal(versions, want) {
+		t.Errorf("versions = %v, want %v", versions, want)
+	}
+
+	old, err := c.FetchPackageVersion(context.Background(), "Serilog", "3.1.1", false)
+	if err != nil {
+		t.Fatalf("FetchPackageVersion failed: %v", err)
+	}
+	if old.Version != "3.1.1" {
+		t.Errorf("expected version 3.1.1, got %s", old.Version)
+	}
+	if _, err := c.FetchPackageVersion(context.Background(), "Serilog", "4.0.1", false); !errors.Is(err, integrations.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unlisted 4.0.1, got %v", err)
+	}
+}
+
+func TestClient_FetchPackage_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL = server.URL
+
+	_, err = c.FetchPackage(context.Background(), "missing", true)
+	if !errors.Is(err, integrations.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
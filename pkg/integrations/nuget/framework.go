@@ -0,0 +1,229 @@
+package nuget
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	FamilyNetCoreApp   = ".NETCoreApp"
+	FamilyNetStandard  = ".NETStandard"
+	FamilyNetFramework = ".NETFramework"
+)
+
+var (
+	longFormRE  = regexp.MustCompile(`^(\.[a-z]+)(?:,version=v)?([0-9.]*)$`)
+	shortFormRE = regexp.MustCompile(`^([a-z]+)([0-9.]*)$`)
+)
+
+var shortFamilies = map[string]string{
+	"netcoreapp":  FamilyNetCoreApp,
+	"netstandard": FamilyNetStandard,
+}
+
+var longFamilies = map[string]string{
+	".netcoreapp":   FamilyNetCoreApp,
+	".netstandard":  FamilyNetStandard,
+	".netframework": FamilyNetFramework,
+}
+
+// Framework is a parsed target framework moniker such as net8.0,
+// netstandard2.0, net472 or their long forms (.NETStandard2.0). The zero
+// value stands for a framework-agnostic dependency group.
+type Framework struct {
+	Family   string
+	Version  []int
+	Platform string
+}
+
+func (f Framework) IsAny() bool { return f.Family == "" }
+
+func (f Framework) String() string {
+	if f.IsAny() {
+		return "any"
+	}
+	parts := make([]string, len(f.Version))
+	for i, v := range f.Version {
+		parts[i] = strconv.Itoa(v)
+	}
+	s := f.Family + strings.Join(parts, ".")
+	if f.Platform != "" {
+		s += "-" + f.Platform
+	}
+	return s
+}
+
+func ParseFramework(s string) (Framework, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "any" || s == "agnostic" {
+		return Framework{}, nil
+	}
+
+	var platform string
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, platform = s[:i], strings.TrimRight(s[i+1:], "0123456789.")
+	}
+
+	if m := longFormRE.FindStringSubmatch(s); m != nil {
+		family, ok := longFamilies[m[1]]
+		if !ok {
+			family = m[1]
+		}
+		return Framework{Family: family, Version: parseVersion(m[2], false), Platform: platform}, nil
+	}
+
+	m := shortFormRE.FindStringSubmatch(s)
+	if m == nil {
+		return Framework{}, fmt.Errorf("invalid target framework %q", s)
+	}
+	if m[1] == "net" {
+		// net5.0 and later are .NET (Core); dotless monikers like net472
+		// are the classic .NET Framework.
+		if strings.Contains(m[2], ".") {
+			return Framework{Family: FamilyNetCoreApp, Version: parseVersion(m[2], false), Platform: platform}, nil
+		}
+		return Framework{Family: FamilyNetFramework, Version: parseVersion(m[2], true), Platform: platform}, nil
+	}
+	family, ok := shortFamilies[m[1]]
+	if !ok {
+		family = m[1]
+	}
+	return Framework{Family: family, Version: parseVersion(m[2], false), Platform: platform}, nil
+}
+
+func parseVersion(s string, digits bool) []int {
+	var parts []string
+	if digits && !strings.Contains(s, ".") {
+		parts = strings.Split(s, "")
+	} else {
+		parts = strings.Split(s, ".")
+	}
+
+	version := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		version = append(version, n)
+	}
+	for len(version) > 1 && version[len(version)-1] == 0 {
+		version = version[:len(version)-1]
+	}
+	return version
+}
+
+func compareVersions(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// netStandardSupport is the highest .NET Standard version each platform
+// implements, keyed by the minimum platform version.
+var netStandardSupport = map[string][]struct {
+	min      []int
+	standard []int
+}{
+	FamilyNetCoreApp: {
+		{[]int{3}, []int{2, 1}},
+		{[]int{2}, []int{2}},
+		{[]int{1}, []int{1, 6}},
+	},
+	FamilyNetFramework: {
+		{[]int{4, 6, 1}, []int{2}},
+		{[]int{4, 6}, []int{1, 3}},
+		{[]int{4, 5, 1}, []int{1, 2}},
+		{[]int{4, 5}, []int{1, 1}},
+	},
+}
+
+func maxNetStandard(target Framework) []int {
+	if target.Family == FamilyNetStandard {
+		return target.Version
+	}
+	for _, s := range netStandardSupport[target.Family] {
+		if compareVersions(target.Version, s.min) >= 0 {
+			return s.standard
+		}
+	}
+	return nil
+}
+
+const (
+	matchNone = iota
+	matchAny
+	matchNetStandard
+	matchFamily
+)
+
+// compatibility ranks how well a dependency group's framework fits the
+// target: the target's own family beats .NET Standard, which beats a
+// framework-agnostic group.
+func compatibility(target, group Framework) int {
+	if group.IsAny() {
+		return matchAny
+	}
+	if group.Platform != "" && group.Platform != target.Platform {
+		return matchNone
+	}
+	if group.Family == target.Family && compareVersions(group.Version, target.Version) <= 0 {
+		return matchFamily
+	}
+	if group.Family == FamilyNetStandard {
+		if limit := maxNetStandard(target); limit != nil && compareVersions(group.Version, limit) <= 0 {
+			return matchNetStandard
+		}
+	}
+	return matchNone
+}
+
+// NearestGroup picks the dependency group NuGet would use when restoring for
+// target: the closest compatible framework, preferring platform-specific
+// groups and higher versions within the best-matching family.
+func NearestGroup(groups []DependencyGroup, target Framework) (DependencyGroup, bool) {
+	type candidate struct {
+		group DependencyGroup
+		fw    Framework
+		rank  int
+	}
+
+	var candidates []candidate
+	for _, g := range groups {
+		fw, err := ParseFramework(g.TargetFramework)
+		if err != nil {
+			continue
+		}
+		if rank := compatibility(target, fw); rank != matchNone {
+			candidates = append(candidates, candidate{g, fw, rank})
+		}
+	}
+	if len(candidates) == 0 {
+		return DependencyGroup{}, false
+	}
+
+	best := slices.MaxFunc(candidates, func(a, b candidate) int {
+		if c := cmp.Compare(a.rank, b.rank); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.fw.Platform), len(b.fw.Platform)); c != 0 {
+			return c
+		}
+		return compareVersions(a.fw.Version, b.fw.Version)
+	})
+	return best.group, true
+}
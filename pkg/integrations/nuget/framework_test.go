@@ -0,0 +1,89 @@
+package nuget
+
+import "testing"
+
+func TestParseFramework(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"net8.0", ".NETCoreApp8"},
+		{"net8.0-windows7.0", ".NETCoreApp8-windows"},
+		{"netcoreapp3.1", ".NETCoreApp3.1"},
+		{".NETCoreApp3.1", ".NETCoreApp3.1"},
+		{".NETCoreApp,Version=v2.0", ".NETCoreApp2"},
+		{"netstandard2.0", ".NETStandard2"},
+		{".NETStandard1.3", ".NETStandard1.3"},
+		{"net472", ".NETFramework4.7.2"},
+		{".NETFramework4.6.1", ".NETFramework4.6.1"},
+		{"", "any"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			fw, err := ParseFramework(tt.input)
+			if err != nil {
+				t.Fatalf("ParseFramework: %v", err)
+			}
+			if got := fw.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearestGroup(t *testing.T) {
+	groups := []DependencyGroup{
+		{TargetFramework: ""},
+		{TargetFramework: ".NETFramework4.6.2"},
+		{TargetFramework: ".NETStandard1.3"},
+		{TargetFramework: ".NETStandard2.0"},
+		{TargetFramework: "net6.0"},
+		{TargetFramework: "net8.0"},
+		{TargetFramework: "net8.0-windows7.0"},
+	}
+
+	tests := []struct {
+		target string
+		want   string
+		wantOK bool
+	}{
+		{"net8.0", "net8.0", true},
+		{"net9.0", "net8.0", true},
+		{"net7.0", "net6.0", true},
+		{"net8.0-windows", "net8.0-windows7.0", true},
+		{"netcoreapp3.1", ".NETStandard2.0", true},
+		{"net48", ".NETFramework4.6.2", true},
+		{"net461", ".NETStandard2.0", true},
+		{"net46", ".NETStandard1.3", true},
+		{"netstandard1.6", ".NETStandard1.3", true},
+		{"net40", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			target, err := ParseFramework(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := NearestGroup(groups, target)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got.TargetFramework != tt.want {
+				t.Errorf("got %q, want %q", got.TargetFramework, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearestGroup_NoCompatibleGroup(t *testing.T) {
+	groups := []DependencyGroup{
+		{TargetFramework: "net8.0"},
+		{TargetFramework: "net8.0-android34.0"},
+	}
+	target, _ := ParseFramework("netstandard2.0")
+	if g, ok := NearestGroup(groups, target); ok {
+		t.Errorf("expected no compatible group, got %q", g.TargetFramework)
+	}
+}
@@ -0,0 +1,73 @@
+package osv
+
+import (
+	"context"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+// Client queries the osv.dev API, or another server at the osv endpoint,
+// for the advisories of one package version at a time.
+type Client struct {
+	integrations.BaseClient
+	baseURL string
+}
+
+func NewClient(cacheTTL time.Duration) (*Client, error) {
+	cache, err := integrations.NewCache(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	ep := integrations.EndpointFor("osv", "https://api.osv.dev/v1")
+	return &Client{
+		BaseClient: integrations.BaseClient{
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
+		},
+		baseURL: ep.URL,
+	}, nil
+}
+
+// Advisories returns the advisories affecting version of the named
+// package.
+func (c *Client) Advisories(ctx context.Context, ecosystem, name, version string, refresh bool) ([]*Advisory, error) {
+	cacheKey := "osv:" + ecosystem + ":" + name + "@" + version
+
+	var advisories []*Advisory
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		advisories = nil
+		q := queryRequest{Version: version}
+		q.Package.Ecosystem, q.Package.Name = ecosystem, name
+		for {
+			var data queryResponse
+			if err := c.DoPost(ctx, c.baseURL+"/query", nil, q, &data); err != nil {
+				return err
+			}
+			advisories = append(advisories, data.Vulns...)
+			if data.NextPageToken == "" {
+				return nil
+			}
+			q.PageToken = data.NextPageToken
+		}
+	}, &advisories)
+	if err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
+
+type queryRequest struct {
+	Version string `json:"version"`
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type queryResponse struct {
+	Vulns         []*Advisory `json:"vulns"`
+	NextPageToken string      `json:"next_page_token"`
+}
@@ -0,0 +1,55 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/httputil"
+)
+
+func TestClient_Advisories(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var q queryRequest
+		if r.Method != http.MethodPost || r.URL.Path != "/query" || json.NewDecoder(r.Body).Decode(&q) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if q.Package.Ecosystem != "PyPI" || q.Package.Name != "requests" || q.Version != "2.30.0" {
+			t.Errorf("got query %+v", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if q.PageToken == "" {
+			w.Write([]byte(`{"vulns": [` + requestsAdvisory + `], "next_page_token": "p2"}`))
+			return
+		}
+		w.Write([]byte(`{"vulns": [{"id": "PYSEC-2", "affected": []}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTP = server.Client()
+	c.Cache = httputil.NewMemoryCache(time.Hour)
+	c.baseURL = server.URL
+
+	for range 2 {
+		got, err := c.Advisories(context.Background(), "PyPI", "requests", "2.30.0", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != "PYSEC-1" || got[1].ID != "PYSEC-2" {
+			t.Errorf("got %v, want the advisories of both pages", got)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2 and the second call cached", requests)
+	}
+}
@@ -0,0 +1,95 @@
+package osv
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// cvss3Weights are the weights of the CVSS v3 base metrics' values. PR is
+// given for an unchanged scope; a changed scope raises L and H.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSS3Score computes the base score of a CVSS v3.0 or v3.1 vector such as
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H.
+func CVSS3Score(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("not a CVSS v3 vector: %q", vector)
+	}
+	w := make(map[string]float64, len(cvss3Weights))
+	var changed bool
+	var pr string
+	for _, p := range parts[1:] {
+		metric, value, _ := strings.Cut(p, ":")
+		switch metric {
+		case "S":
+			changed = value == "C"
+			continue
+		case "PR":
+			pr = value
+		}
+		if weight, ok := cvss3Weights[metric][value]; ok {
+			w[metric] = weight
+		}
+	}
+	if len(w) != len(cvss3Weights) {
+		return 0, fmt.Errorf("incomplete CVSS v3 vector: %q", vector)
+	}
+	if changed {
+		switch pr {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(min(impact+exploitability, 10)), nil
+}
+
+// roundUp rounds up to one decimal the way CVSS v3.1 specifies, immune to
+// floating point error.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// Rating returns the qualitative rating of a CVSS score: CRITICAL, HIGH,
+// MEDIUM, LOW, or "" for none.
+func Rating(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package osv
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Database is a set of advisories held in memory, indexed by package.
+type Database struct {
+	byPackage map[string][]*Advisory
+	count     int
+}
+
+// NewDatabase returns an empty database.
+func NewDatabase() *Database {
+	return &Database{byPackage: make(map[string][]*Advisory)}
+}
+
+// Load reads an OSV database export: a directory of advisories, a zip
+// archive of them such as https://osv-vulnerabilities.storage.googleapis.com/PyPI/all.zip,
+// or a single advisory file. A directory may hold archives too, one per
+// ecosystem. Files that are not JSON are skipped.
+func Load(path string) (*Database, error) {
+	db := NewDatabase()
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return db, db.loadFile(path)
+	}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		return db.loadFile(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *Database) loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return db.loadZip(path)
+	case ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return db.read(path, f)
+	}
+	return nil
+}
+
+func (db *Database) loadZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		err = db.read(path+"!"+f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *Database) read(name string, r io.Reader) error {
+	var a Advisory
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	db.Add(&a)
+	return nil
+}
+
+// Add indexes an advisory under each package it affects.
+func (db *Database) Add(a *Advisory) {
+	if a.ID == "" {
+		return
+	}
+	db.count++
+	for _, aff := range a.Affected {
+		key := packageKey(aff.Package.Ecosystem, aff.Package.Name)
+		if !slices.Contains(db.byPackage[key], a) {
+			db.byPackage[key] = append(db.byPackage[key], a)
+		}
+	}
+}
+
+// Len returns the number of advisories in the database.
+func (db *Database) Len() int { return db.count }
+
+// Advisories returns the advisories of the named package, whatever their
+// versions.
+func (db *Database) Advisories(_ context.Context, ecosystem, name, _ string, _ bool) ([]*Advisory, error) {
+	return db.byPackage[packageKey(ecosystem, name)], nil
+}
+
+func packageKey(ecosystem, name string) string {
+	return ecosystem + ":" + normalizeName(ecosystem, name)
+}
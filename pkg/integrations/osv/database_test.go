@@ -0,0 +1,108 @@
+package osv
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const requestsAdvisory = `{
+	"id": "PYSEC-1",
+	"affected": [{
+		"package": {"ecosystem": "PyPI", "name": "requests"},
+		"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.31.0"}]}]
+	}]
+}`
+
+const lodashAdvisory = `{
+	"id": "GHSA-2",
+	"affected": [{
+		"package": {"ecosystem": "npm", "name": "lodash"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+	}]
+}`
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_Directory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "PYSEC-1.json"), []byte(requestsAdvisory), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not an advisory"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "npm"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeZip(t, filepath.Join(dir, "npm", "all.zip"), map[string]string{"GHSA-2.json": lodashAdvisory})
+
+	db, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 2 {
+		t.Errorf("loaded %d advisories, want 2", db.Len())
+	}
+
+	ctx := context.Background()
+	for _, tt := range []struct{ ecosystem, name, id string }{
+		{"PyPI", "Requests", "PYSEC-1"},
+		{"npm", "lodash", "GHSA-2"},
+	} {
+		got, err := db.Advisories(ctx, tt.ecosystem, tt.name, "1.0.0", false)
+		if err != nil || len(got) != 1 || got[0].ID != tt.id {
+			t.Errorf("%s %s: got %v, %v; want %s", tt.ecosystem, tt.name, got, err, tt.id)
+		}
+	}
+	if got, _ := db.Advisories(ctx, "npm", "requests", "1.0.0", false); len(got) != 0 {
+		t.Errorf("got %v for a package of another ecosystem", got)
+	}
+}
+
+func TestLoad_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.zip")
+	writeZip(t, path, map[string]string{"PYSEC-1.json": requestsAdvisory})
+
+	db, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	advisories, _ := db.Advisories(context.Background(), "PyPI", "requests", "2.30.0", false)
+	if got := Vulns(advisories, "PyPI", "requests", "2.30.0"); len(got) != 1 || got[0].Fixed != "2.31.0" {
+		t.Errorf("got %+v, want PYSEC-1 fixed in 2.31.0", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for a malformed advisory")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+}
@@ -0,0 +1,261 @@
+// Package osv matches packages against advisories in the OSV format
+// (https://ossf.github.io/osv-schema/), read from a database export on
+// disk or queried from the osv.dev API.
+package osv
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+// Source finds the advisories that may affect a package. They are not
+// necessarily filtered by version; Vulns does that.
+type Source interface {
+	Advisories(ctx context.Context, ecosystem, name, version string, refresh bool) ([]*Advisory, error)
+}
+
+// Advisory is the part of an OSV record stacktower reads.
+type Advisory struct {
+	ID               string     `json:"id"`
+	Aliases          []string   `json:"aliases,omitempty"`
+	Summary          string     `json:"summary,omitempty"`
+	Withdrawn        string     `json:"withdrawn,omitempty"`
+	Severity         []Severity `json:"severity,omitempty"`
+	Affected         []Affected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity,omitempty"`
+	} `json:"database_specific"`
+}
+
+// Severity is a score of an advisory, such as a CVSS_V3 vector.
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Affected is a package an advisory applies to, and its affected versions:
+// those in any of the ranges and those listed.
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []Range  `json:"ranges,omitempty"`
+	Versions          []string `json:"versions,omitempty"`
+	EcosystemSpecific struct {
+		Severity string `json:"severity,omitempty"`
+	} `json:"ecosystem_specific"`
+}
+
+// Range is a list of events, each of which starts or ends a span of
+// affected versions. GIT ranges, which are commits, are not evaluated.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event is one of introduced, fixed, last_affected or limit.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+func (e Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+// Vuln is an advisory that affects a package version.
+type Vuln struct {
+	ID       string
+	Severity string // CRITICAL, HIGH, MEDIUM or LOW; empty if unknown
+	Fixed    string // the first version with the fix; empty if there is none
+
+	// Unverified is set when the advisory names the package but its
+	// ranges could not be evaluated for the version, which may be affected.
+	Unverified bool
+}
+
+// Vulns returns the advisories that affect version of the named package,
+// sorted by ID. Withdrawn advisories are left out. Advisories whose ranges
+// cannot be evaluated for version are returned as Unverified rather than
+// dropped.
+func Vulns(advisories []*Advisory, ecosystem, name, version string) []Vuln {
+	var vulns []Vuln
+	for _, a := range advisories {
+		if a.Withdrawn != "" || slices.ContainsFunc(vulns, func(v Vuln) bool { return v.ID == a.ID }) {
+			continue
+		}
+		for _, aff := range a.Affected {
+			if aff.Package.Ecosystem != ecosystem || normalizeName(ecosystem, aff.Package.Name) != normalizeName(ecosystem, name) {
+				continue
+			}
+			if fixed, ok, unverified := aff.affects(ecosystem, version); ok || unverified {
+				vulns = append(vulns, Vuln{ID: a.ID, Severity: a.severity(aff), Fixed: fixed, Unverified: !ok})
+				break
+			}
+		}
+	}
+	slices.SortFunc(vulns, func(a, b Vuln) int { return strings.Compare(a.ID, b.ID) })
+	return vulns
+}
+
+// affects reports whether v is an affected version, and the version that
+// fixes it if a range names one. A version that is neither listed nor in
+// a range is unverified if some range could not be evaluated.
+func (aff Affected) affects(ecosystem, v string) (fixed string, ok, unverified bool) {
+	listed := slices.Contains(aff.Versions, v)
+	for _, r := range aff.Ranges {
+		s := schemeFor(ecosystem)
+		switch r.Type {
+		case "SEMVER":
+			s = version.NPM
+		case "ECOSYSTEM":
+		default:
+			continue
+		}
+		f, in, err := r.affects(s, v)
+		if err != nil {
+			unverified = true
+			continue
+		}
+		if in {
+			return f, true, false
+		}
+	}
+	return "", listed, unverified && !listed
+}
+
+// affects evaluates the range for v: the events up to v, in version
+// order, decide whether it is affected, and the next fixed event is its
+// fix. It fails if the scheme cannot parse v or a version of the range.
+func (r Range) affects(s version.Scheme, raw string) (fixed string, ok bool, err error) {
+	v, err := s.ParseVersion(raw)
+	if err != nil {
+		return "", false, err
+	}
+	type event struct {
+		Event
+		v version.Version // nil for introduced "0", before every version
+	}
+	events := make([]event, 0, len(r.Events))
+	for _, e := range r.Events {
+		if e.Introduced == "0" {
+			events = append(events, event{Event: e})
+			continue
+		}
+		ev, err := s.ParseVersion(e.version())
+		if err != nil {
+			return "", false, err
+		}
+		events = append(events, event{Event: e, v: ev})
+	}
+	slices.SortStableFunc(events, func(a, b event) int {
+		switch {
+		case a.v == nil && b.v == nil:
+			return 0
+		case a.v == nil:
+			return -1
+		case b.v == nil:
+			return 1
+		}
+		return a.v.Compare(b.v)
+	})
+
+	affected := false
+	for _, e := range events {
+		c := -1
+		if e.v != nil {
+			c = e.v.Compare(v)
+		}
+		if c > 0 {
+			if affected && e.Fixed != "" {
+				return e.Fixed, true, nil
+			}
+			if e.Introduced != "" {
+				break
+			}
+			continue
+		}
+		switch {
+		case e.Introduced != "":
+			affected = true
+		case e.Fixed != "":
+			affected = false
+		case e.LastAffected != "" && c < 0:
+			affected = false
+		}
+	}
+	return "", affected, nil
+}
+
+// schemeFor returns how the versions of an ecosystem order. Ecosystems
+// without a scheme of their own, such as Go, are compared as semantic
+// versions.
+func schemeFor(ecosystem string) version.Scheme {
+	switch ecosystem {
+	case "PyPI":
+		return version.PEP440
+	case "RubyGems":
+		return version.RubyGems
+	case "crates.io":
+		return version.Cargo
+	case "Packagist":
+		return version.Composer
+	case "Maven":
+		return version.Maven
+	case "NuGet":
+		return version.NuGet
+	}
+	return version.NPM
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizeName returns the form in which names of the ecosystem compare:
+// PEP 503 names for PyPI, lower case where registries ignore case.
+func normalizeName(ecosystem, name string) string {
+	switch ecosystem {
+	case "PyPI":
+		return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	case "npm", "Packagist", "NuGet":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// severity returns the severity the database gave an advisory, or else
+// the rating of its CVSS v3 score.
+func (a *Advisory) severity(aff Affected) string {
+	s := strings.ToUpper(a.DatabaseSpecific.Severity)
+	if s == "" {
+		s = strings.ToUpper(aff.EcosystemSpecific.Severity)
+	}
+	switch s {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW":
+		return s
+	case "MODERATE":
+		return "MEDIUM"
+	}
+	for _, sev := range a.Severity {
+		if sev.Type == "CVSS_V3" {
+			if score, err := CVSS3Score(sev.Score); err == nil {
+				return Rating(score)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package osv
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func advisory(t *testing.T, data string) *Advisory {
+	t.Helper()
+	var a Advisory
+	if err := json.Unmarshal([]byte(data), &a); err != nil {
+		t.Fatal(err)
+	}
+	return &a
+}
+
+func TestVulns_Ranges(t *testing.T) {
+	a := advisory(t, `{
+		"id": "GHSA-1",
+		"affected": [{
+			"package": {"ecosystem": "PyPI", "name": "Django"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [
+				{"introduced": "4.0"}, {"fixed": "4.1.10"},
+				{"introduced": "0"}, {"fixed": "3.2.20"}
+			]}],
+			"versions": ["4.2rc1"]
+		}],
+		"database_specific": {"severity": "MODERATE"}
+	}`)
+
+	tests := []struct {
+		version string
+		want    []Vuln
+	}{
+		{"1.11", []Vuln{{ID: "GHSA-1", Severity: "MEDIUM", Fixed: "3.2.20"}}},
+		{"3.2.20", nil},
+		{"4.0", []Vuln{{ID: "GHSA-1", Severity: "MEDIUM", Fixed: "4.1.10"}}},
+		{"4.1.9", []Vuln{{ID: "GHSA-1", Severity: "MEDIUM", Fixed: "4.1.10"}}},
+		{"4.1.10", nil},
+		{"4.2rc1", []Vuln{{ID: "GHSA-1", Severity: "MEDIUM"}}},
+		{"not-a-version", []Vuln{{ID: "GHSA-1", Severity: "MEDIUM", Unverified: true}}},
+	}
+	for _, tt := range tests {
+		if got := Vulns([]*Advisory{a}, "PyPI", "django", tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestVulns_Maven(t *testing.T) {
+	// GHSA-36p3-wjmg-h94x (Spring4Shell), as osv.dev serves it.
+	a := advisory(t, `{
+		"id": "GHSA-36p3-wjmg-h94x",
+		"affected": [{
+			"package": {"ecosystem": "Maven", "name": "org.springframework:spring-beans"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "5.2.20"}]}]
+		}, {
+			"package": {"ecosystem": "Maven", "name": "org.springframework:spring-beans"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "5.3.0"}, {"fixed": "5.3.18"}]}]
+		}],
+		"database_specific": {"severity": "CRITICAL"}
+	}`)
+	// GHSA-7g45-4rm6-3mm3, which affects Guava before 32.0.0-android.
+	guava := advisory(t, `{
+		"id": "GHSA-7g45-4rm6-3mm3",
+		"affected": [{
+			"package": {"ecosystem": "Maven", "name": "com.google.guava:guava"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "1.0"}, {"fixed": "32.0.0-android"}]}]
+		}],
+		"database_specific": {"severity": "MODERATE"}
+	}`)
+
+	tests := []struct {
+		name, version string
+		want          []Vuln
+	}{
+		{"org.springframework:spring-beans", "5.2.0.RELEASE", []Vuln{{ID: "GHSA-36p3-wjmg-h94x", Severity: "CRITICAL", Fixed: "5.2.20"}}},
+		{"org.springframework:spring-beans", "5.2.20.RELEASE", nil},
+		{"org.springframework:spring-beans", "5.3.17", []Vuln{{ID: "GHSA-36p3-wjmg-h94x", Severity: "CRITICAL", Fixed: "5.3.18"}}},
+		{"org.springframework:spring-beans", "5.3.18", nil},
+		{"com.google.guava:guava", "31.1-jre", []Vuln{{ID: "GHSA-7g45-4rm6-3mm3", Severity: "MEDIUM", Fixed: "32.0.0-android"}}},
+		{"com.google.guava:guava", "32.0.1-jre", nil},
+	}
+	for _, tt := range tests {
+		if got := Vulns([]*Advisory{a, guava}, "Maven", tt.name, tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s@%s: got %+v, want %+v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestVulns_Unverified(t *testing.T) {
+	a := advisory(t, `{
+		"id": "GHSA-u",
+		"affected": [{
+			"package": {"ecosystem": "npm", "name": "left-pad"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "next-release"}]}],
+			"versions": ["1.0.0"]
+		}]
+	}`)
+
+	tests := []struct {
+		version string
+		want    []Vuln
+	}{
+		{"1.0.0", []Vuln{{ID: "GHSA-u"}}},
+		{"1.1.0", []Vuln{{ID: "GHSA-u", Unverified: true}}},
+	}
+	for _, tt := range tests {
+		if got := Vulns([]*Advisory{a}, "npm", "left-pad", tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestVulns_LastAffected(t *testing.T) {
+	a := advisory(t, `{
+		"id": "RUSTSEC-1",
+		"affected": [{
+			"package": {"ecosystem": "crates.io", "name": "smallvec"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}, {"last_affected": "1.6.0"}]}]
+		}]
+	}`)
+
+	for version, want := range map[string]bool{"0.9.0": false, "1.0.0": true, "1.6.0": true, "1.6.1": false} {
+		if got := len(Vulns([]*Advisory{a}, "crates.io", "smallvec", version)) > 0; got != want {
+			t.Errorf("%s: affected = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestVulns_Filters(t *testing.T) {
+	withdrawn := advisory(t, `{
+		"id": "GHSA-w",
+		"withdrawn": "2024-01-01T00:00:00Z",
+		"affected": [{"package": {"ecosystem": "npm", "name": "left-pad"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]
+	}`)
+	git := advisory(t, `{
+		"id": "GHSA-g",
+		"affected": [{"package": {"ecosystem": "npm", "name": "left-pad"}, "ranges": [{"type": "GIT", "events": [{"introduced": "0"}]}]}]
+	}`)
+	other := advisory(t, `{
+		"id": "GHSA-o",
+		"affected": [{"package": {"ecosystem": "npm", "name": "right-pad"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]
+	}`)
+
+	if got := Vulns([]*Advisory{withdrawn, git, other}, "npm", "left-pad", "1.0.0"); len(got) != 0 {
+		t.Errorf("got %+v, want none", got)
+	}
+}
+
+func TestAdvisory_Severity(t *testing.T) {
+	a := advisory(t, `{
+		"id": "CVE-1",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+		"affected": [{"package": {"ecosystem": "Go", "name": "example.com/mod"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}]}]}]
+	}`)
+
+	got := Vulns([]*Advisory{a}, "Go", "example.com/mod", "v1.1.0")
+	want := []Vuln{{ID: "CVE-1", Severity: "CRITICAL", Fixed: "1.2.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCVSS3Score(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	}
+	for _, tt := range tests {
+		got, err := CVSS3Score(tt.vector)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %v, %v; want %v", tt.vector, got, err, tt.want)
+		}
+	}
+
+	if _, err := CVSS3Score("CVSS:4.0/AV:N"); err == nil {
+		t.Error("expected an error for a CVSS v4 vector")
+	}
+}
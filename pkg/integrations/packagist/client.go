@@ -16,7 +16,7 @@ import (
 type PackageInfo struct {
 	Name         string
 	Version      string
-	Dependencies []string
+	Dependencies []integrations.Dependency
 	Repository   string
 	HomePage     string
 	Description  string
@@ -34,23 +34,34 @@ func NewClient(cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("packagist", "https://repo.packagist.org")
 
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		baseURL: "https://repo.packagist.org",
+		baseURL: ep.URL,
 	}, nil
 }
 
 func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*PackageInfo, error) {
+	return c.FetchPackageVersion(ctx, pkg, "", refresh)
+}
+
+// FetchPackageVersion fetches a specific tagged release; an empty version
+// means the latest stable one.
+func (c *Client) FetchPackageVersion(ctx context.Context, pkg, version string, refresh bool) (*PackageInfo, error) {
 	pkg = normalizeName(pkg)
-	cacheKey := "packagist:" + pkg
+	cacheKey := "packagist:package:" + pkg
+	if version != "" {
+		cacheKey += "@" + version
+	}
 
 	var info PackageInfo
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
-		return c.fetchPackage(ctx, pkg, &info)
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchPackage(ctx, pkg, version, &info)
 	}, &info)
 	if err != nil {
 		return nil, err
@@ -59,35 +70,76 @@ func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*P
 	return &info, nil
 }
 
-func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo) error {
+// FetchVersions lists the tagged releases of a package.
+func (c *Client) FetchVersions(ctx context.Context, pkg string, refresh bool) ([]string, error) {
+	pkg = normalizeName(pkg)
+	cacheKey := "packagist:versions:" + pkg
+
+	var versions []string
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		all, err := c.fetchVersions(ctx, pkg)
+		if err != nil {
+			return err
+		}
+		versions = versions[:0]
+		for _, v := range all {
+			versions = append(versions, v.Version)
+		}
+		return nil
+	}, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (c *Client) fetchVersions(ctx context.Context, pkg string) ([]p2Version, error) {
 	url := fmt.Sprintf("%s/p2/%s.json", c.baseURL, pkg)
 
-	var data p2Response
-	if err := c.DoRequest(ctx, url, nil, &data); err != nil {
+	body, err := c.DoRequestRaw(ctx, url, nil)
+	if err != nil {
 		if errors.Is(err, integrations.ErrNotFound) {
-			return fmt.Errorf("%w: packagist package %s", err, pkg)
+			return nil, fmt.Errorf("%w: packagist package %s", err, pkg)
 		}
-		return err
+		return nil, err
+	}
+	data, err := decodeP2(body)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
 	}
 
 	versions, ok := data.Packages[pkg]
 	if !ok || len(versions) == 0 {
-		return fmt.Errorf("no versions found for %s", pkg)
+		return nil, fmt.Errorf("no versions found for %s", pkg)
 	}
+	return versions, nil
+}
 
-	v := chooseLatestStable(versions)
-	deps := filterComposerDeps(v.Require)
+func (c *Client) fetchPackage(ctx context.Context, pkg, version string, info *PackageInfo) error {
+	versions, err := c.fetchVersions(ctx, pkg)
+	if err != nil {
+		return err
+	}
 
-	license := ""
-	if len(v.License) > 0 {
-		license = v.License[0]
+	v := chooseLatestStable(versions)
+	if version != "" {
+		i := slices.IndexFunc(versions, func(v p2Version) bool { return v.Version == version })
+		if i < 0 {
+			return fmt.Errorf("%w: packagist package %s version %s", integrations.ErrNotFound, pkg, version)
+		}
+		v = versions[i]
 	}
 
+	// Several licenses are alternatives to choose from.
+	license := strings.Join(v.License, " OR ")
+
 	author := ""
 	if len(v.Authors) > 0 {
 		author = strings.TrimSpace(v.Authors[0].Name)
 	}
 
+	dependencies := ExtractDeps(v.Require, v.RequireDev)
+
 	*info = PackageInfo{
 		Name:         v.Name,
 		Version:      v.Version,
@@ -96,13 +148,84 @@ func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo
 		Author:       author,
 		Repository:   normalizeRepoURL(v.Source.URL),
 		HomePage:     v.Homepage,
-		Dependencies: slices.Collect(maps.Keys(deps)),
+		Dependencies: dependencies,
 	}
 
 	return nil
 }
 
-func filterComposerDeps(require map[string]string) map[string]string {
+// decodeP2 decodes a p2 metadata document. Packagist serves these
+// minified: each version only lists the fields that changed since the
+// previous one, with "__unset" marking removed fields.
+func decodeP2(body []byte) (*p2Response, error) {
+	var raw struct {
+		Minified string                                  `json:"minified"`
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	data := &p2Response{Packages: make(map[string][]p2Version, len(raw.Packages))}
+	for name, versions := range raw.Packages {
+		if raw.Minified != "" {
+			versions = expandMinified(versions)
+		}
+		for _, fields := range versions {
+			b, err := json.Marshal(fields)
+			if err != nil {
+				return nil, err
+			}
+			var v p2Version
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+			data.Packages[name] = append(data.Packages[name], v)
+		}
+	}
+	return data, nil
+}
+
+func expandMinified(versions []map[string]json.RawMessage) []map[string]json.RawMessage {
+	expanded := make([]map[string]json.RawMessage, 0, len(versions))
+	prev := map[string]json.RawMessage{}
+	for _, fields := range versions {
+		cur := maps.Clone(prev)
+		for k, v := range fields {
+			if string(v) == `"__unset"` {
+				delete(cur, k)
+			} else {
+				cur[k] = v
+			}
+		}
+		expanded = append(expanded, cur)
+		prev = cur
+	}
+	return expanded
+}
+
+// ExtractDeps turns the require and require-dev maps of a package into a
+// list of dependencies sorted by name, the latter tagged as dev
+// dependencies. A package required both ways is a runtime dependency.
+func ExtractDeps(require, requireDev map[string]string) []integrations.Dependency {
+	deps := FilterComposerDeps(require)
+	dev := FilterComposerDeps(requireDev)
+
+	var out []integrations.Dependency
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		out = append(out, integrations.Dependency{Name: name, Constraint: deps[name], Kind: integrations.KindRuntime})
+	}
+	for _, name := range slices.Sorted(maps.Keys(dev)) {
+		if _, ok := deps[name]; !ok {
+			out = append(out, integrations.Dependency{Name: name, Constraint: dev[name], Kind: integrations.KindDev})
+		}
+	}
+	return out
+}
+
+// FilterComposerDeps drops platform requirements (php, extensions,
+// libraries, Composer APIs) from a require map and lowercases names.
+func FilterComposerDeps(require map[string]string) map[string]string {
 	if require == nil {
 		return map[string]string{}
 	}
@@ -169,6 +292,7 @@ type p2Version struct {
 	Homepage    string            `json:"homepage"`
 	License     []string          `json:"license"`
 	Require     map[string]string `json:"require"`
+	RequireDev  map[string]string `json:"require-dev"`
 	Support     map[string]string `json:"support"`
 	Source      struct {
 		URL string `json:"url"`
@@ -189,6 +313,7 @@ func (v *p2Version) UnmarshalJSON(b []byte) error {
 		Homepage    string            `json:"homepage"`
 		License     json.RawMessage   `json:"license"`
 		Require     json.RawMessage   `json:"require"`
+		RequireDev  json.RawMessage   `json:"require-dev"`
 		Support     map[string]string `json:"support"`
 		Source      struct {
 			URL string `json:"url"`
@@ -216,27 +341,13 @@ func (v *p2Version) UnmarshalJSON(b []byte) error {
 		}
 	}
 
-	require := map[string]string{}
-	if len(rv.Require) > 0 && string(rv.Require) != "null" {
-		if err := json.Unmarshal(rv.Require, &require); err != nil {
-			var anyObj map[string]any
-			if err := json.Unmarshal(rv.Require, &anyObj); err == nil {
-				require = make(map[string]string, len(anyObj))
-				for k, val := range anyObj {
-					if s, ok := val.(string); ok {
-						require[k] = s
-					}
-				}
-			}
-		}
-	}
-
 	v.Name = rv.Name
 	v.Version = rv.Version
 	v.Description = rv.Description
 	v.Homepage = rv.Homepage
 	v.License = license
-	v.Require = require
+	v.Require = decodeRequire(rv.Require)
+	v.RequireDev = decodeRequire(rv.RequireDev)
 	v.Support = rv.Support
 	v.Source = rv.Source
 	v.Dist = rv.Dist
@@ -244,3 +355,24 @@ func (v *p2Version) UnmarshalJSON(b []byte) error {
 
 	return nil
 }
+
+// decodeRequire decodes a require map, keeping only string constraints;
+// some old releases carry other values there.
+func decodeRequire(raw json.RawMessage) map[string]string {
+	require := map[string]string{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return require
+	}
+	if err := json.Unmarshal(raw, &require); err != nil {
+		var anyObj map[string]any
+		if err := json.Unmarshal(raw, &anyObj); err == nil {
+			require = make(map[string]string, len(anyObj))
+			for k, val := range anyObj {
+				if s, ok := val.(string); ok {
+					require[k] = s
+				}
+			}
+		}
+	}
+	return require
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
 )
 
 func TestNewClient(t *testing.T) {
@@ -88,7 +91,7 @@ func TestFetchPackage_Success(t *testing.T) {
 		t.Errorf("unexpected homepage: %s", info.HomePage)
 	}
 	// Only vendor/dep should survive filtering
-	if len(info.Dependencies) != 1 || info.Dependencies[0] != "vendor/dep" {
+	if len(info.Dependencies) != 1 || info.Dependencies[0].Name != "vendor/dep" {
 		t.Errorf("unexpected dependencies: %#v", info.Dependencies)
 	}
 }
@@ -141,7 +144,7 @@ func TestFilterComposerDeps(t *testing.T) {
 		"no/slash?":            "1.0", // still has slash, should be included once normalized by caller (function just checks contains "/")
 		"noslash":              "*",   // ignored
 	}
-	got := filterComposerDeps(in)
+	got := FilterComposerDeps(in)
 	// Expect entries with a slash and not platform/composer special ones
 	if _, ok := got["vendor/dep1"]; !ok {
 		t.Errorf("missing vendor/dep1 in %v", got)
@@ -166,6 +169,21 @@ func TestFilterComposerDeps(t *testing.T) {
 	}
 }
 
+func TestExtractDeps(t *testing.T) {
+	require := map[string]string{"php": ">=8.1", "psr/log": "^3.0", "symfony/console": "^7.0"}
+	requireDev := map[string]string{"phpunit/phpunit": "^10", "Psr/Log": "^3.0"}
+
+	got := ExtractDeps(require, requireDev)
+	want := []integrations.Dependency{
+		{Name: "psr/log", Constraint: "^3.0", Kind: integrations.KindRuntime},
+		{Name: "symfony/console", Constraint: "^7.0", Kind: integrations.KindRuntime},
+		{Name: "phpunit/phpunit", Constraint: "^10", Kind: integrations.KindDev},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractDeps = %v, want %v", got, want)
+	}
+}
+
 func TestChooseLatestStable(t *testing.T) {
 	versions := []p2Version{
 		{Version: "2-dev"},
@@ -208,3 +226,32 @@ func TestP2Version_UnmarshalJSON(t *testing.T) {
 		t.Errorf("unexpected require: %#v", v.Require)
 	}
 }
+
+func TestDecodeP2_Minified(t *testing.T) {
+	raw := `{
+        "minified": "composer/2.0",
+        "packages": {"vendor/pkg": [
+            {"name": "vendor/pkg", "version": "2.0.0", "require": {"vendor/dep": "^2.0"}, "homepage": "h"},
+            {"version": "1.1.0", "require": {"vendor/dep": "^1.0"}},
+            {"version": "1.0.0", "homepage": "__unset"}
+        ]}
+    }`
+	data, err := decodeP2([]byte(raw))
+	if err != nil {
+		t.Fatalf("decodeP2: %v", err)
+	}
+	versions := data.Packages["vendor/pkg"]
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(versions))
+	}
+	v := versions[2]
+	if v.Name != "vendor/pkg" || v.Version != "1.0.0" {
+		t.Errorf("inherited fields wrong: %#v", v)
+	}
+	if v.Require["vendor/dep"] != "^1.0" {
+		t.Errorf("require = %#v, want ^1.0 from previous version", v.Require)
+	}
+	if v.Homepage != "" {
+		t.Errorf("homepage = %q, want unset", v.Homepage)
+	}
+}
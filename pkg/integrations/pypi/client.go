@@ -5,22 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/matzehuels/stacktower/pkg/integrations"
 )
 
-var (
-	depRE    = regexp.MustCompile(`^([a-zA-Z0-9_-]+)`)
-	markerRE = regexp.MustCompile(`;\s*(.+)`)
-	skipRE   = regexp.MustCompile(`extra|dev|test`)
-)
+var depRE = regexp.MustCompile(`^([a-zA-Z0-9._-]+)`)
 
 type PackageInfo struct {
 	Name         string
 	Version      string
-	Dependencies []string
+	RequiresDist []string
 	ProjectURLs  map[string]string
 	HomePage     string
 	Summary      string
@@ -38,22 +35,35 @@ func NewClient(cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("pypi", "https://pypi.org/pypi")
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		baseURL: "https://pypi.org/pypi",
+		baseURL: ep.URL,
 	}, nil
 }
 
 func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*PackageInfo, error) {
-	pkg = normalizeName(pkg)
-	cacheKey := "pypi:" + pkg
+	return c.FetchPackageVersion(ctx, pkg, "", refresh)
+}
+
+// FetchPackageVersion fetches a specific release; an empty version means
+// the latest one.
+func (c *Client) FetchPackageVersion(ctx context.Context, pkg, version string, refresh bool) (*PackageInfo, error) {
+	pkg = NormalizeName(pkg)
+	cacheKey := "pypi:release:" + pkg
+	url := fmt.Sprintf("%s/%s/json", c.baseURL, pkg)
+	if version != "" {
+		cacheKey += "@" + version
+		url = fmt.Sprintf("%s/%s/%s/json", c.baseURL, pkg, version)
+	}
 
 	var info PackageInfo
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
-		return c.fetchPackage(ctx, pkg, &info)
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchPackage(ctx, url, pkg, &info)
 	}, &info)
 	if err != nil {
 		return nil, err
@@ -61,9 +71,42 @@ func (c *Client) FetchPackage(ctx context.Context, pkg string, refresh bool) (*P
 	return &info, nil
 }
 
-func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo) error {
-	url := fmt.Sprintf("%s/%s/json", c.baseURL, pkg)
+// FetchVersions lists the releases of a package that have at least one
+// file that was not yanked.
+func (c *Client) FetchVersions(ctx context.Context, pkg string, refresh bool) ([]string, error) {
+	pkg = NormalizeName(pkg)
+	cacheKey := "pypi:versions:" + pkg
+
+	var versions []string
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		var data apiResponse
+		if err := c.DoRequest(ctx, fmt.Sprintf("%s/%s/json", c.baseURL, pkg), nil, &data); err != nil {
+			if errors.Is(err, integrations.ErrNotFound) {
+				return fmt.Errorf("%w: pypi package %s", err, pkg)
+			}
+			return err
+		}
+		versions = installableReleases(data.Releases)
+		return nil
+	}, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func installableReleases(releases map[string][]releaseFile) []string {
+	var versions []string
+	for v, files := range releases {
+		if slices.ContainsFunc(files, func(f releaseFile) bool { return !f.Yanked }) {
+			versions = append(versions, v)
+		}
+	}
+	slices.Sort(versions)
+	return versions
+}
 
+func (c *Client) fetchPackage(ctx context.Context, url, pkg string, info *PackageInfo) error {
 	var data apiResponse
 	if err := c.DoRequest(ctx, url, nil, &data); err != nil {
 		if errors.Is(err, integrations.ErrNotFound) {
@@ -84,7 +127,7 @@ func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo
 		Version:      data.Info.Version,
 		Summary:      data.Info.Summary,
 		License:      data.Info.License,
-		Dependencies: extractDeps(data.Info.RequiresDist),
+		RequiresDist: data.Info.RequiresDist,
 		ProjectURLs:  urls,
 		HomePage:     data.Info.HomePage,
 		Author:       data.Info.Author,
@@ -92,31 +135,114 @@ func (c *Client) fetchPackage(ctx context.Context, pkg string, info *PackageInfo
 	return nil
 }
 
-func extractDeps(requiresDist []string) []string {
-	seen := make(map[string]bool)
-	var deps []string
+// ExtractDeps returns the PEP 508 requirements that apply in env as
+// dependencies with normalized names, version specifiers and requested
+// extras. A requirement gated on one of extras is included with Feature set
+// to that extra; one gated on any other extra is skipped. A marker that
+// cannot be evaluated keeps its requirement.
+func ExtractDeps(requiresDist []string, env Environment, extras ...string) []integrations.Dependency {
+	index := make(map[string]int)
+	var deps []integrations.Dependency
 
 	for _, req := range requiresDist {
-		if m := markerRE.FindStringSubmatch(req); len(m) > 1 && skipRE.MatchString(m[1]) {
+		spec, marker, hasMarker := strings.Cut(req, ";")
+		feature, ok := "", true
+		if hasMarker {
+			feature, ok = env.applies(marker, extras)
+		}
+		if !ok {
+			continue
+		}
+		dep, ok := parseRequirement(spec)
+		if !ok {
 			continue
 		}
-		if m := depRE.FindStringSubmatch(req); len(m) > 1 {
-			dep := normalizeName(m[1])
-			if !seen[dep] {
-				seen[dep] = true
-				deps = append(deps, dep)
+		dep.Feature = feature
+
+		i, seen := index[dep.Name]
+		if !seen {
+			index[dep.Name] = len(deps)
+			deps = append(deps, dep)
+			continue
+		}
+		// The same project listed again, typically with more extras under
+		// an extra of its own: merge, and let an unconditional requirement
+		// win over a feature-gated one.
+		prev := &deps[i]
+		for _, e := range dep.Extras {
+			if !slices.Contains(prev.Extras, e) {
+				prev.Extras = append(prev.Extras, e)
 			}
 		}
+		if dep.Feature == "" && prev.Feature != "" {
+			prev.Feature = ""
+			prev.Constraint = dep.Constraint
+		}
 	}
 	return deps
 }
 
-func normalizeName(name string) string {
-	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+// parseRequirement splits "name[extra1,extra2] (>=1.0, <2)" into the
+// normalized name, the extras and the specifier set ">=1.0,<2". Direct URL
+// references carry no constraint.
+func parseRequirement(spec string) (integrations.Dependency, bool) {
+	spec = strings.TrimSpace(spec)
+	m := depRE.FindStringSubmatch(spec)
+	if len(m) < 2 {
+		return integrations.Dependency{}, false
+	}
+
+	dep := integrations.Dependency{Name: NormalizeName(m[1])}
+	rest := strings.TrimSpace(spec[len(m[1]):])
+	if strings.HasPrefix(rest, "[") {
+		if i := strings.IndexByte(rest, ']'); i >= 0 {
+			dep.Extras = ParseExtras(rest[1:i])
+			rest = rest[i+1:]
+		}
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "@") {
+		rest = ""
+	}
+	dep.Constraint = strings.Join(strings.Fields(strings.Trim(rest, "()")), "")
+	return dep, true
+}
+
+// ParseExtras splits a comma-separated extras list into normalized names.
+func ParseExtras(s string) []string {
+	var extras []string
+	for _, e := range strings.Split(s, ",") {
+		if e = NormalizeName(strings.TrimSpace(e)); e != "" && !slices.Contains(extras, e) {
+			extras = append(extras, e)
+		}
+	}
+	return extras
+}
+
+// SplitExtras splits a requirement such as "fastapi[standard]" into the
+// project name and its extras.
+func SplitExtras(req string) (string, []string) {
+	name, rest, ok := strings.Cut(req, "[")
+	if !ok {
+		return req, nil
+	}
+	list, _, _ := strings.Cut(rest, "]")
+	return name, ParseExtras(list)
+}
+
+// NormalizeName returns the form of a project name used for lookups and
+// node ids: lower-case, with underscores replaced by dashes.
+func NormalizeName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
 }
 
 type apiResponse struct {
-	Info apiInfo `json:"info"`
+	Info     apiInfo                  `json:"info"`
+	Releases map[string][]releaseFile `json:"releases"`
+}
+
+type releaseFile struct {
+	Yanked bool `json:"yanked"`
 }
 
 type apiInfo struct {
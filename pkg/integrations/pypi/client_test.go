@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
@@ -50,8 +51,8 @@ func TestClient_FetchPackage(t *testing.T) {
 	if info.Version == "" {
 		t.Error("expected non-empty version")
 	}
-	if len(info.Dependencies) == 0 {
-		t.Error("expected at least one dependency")
+	if len(info.RequiresDist) != 2 {
+		t.Errorf("expected 2 requirements, got %v", info.RequiresDist)
 	}
 }
 
@@ -89,16 +90,60 @@ func TestExtractDeps_FiltersMarkers(t *testing.T) {
 			input:    []string{"flask"},
 			expected: 1,
 		},
+		{
+			input:    []string{"pywin32>=300; sys_platform == 'win32'", "uvloop; sys_platform != 'win32'"},
+			expected: 1,
+		},
+		{
+			input:    []string{"tomli>=1.1; python_version < '3.11'", "exceptiongroup; python_full_version < '3.11.0a7'"},
+			expected: 0,
+		},
 	}
 
 	for _, tt := range tests {
-		got := extractDeps(tt.input)
+		got := ExtractDeps(tt.input, DefaultEnvironment)
 		if len(got) != tt.expected {
-			t.Errorf("extractDeps(%v): expected %d deps, got %d", tt.input, tt.expected, len(got))
+			t.Errorf("ExtractDeps(%v): expected %d deps, got %d", tt.input, tt.expected, len(got))
 		}
 	}
 }
 
+func TestExtractDeps_Extras(t *testing.T) {
+	requires := []string{
+		"starlette>=0.37",
+		"pydantic>=2",
+		"uvicorn[standard]>=0.12; extra == 'standard'",
+		"pydantic[email]; extra == 'Standard'",
+		"orjson>=3.2; extra == 'all'",
+		"email-validator>=2; extra == 'standard' or extra == 'all'",
+	}
+
+	got := ExtractDeps(requires, DefaultEnvironment, "standard")
+	want := []integrations.Dependency{
+		{Name: "starlette", Constraint: ">=0.37"},
+		{Name: "pydantic", Constraint: ">=2", Extras: []string{"email"}},
+		{Name: "uvicorn", Constraint: ">=0.12", Extras: []string{"standard"}, Feature: "standard"},
+		{Name: "email-validator", Constraint: ">=2", Feature: "standard"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractDeps = %+v, want %+v", got, want)
+	}
+
+	if got := ExtractDeps(requires, DefaultEnvironment); len(got) != 2 {
+		t.Errorf("without extras got %+v, want starlette and pydantic", got)
+	}
+}
+
+func TestSplitExtras(t *testing.T) {
+	name, extras := SplitExtras("fastapi[standard, All]")
+	if name != "fastapi" || !reflect.DeepEqual(extras, []string{"standard", "all"}) {
+		t.Errorf("SplitExtras = %s %v", name, extras)
+	}
+	if name, extras := SplitExtras("flask"); name != "flask" || extras != nil {
+		t.Errorf("SplitExtras(flask) = %s %v", name, extras)
+	}
+}
+
 func TestNormalizeName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -112,7 +157,7 @@ func TestNormalizeName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := normalizeName(tt.input)
+			result := NormalizeName(tt.input)
 			if result != tt.expected {
 				t.Errorf("expected %s, got %s", tt.expected, result)
 			}
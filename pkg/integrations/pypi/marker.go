@@ -0,0 +1,368 @@
+package pypi
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+// Environment is the interpreter that PEP 508 environment markers are
+// evaluated against. Empty fields fall back to DefaultEnvironment.
+type Environment struct {
+	PythonVersion  string // "3.12" or a full "3.12.4"
+	Platform       string // linux, darwin or win32
+	Implementation string // cpython or pypy
+}
+
+var DefaultEnvironment = Environment{PythonVersion: "3.12", Platform: "linux", Implementation: "cpython"}
+
+type platformVars struct {
+	osName, sysPlatform, system, machine string
+}
+
+var platforms = map[string]platformVars{
+	"linux":  {"posix", "linux", "Linux", "x86_64"},
+	"darwin": {"posix", "darwin", "Darwin", "arm64"},
+	"win32":  {"nt", "win32", "Windows", "AMD64"},
+}
+
+var platformAliases = map[string]string{"macos": "darwin", "windows": "win32"}
+
+var implementations = map[string]string{"cpython": "CPython", "pypy": "PyPy"}
+
+// legacyVars maps the dotted marker names of PEP 345 that are still seen in
+// the wild to their PEP 508 spelling.
+var legacyVars = map[string]string{
+	"os.name":                        "os_name",
+	"sys.platform":                   "sys_platform",
+	"platform.version":               "platform_version",
+	"platform.machine":               "platform_machine",
+	"platform.python_implementation": "platform_python_implementation",
+	"python_implementation":          "platform_python_implementation",
+	"sys.implementation.name":        "implementation_name",
+	"platform.python_version":        "python_full_version",
+	"sys.implementation.version":     "implementation_version",
+}
+
+// Validate reports whether the environment names a platform and
+// implementation that markers can be evaluated for.
+func (e Environment) Validate() error {
+	_, err := e.vars()
+	return err
+}
+
+func (e Environment) vars() (map[string]string, error) {
+	pyVersion := cmp.Or(e.PythonVersion, DefaultEnvironment.PythonVersion)
+	if _, err := version.PEP440.ParseVersion(pyVersion); err != nil {
+		return nil, fmt.Errorf("python version: %w", err)
+	}
+	parts := strings.Split(pyVersion, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("python version %q: want major.minor", pyVersion)
+	}
+	full := pyVersion
+	if len(parts) == 2 {
+		full += ".0"
+	}
+
+	platform := strings.ToLower(cmp.Or(e.Platform, DefaultEnvironment.Platform))
+	platform = cmp.Or(platformAliases[platform], platform)
+	pv, ok := platforms[platform]
+	if !ok {
+		return nil, fmt.Errorf("unknown platform %q (want linux, darwin or win32)", platform)
+	}
+
+	impl := strings.ToLower(cmp.Or(e.Implementation, DefaultEnvironment.Implementation))
+	implName, ok := implementations[impl]
+	if !ok {
+		return nil, fmt.Errorf("unknown implementation %q (want cpython or pypy)", impl)
+	}
+
+	return map[string]string{
+		"python_version":                 parts[0] + "." + parts[1],
+		"python_full_version":            full,
+		"os_name":                        pv.osName,
+		"sys_platform":                   pv.sysPlatform,
+		"platform_system":                pv.system,
+		"platform_machine":               pv.machine,
+		"platform_release":               "",
+		"platform_version":               "",
+		"platform_python_implementation": implName,
+		"implementation_name":            impl,
+		"implementation_version":         full,
+		"extra":                          "",
+	}, nil
+}
+
+// Evaluate reports whether a PEP 508 marker such as
+// `python_version < "3.11" and sys_platform != "win32"` holds in e.
+func (e Environment) Evaluate(marker string) (bool, error) {
+	return e.evaluate(marker, "")
+}
+
+// applies reports whether a requirement with marker is installed when
+// extras are requested, and through which extra. Like pip, the marker is
+// tried without an extra first, then once per requested extra.
+func (e Environment) applies(marker string, extras []string) (string, bool) {
+	ok, err := e.evaluate(marker, "")
+	if err != nil || ok {
+		return "", true
+	}
+	for _, extra := range extras {
+		if ok, _ := e.evaluate(marker, extra); ok {
+			return extra, true
+		}
+	}
+	return "", false
+}
+
+func (e Environment) evaluate(marker, extra string) (bool, error) {
+	vars, err := e.vars()
+	if err != nil {
+		return false, err
+	}
+	vars["extra"] = extra
+	toks, err := lexMarker(marker)
+	if err != nil {
+		return false, err
+	}
+	p := &markerParser{toks: toks, vars: vars}
+	ok, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.toks) {
+		return false, fmt.Errorf("marker %q: unexpected %q", marker, p.toks[p.pos].text)
+	}
+	return ok, nil
+}
+
+type tokenKind int
+
+const (
+	tokName tokenKind = iota
+	tokString
+	tokOp
+	tokParen
+)
+
+type markerToken struct {
+	kind tokenKind
+	text string
+}
+
+var markerOps = []string{"===", "==", "!=", "<=", ">=", "~=", "<", ">"}
+
+func lexMarker(s string) ([]markerToken, error) {
+	var toks []markerToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, markerToken{tokParen, string(c)})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("marker %q: unterminated string", s)
+			}
+			toks = append(toks, markerToken{tokString, s[i+1 : i+1+end]})
+			i += end + 2
+		case isNameByte(c):
+			j := i
+			for j < len(s) && isNameByte(s[j]) {
+				j++
+			}
+			toks = append(toks, markerToken{tokName, s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range markerOps {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("marker %q: unexpected %q", s, c)
+			}
+			toks = append(toks, markerToken{tokOp, op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+type markerParser struct {
+	toks []markerToken
+	pos  int
+	vars map[string]string
+}
+
+func (p *markerParser) peek() (markerToken, bool) {
+	if p.pos >= len(p.toks) {
+		return markerToken{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *markerParser) keyword(word string) bool {
+	if t, ok := p.peek(); ok && t.kind == tokName && t.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *markerParser) or() (bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return false, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return false, err
+		}
+		left = left || right
+	}
+	return left, nil
+}
+
+func (p *markerParser) and() (bool, error) {
+	left, err := p.expr()
+	if err != nil {
+		return false, err
+	}
+	for p.keyword("and") {
+		right, err := p.expr()
+		if err != nil {
+			return false, err
+		}
+		left = left && right
+	}
+	return left, nil
+}
+
+func (p *markerParser) expr() (bool, error) {
+	if t, ok := p.peek(); ok && t.kind == tokParen && t.text == "(" {
+		p.pos++
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if t, ok := p.peek(); !ok || t.text != ")" {
+			return false, fmt.Errorf("marker: missing )")
+		}
+		p.pos++
+		return v, nil
+	}
+
+	lhs, lname, err := p.value()
+	if err != nil {
+		return false, err
+	}
+	op, err := p.op()
+	if err != nil {
+		return false, err
+	}
+	rhs, rname, err := p.value()
+	if err != nil {
+		return false, err
+	}
+	if lname == "extra" || rname == "extra" {
+		lhs, rhs = NormalizeName(lhs), NormalizeName(rhs)
+	}
+	return compareMarker(lhs, op, rhs, isVersionVar(lname) || isVersionVar(rname)), nil
+}
+
+// value returns the value of a quoted string or environment variable, and
+// the variable's name for the latter.
+func (p *markerParser) value() (string, string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return "", "", fmt.Errorf("marker: unexpected end")
+	}
+	p.pos++
+	switch t.kind {
+	case tokString:
+		return t.text, "", nil
+	case tokName:
+		name := cmp.Or(legacyVars[t.text], t.text)
+		v, ok := p.vars[name]
+		if !ok {
+			return "", "", fmt.Errorf("marker: unknown variable %q", t.text)
+		}
+		return v, name, nil
+	}
+	return "", "", fmt.Errorf("marker: unexpected %q", t.text)
+}
+
+func (p *markerParser) op() (string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("marker: missing operator")
+	}
+	p.pos++
+	switch {
+	case t.kind == tokOp:
+		return t.text, nil
+	case t.kind == tokName && t.text == "in":
+		return "in", nil
+	case t.kind == tokName && t.text == "not" && p.keyword("in"):
+		return "not in", nil
+	}
+	return "", fmt.Errorf("marker: unexpected %q", t.text)
+}
+
+func isVersionVar(name string) bool {
+	switch name {
+	case "python_version", "python_full_version", "implementation_version", "platform_release":
+		return true
+	}
+	return false
+}
+
+// compareMarker applies op the way packaging does: version variables are
+// compared as PEP 440 versions when both sides parse, everything else as
+// plain strings.
+func compareMarker(lhs, op, rhs string, versions bool) bool {
+	switch op {
+	case "in":
+		return strings.Contains(rhs, lhs)
+	case "not in":
+		return !strings.Contains(rhs, lhs)
+	}
+
+	if versions {
+		v, verr := version.PEP440.ParseVersion(lhs)
+		c, cerr := version.PEP440.ParseConstraint(op + rhs)
+		if verr == nil && cerr == nil {
+			return c.Check(v)
+		}
+	}
+
+	switch op {
+	case "==", "===":
+		return lhs == rhs
+	case "!=":
+		return lhs != rhs
+	case "<":
+		return lhs < rhs
+	case "<=":
+		return lhs <= rhs
+	case ">":
+		return lhs > rhs
+	case ">=":
+		return lhs >= rhs
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package pypi
+
+import "testing"
+
+func TestEnvironment_Evaluate(t *testing.T) {
+	tests := []struct {
+		marker string
+		env    Environment
+		want   bool
+	}{
+		{`python_version < "3.11"`, DefaultEnvironment, false},
+		{`python_version >= "3.8"`, DefaultEnvironment, true},
+		{`python_version < "3.11"`, Environment{PythonVersion: "3.9"}, true},
+		{`"3.10" < python_version`, DefaultEnvironment, true},
+		{`python_full_version >= "3.12.1"`, Environment{PythonVersion: "3.12.4"}, true},
+		{`python_version == "3.*"`, DefaultEnvironment, true},
+		{`python_version ~= "3.10"`, DefaultEnvironment, true},
+		{`sys_platform == "win32"`, DefaultEnvironment, false},
+		{`sys_platform == "win32"`, Environment{Platform: "windows"}, true},
+		{`os_name == "nt" or platform_system == "Darwin"`, Environment{Platform: "darwin"}, true},
+		{`platform_python_implementation == "CPython"`, DefaultEnvironment, true},
+		{`implementation_name == "pypy"`, Environment{Implementation: "pypy"}, true},
+		{`platform_machine in "x86_64 aarch64"`, DefaultEnvironment, true},
+		{`"arm" not in platform_machine`, DefaultEnvironment, true},
+		{`extra == "test"`, DefaultEnvironment, false},
+		{`sys.platform == 'linux'`, DefaultEnvironment, true},
+		{`(sys_platform == "linux" or sys_platform == "darwin") and python_version < "3.13"`, DefaultEnvironment, true},
+		{`python_version < "3.13" and (sys_platform == "win32" or extra == "all")`, DefaultEnvironment, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.marker, func(t *testing.T) {
+			got, err := tt.env.Evaluate(tt.marker)
+			if err != nil {
+				t.Fatalf("Evaluate error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironment_EvaluateErrors(t *testing.T) {
+	for _, marker := range []string{
+		`python_version <`,
+		`unknown_var == "x"`,
+		`(python_version > "3"`,
+		`python_version > "3" junk`,
+		`sys_platform == "linux`,
+	} {
+		if _, err := DefaultEnvironment.Evaluate(marker); err == nil {
+			t.Errorf("Evaluate(%q): expected error", marker)
+		}
+	}
+}
+
+func TestEnvironment_Validate(t *testing.T) {
+	if err := (Environment{}).Validate(); err != nil {
+		t.Errorf("zero environment: %v", err)
+	}
+	for _, env := range []Environment{
+		{PythonVersion: "3"},
+		{PythonVersion: "three"},
+		{Platform: "beos"},
+		{Implementation: "ironpython"},
+	} {
+		if err := env.Validate(); err == nil {
+			t.Errorf("Validate(%+v): expected error", env)
+		}
+	}
+}
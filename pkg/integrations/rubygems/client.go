@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 type GemInfo struct {
 	Name          string
 	Version       string
-	Dependencies  []string
+	Dependencies  []integrations.Dependency
 	SourceCodeURI string
 	HomepageURI   string
 	Description   string
@@ -32,22 +33,35 @@ func NewClient(cacheTTL time.Duration) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	ep := integrations.EndpointFor("rubygems", "https://rubygems.org/api/v1")
 	return &Client{
 		BaseClient: integrations.BaseClient{
-			HTTP:  integrations.NewHTTPClient(),
-			Cache: cache,
+			HTTP:    integrations.NewHTTPClient(),
+			Cache:   cache,
+			Headers: ep.Headers,
 		},
-		baseURL: "https://rubygems.org/api/v1",
+		baseURL: ep.URL,
 	}, nil
 }
 
 func (c *Client) FetchGem(ctx context.Context, gem string, refresh bool) (*GemInfo, error) {
+	return c.FetchGemVersion(ctx, gem, "", refresh)
+}
+
+// FetchGemVersion fetches a specific release; an empty version means the
+// latest one.
+func (c *Client) FetchGemVersion(ctx context.Context, gem, version string, refresh bool) (*GemInfo, error) {
 	gem = normalizeName(gem)
-	cacheKey := "rubygems:" + gem
+	cacheKey := "rubygems:gem:" + gem
+	url := fmt.Sprintf("%s/gems/%s.json", c.baseURL, gem)
+	if version != "" {
+		cacheKey += "@" + version
+		url = fmt.Sprintf("%s/v2/rubygems/%s/versions/%s.json", strings.TrimSuffix(c.baseURL, "/v1"), gem, version)
+	}
 
 	var info GemInfo
-	err := c.FetchWithCache(ctx, cacheKey, refresh, func() error {
-		return c.fetchGem(ctx, gem, &info)
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		return c.fetchGem(ctx, url, gem, &info)
 	}, &info)
 	if err != nil {
 		return nil, err
@@ -55,9 +69,36 @@ func (c *Client) FetchGem(ctx context.Context, gem string, refresh bool) (*GemIn
 	return &info, nil
 }
 
-func (c *Client) fetchGem(ctx context.Context, gem string, info *GemInfo) error {
-	url := fmt.Sprintf("%s/gems/%s.json", c.baseURL, gem)
+// FetchVersions lists the released versions of a gem. Platform-specific
+// builds share their version number and are listed once.
+func (c *Client) FetchVersions(ctx context.Context, gem string, refresh bool) ([]string, error) {
+	gem = normalizeName(gem)
+	cacheKey := "rubygems:versions:" + gem
+
+	var versions []string
+	err := c.FetchWithCache(ctx, cacheKey, refresh, func(ctx context.Context) error {
+		var data []versionResponse
+		if err := c.DoRequest(ctx, fmt.Sprintf("%s/versions/%s.json", c.baseURL, gem), nil, &data); err != nil {
+			if errors.Is(err, integrations.ErrNotFound) {
+				return fmt.Errorf("%w: rubygems gem %s", err, gem)
+			}
+			return err
+		}
+		versions = versions[:0]
+		for _, v := range data {
+			if !slices.Contains(versions, v.Number) {
+				versions = append(versions, v.Number)
+			}
+		}
+		return nil
+	}, &versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
 
+func (c *Client) fetchGem(ctx context.Context, url, gem string, info *GemInfo) error {
 	var data gemResponse
 	if err := c.DoRequest(ctx, url, nil, &data); err != nil {
 		if errors.Is(err, integrations.ErrNotFound) {
@@ -80,18 +121,22 @@ func (c *Client) fetchGem(ctx context.Context, gem string, info *GemInfo) error
 	return nil
 }
 
-func extractDeps(deps dependenciesResponse) []string {
+func extractDeps(deps dependenciesResponse) []integrations.Dependency {
 	seen := make(map[string]bool)
-	var result []string
-
-	// Only include runtime dependencies, skip development dependencies
-	for _, dep := range deps.Runtime {
-		name := normalizeName(dep.Name)
-		if !seen[name] {
-			seen[name] = true
-			result = append(result, name)
+	var result []integrations.Dependency
+
+	add := func(list []dependencyInfo, kind string) {
+		for _, dep := range list {
+			name := normalizeName(dep.Name)
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, integrations.Dependency{Name: name, Constraint: dep.Requirements, Kind: kind})
+			}
 		}
 	}
+	// A gem listed both ways is a runtime dependency.
+	add(deps.Runtime, integrations.KindRuntime)
+	add(deps.Development, integrations.KindDev)
 	return result
 }
 
@@ -123,6 +168,10 @@ type dependenciesResponse struct {
 	Runtime     []dependencyInfo `json:"runtime"`
 }
 
+type versionResponse struct {
+	Number string `json:"number"`
+}
+
 type dependencyInfo struct {
 	Name         string `json:"name"`
 	Requirements string `json:"requirements"`
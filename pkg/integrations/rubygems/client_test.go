@@ -56,8 +56,8 @@ func TestClient_FetchGem(t *testing.T) {
 	if info.Version != "7.1.0" {
 		t.Errorf("expected version 7.1.0, got %s", info.Version)
 	}
-	if len(info.Dependencies) != 2 {
-		t.Errorf("expected 2 runtime dependencies, got %d", len(info.Dependencies))
+	if len(info.Dependencies) != 3 {
+		t.Errorf("expected 3 dependencies, got %d", len(info.Dependencies))
 	}
 	if info.License != "MIT" {
 		t.Errorf("expected license MIT, got %s", info.License)
@@ -81,7 +81,7 @@ func TestClient_FetchGem_NotFound(t *testing.T) {
 	}
 }
 
-func TestExtractDeps_RuntimeOnly(t *testing.T) {
+func TestExtractDeps_Kinds(t *testing.T) {
 	deps := dependenciesResponse{
 		Runtime: []dependencyInfo{
 			{Name: "activesupport", Requirements: ">= 0"},
@@ -89,25 +89,24 @@ func TestExtractDeps_RuntimeOnly(t *testing.T) {
 		},
 		Development: []dependencyInfo{
 			{Name: "rake", Requirements: ">= 0"},
-			{Name: "rspec", Requirements: ">= 0"},
+			{Name: "ActiveSupport", Requirements: ">= 0"},
 		},
 	}
 
 	result := extractDeps(deps)
-	if len(result) != 2 {
-		t.Errorf("expected 2 runtime deps, got %d", len(result))
+	want := map[string]string{
+		"activesupport": integrations.KindRuntime,
+		"actionpack":    integrations.KindRuntime,
+		"rake":          integrations.KindDev,
+	}
+	if len(result) != len(want) {
+		t.Fatalf("expected %d deps, got %d", len(want), len(result))
 	}
-
-	// Verify only runtime deps are included
-	hasRake := false
 	for _, d := range result {
-		if d == "rake" || d == "rspec" {
-			hasRake = true
+		if d.Kind != want[d.Name] {
+			t.Errorf("%s: kind = %q, want %q", d.Name, d.Kind, want[d.Name])
 		}
 	}
-	if hasRake {
-		t.Error("expected development dependencies to be excluded")
-	}
 }
 
 func TestNormalizeName(t *testing.T) {
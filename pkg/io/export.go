@@ -1,21 +1,30 @@
 package io
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/matzehuels/stacktower/pkg/dag"
 )
 
+// FormatVersion is the version of the JSON format written by WriteJSON.
+// Version 1 files, which predate the field, had no edge or graph meta.
+const FormatVersion = 2
+
 var kindToString = map[dag.NodeKind]string{
 	dag.NodeKindSubdivider: "subdivider",
 	dag.NodeKindAuxiliary:  "auxiliary",
 }
 
 type graph struct {
-	Nodes []node `json:"nodes"`
-	Edges []edge `json:"edges"`
+	FormatVersion int          `json:"format_version,omitempty"`
+	Meta          dag.Metadata `json:"meta,omitempty"`
+	Nodes         []node       `json:"nodes"`
+	Edges         []edge       `json:"edges"`
 }
 
 type node struct {
@@ -26,17 +35,29 @@ type node struct {
 }
 
 type edge struct {
-	From string `json:"from"`
-	To   string `json:"to"`
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Meta dag.Metadata `json:"meta,omitempty"`
 }
 
+// WriteJSON writes g with nodes sorted by ID and edges by source and
+// target, so the same graph always serializes to the same bytes.
 func WriteJSON(g *dag.DAG, w io.Writer) error {
+	nodes := g.Nodes()
+	slices.SortFunc(nodes, func(a, b *dag.Node) int { return strings.Compare(a.ID, b.ID) })
+	edges := g.Edges()
+	slices.SortFunc(edges, func(a, b dag.Edge) int {
+		return cmp.Or(strings.Compare(a.From, b.From), strings.Compare(a.To, b.To))
+	})
+
 	out := graph{
-		Nodes: make([]node, len(g.Nodes())),
-		Edges: make([]edge, len(g.Edges())),
+		FormatVersion: FormatVersion,
+		Meta:          g.Meta(),
+		Nodes:         make([]node, len(nodes)),
+		Edges:         make([]edge, len(edges)),
 	}
 
-	for i, n := range g.Nodes() {
+	for i, n := range nodes {
 		nd := node{ID: n.ID, Meta: n.Meta}
 		if n.Row != 0 {
 			row := n.Row
@@ -47,8 +68,8 @@ func WriteJSON(g *dag.DAG, w io.Writer) error {
 		}
 		out.Nodes[i] = nd
 	}
-	for i, e := range g.Edges() {
-		out.Edges[i] = edge{From: e.From, To: e.To}
+	for i, e := range edges {
+		out.Edges[i] = edge{From: e.From, To: e.To, Meta: e.Meta}
 	}
 
 	enc := json.NewEncoder(w)
@@ -58,6 +58,56 @@ func TestWriteJSON(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "SortedOutput",
+			build: func() *dag.DAG {
+				g := dag.New(nil)
+				for _, id := range []string{"c", "a", "b"} {
+					g.AddNode(dag.Node{ID: id})
+				}
+				g.AddEdge(dag.Edge{From: "b", To: "c"})
+				g.AddEdge(dag.Edge{From: "a", To: "c"})
+				g.AddEdge(dag.Edge{From: "a", To: "b"})
+				return g
+			},
+			wantNodes: 3,
+			wantEdges: 3,
+			check: func(t *testing.T, g graph) {
+				for i, id := range []string{"a", "b", "c"} {
+					if g.Nodes[i].ID != id {
+						t.Errorf("nodes[%d] = %s, want %s", i, g.Nodes[i].ID, id)
+					}
+				}
+				for i, want := range []string{"a->b", "a->c", "b->c"} {
+					if got := g.Edges[i].From + "->" + g.Edges[i].To; got != want {
+						t.Errorf("edges[%d] = %s, want %s", i, got, want)
+					}
+				}
+			},
+		},
+		{
+			name: "EdgeAndGraphMeta",
+			build: func() *dag.DAG {
+				g := dag.New(dag.Metadata{"root": "a"})
+				g.AddNode(dag.Node{ID: "a"})
+				g.AddNode(dag.Node{ID: "b"})
+				g.AddEdge(dag.Edge{From: "a", To: "b", Meta: dag.Metadata{"constraint": "^1.0"}})
+				return g
+			},
+			wantNodes: 2,
+			wantEdges: 1,
+			check: func(t *testing.T, g graph) {
+				if g.FormatVersion != FormatVersion {
+					t.Errorf("format_version = %d, want %d", g.FormatVersion, FormatVersion)
+				}
+				if g.Meta["root"] != "a" {
+					t.Errorf("meta = %v", g.Meta)
+				}
+				if g.Edges[0].Meta["constraint"] != "^1.0" {
+					t.Errorf("edge meta = %v", g.Edges[0].Meta)
+				}
+			},
+		},
 		{
 			name: "Diamond",
 			build: func() *dag.DAG {
@@ -14,13 +14,19 @@ var kindFromString = map[string]dag.NodeKind{
 	"auxiliary":  dag.NodeKindAuxiliary,
 }
 
+// ReadJSON reads a graph written by WriteJSON or by hand. Files without a
+// format_version are read as version 1; newer versions than this build
+// knows are rejected rather than half understood.
 func ReadJSON(r io.Reader) (*dag.DAG, error) {
 	var data graph
 	if err := json.NewDecoder(r).Decode(&data); err != nil {
 		return nil, fmt.Errorf("decode: %w", err)
 	}
+	if data.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("unsupported format_version %d (this build reads up to %d)", data.FormatVersion, FormatVersion)
+	}
 
-	g := dag.New(nil)
+	g := dag.New(data.Meta)
 	for _, n := range data.Nodes {
 		nd := dag.Node{ID: n.ID, Meta: n.Meta}
 		if n.Row != nil {
@@ -34,7 +40,7 @@ func ReadJSON(r io.Reader) (*dag.DAG, error) {
 		}
 	}
 	for _, e := range data.Edges {
-		if err := g.AddEdge(dag.Edge{From: e.From, To: e.To}); err != nil {
+		if err := g.AddEdge(dag.Edge{From: e.From, To: e.To, Meta: e.Meta}); err != nil {
 			return nil, fmt.Errorf("edge %s->%s: %w", e.From, e.To, err)
 		}
 	}
@@ -55,6 +55,31 @@ func TestReadJSON(t *testing.T) {
 			input:   `{invalid json}`,
 			wantErr: true,
 		},
+		{
+			name: "Meta",
+			input: `{
+				"format_version": 2,
+				"meta": {"root": "A", "ecosystem": "python"},
+				"nodes": [{"id": "A"}, {"id": "B"}],
+				"edges": [{"from": "A", "to": "B", "meta": {"constraint": ">=1.0", "kind": "dev"}}]
+			}`,
+			wantNodes: 2,
+			wantEdges: 1,
+			check: func(t *testing.T, g *dag.DAG) {
+				if g.Meta()["root"] != "A" || g.Meta()["ecosystem"] != "python" {
+					t.Errorf("graph meta = %v", g.Meta())
+				}
+				e := g.Edges()[0]
+				if e.Meta["constraint"] != ">=1.0" || e.Meta["kind"] != "dev" {
+					t.Errorf("edge meta = %v", e.Meta)
+				}
+			},
+		},
+		{
+			name:    "FutureVersion",
+			input:   `{"format_version": 99, "nodes": [], "edges": []}`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
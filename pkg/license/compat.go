@@ -0,0 +1,79 @@
+package license
+
+import "slices"
+
+var (
+	gpl2Only  = []string{"GPL-2.0-only", "GPL-2.0-or-later"}
+	gpl3      = []string{"GPL-3.0-only", "GPL-3.0-or-later", "AGPL-3.0-only", "AGPL-3.0-or-later"}
+	gplFamily = append(slices.Clone(gpl2Only), gpl3...)
+)
+
+// combinable lists, for each strong and network copyleft license, the
+// project licenses a dependency under it can be part of. The combined
+// work must be distributed under the dependency's terms, so permissive
+// projects are never among them.
+var combinable = map[string][]string{
+	"GPL-1.0-only":      {"GPL-1.0-only", "GPL-1.0-or-later"},
+	"GPL-1.0-or-later":  append([]string{"GPL-1.0-only", "GPL-1.0-or-later"}, gplFamily...),
+	"GPL-2.0-only":      gpl2Only,
+	"GPL-2.0-or-later":  gplFamily,
+	"GPL-3.0-only":      gpl3,
+	"GPL-3.0-or-later":  gpl3,
+	"AGPL-3.0-only":     gpl3,
+	"AGPL-3.0-or-later": gpl3,
+	"EUPL-1.1":          {"EUPL-1.1", "EUPL-1.2"},
+	"EUPL-1.2":          append([]string{"EUPL-1.2", "MPL-2.0", "EPL-2.0", "LGPL-2.1-only", "LGPL-3.0-only", "OSL-3.0"}, gplFamily...),
+	"CC-BY-SA-3.0":      {"CC-BY-SA-3.0", "CC-BY-SA-4.0"},
+	"CC-BY-SA-4.0":      append([]string{"CC-BY-SA-4.0"}, gpl3...),
+	"OSL-3.0":           {"OSL-3.0"},
+	"SSPL-1.0":          {"SSPL-1.0"},
+}
+
+// incompatible lists the project licenses that permissive and weak
+// copyleft licenses conflict with despite being more liberal.
+var incompatible = map[string][]string{
+	"Apache-2.0":   {"GPL-1.0-only", "GPL-2.0-only", "LGPL-2.0-only", "LGPL-2.1-only"},
+	"BSD-4-Clause": gplFamily,
+	"CDDL-1.0":     gplFamily,
+	"CDDL-1.1":     gplFamily,
+	"CPL-1.0":      gplFamily,
+	"EPL-1.0":      gplFamily,
+	"MPL-1.1":      gplFamily,
+}
+
+// Compatible reports whether a dependency under dep can be part of a
+// project under project. Both are single licenses; proprietary and unknown
+// dependencies are left to the policy and count as compatible.
+func Compatible(project, dep *Expression) bool {
+	switch dep.Family() {
+	case StrongCopyleft, NetworkCopyleft:
+		return project.License == dep.License || slices.Contains(combinable[dep.License], project.License)
+	case Proprietary, Unknown:
+		return true
+	}
+	return !slices.Contains(incompatible[dep.License], project.License)
+}
+
+// CompatibleExpr reports whether some choice of licenses for the
+// dependency is compatible with some choice for the project.
+func CompatibleExpr(project, dep *Expression) bool {
+	for _, d := range dep.Choices() {
+		for _, p := range project.Choices() {
+			if allCompatible(p, d) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allCompatible(project, dep []*Expression) bool {
+	for _, p := range project {
+		for _, d := range dep {
+			if !Compatible(p, d) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package license
+
+import "testing"
+
+func TestCompatibleExpr(t *testing.T) {
+	tests := []struct {
+		project, dep string
+		want         bool
+	}{
+		{"MIT", "Apache-2.0", true},
+		{"MIT", "GPL-3.0-only", false},
+		{"GPL-3.0-only", "MIT", true},
+		{"GPL-3.0-only", "Apache-2.0", true},
+		{"GPL-2.0-only", "Apache-2.0", false},
+		{"GPL-2.0-only", "GPL-3.0-only", false},
+		{"GPL-3.0-only", "GPL-2.0-or-later", true},
+		{"MIT", "LGPL-2.1-only", true},
+		{"MIT", "MIT OR GPL-3.0-only", true},
+		{"Apache-2.0", "GPL-2.0-only WITH Classpath-exception-2.0", true},
+		{"MIT OR GPL-3.0-only", "AGPL-3.0-only", true},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.project)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := Parse(tt.dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := CompatibleExpr(p, d); got != tt.want {
+			t.Errorf("CompatibleExpr(%s, %s) = %v, want %v", tt.project, tt.dep, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package license
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Expression is a parsed SPDX license expression: a single license,
+// optionally WITH an exception, or licenses combined by AND or OR.
+type Expression struct {
+	Op        string        // "AND" or "OR"; empty for a single license
+	License   string        // SPDX identifier of a single license
+	Exception string        // exception of a single license, if any
+	Terms     []*Expression // operands of AND and OR
+}
+
+// Parse reads a license as registries give it: an SPDX expression, the
+// name, URL or text of a license, or licenses separated by "/", "," or
+// "or". Deprecated identifiers are replaced (GPL-2.0+ is
+// GPL-2.0-or-later). It fails for licenses it does not know.
+func Parse(s string) (*Expression, error) {
+	s = strings.TrimSpace(s)
+	if id, ok := lookup(s); ok {
+		return &Expression{License: id}, nil
+	}
+	if strings.Contains(s, "\n") {
+		return nil, fmt.Errorf("unknown license text")
+	}
+	// Upper case operators first, so "GPL v2 or later" is not split.
+	for _, loose := range []bool{false, true} {
+		p := &exprParser{tokens: tokenize(s, loose)}
+		e, err := p.or()
+		if err == nil && p.pos == len(p.tokens) {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown license %q", s)
+}
+
+// Normalize returns the SPDX expression of a license string, or "" if
+// Parse does not know it.
+func Normalize(s string) string {
+	e, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return e.String()
+}
+
+// String formats the expression in SPDX syntax.
+func (e *Expression) String() string {
+	if e.Op == "" {
+		if e.Exception != "" {
+			return e.License + " WITH " + e.Exception
+		}
+		return e.License
+	}
+	parts := make([]string, len(e.Terms))
+	for i, t := range e.Terms {
+		parts[i] = t.String()
+		if t.Op != "" {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+e.Op+" ")
+}
+
+// Licenses returns the single licenses of the expression.
+func (e *Expression) Licenses() []*Expression {
+	if e.Op == "" {
+		return []*Expression{e}
+	}
+	var out []*Expression
+	for _, t := range e.Terms {
+		out = append(out, t.Licenses()...)
+	}
+	return out
+}
+
+// Choices returns the ways of meeting the expression, each a set of
+// licenses that all apply: MIT OR (Apache-2.0 AND BSD-3-Clause) has the
+// choices [MIT] and [Apache-2.0, BSD-3-Clause].
+func (e *Expression) Choices() [][]*Expression {
+	switch e.Op {
+	case "OR":
+		var out [][]*Expression
+		for _, t := range e.Terms {
+			out = append(out, t.Choices()...)
+		}
+		return out
+	case "AND":
+		out := [][]*Expression{nil}
+		for _, t := range e.Terms {
+			var next [][]*Expression
+			for _, have := range out {
+				for _, c := range t.Choices() {
+					next = append(next, append(slices.Clip(have), c...))
+				}
+			}
+			out = next
+		}
+		return out
+	}
+	return [][]*Expression{{e}}
+}
+
+// Family returns the family of the expression: of its least restrictive
+// alternative for OR, of its most restrictive part for AND. A copyleft
+// license with a linking exception, such as GPL-2.0-only WITH
+// Classpath-exception-2.0, is weak copyleft.
+func (e *Expression) Family() string {
+	switch e.Op {
+	case "OR", "AND":
+		family := ""
+		for _, t := range e.Terms {
+			f := t.Family()
+			if family == "" || e.Op == "OR" && rank(f) < rank(family) || e.Op == "AND" && rank(f) > rank(family) {
+				family = f
+			}
+		}
+		return family
+	}
+	f, ok := families[e.License]
+	if !ok {
+		return Unknown
+	}
+	if exceptions[e.Exception] && (f == StrongCopyleft || f == NetworkCopyleft) {
+		return WeakCopyleft
+	}
+	return f
+}
+
+func rank(family string) int { return slices.Index(Families, family) }
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) or() (*Expression, error) {
+	return p.operation("OR", p.and)
+}
+
+func (p *exprParser) and() (*Expression, error) {
+	return p.operation("AND", p.atom)
+}
+
+// operation parses operands joined by op, flattening nested operations of
+// the same kind and dropping repeated operands.
+func (p *exprParser) operation(op string, operand func() (*Expression, error)) (*Expression, error) {
+	e := &Expression{Op: op}
+	for {
+		t, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		terms := []*Expression{t}
+		if t.Op == op {
+			terms = t.Terms
+		}
+		for _, t := range terms {
+			if !slices.ContainsFunc(e.Terms, func(have *Expression) bool { return have.String() == t.String() }) {
+				e.Terms = append(e.Terms, t)
+			}
+		}
+		if p.peek() != op {
+			break
+		}
+		p.pos++
+	}
+	if len(e.Terms) == 1 {
+		return e.Terms[0], nil
+	}
+	return e, nil
+}
+
+func (p *exprParser) atom() (*Expression, error) {
+	tok := p.peek()
+	switch tok {
+	case "(":
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return e, nil
+	case "", ")", "AND", "OR", "WITH":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	p.pos++
+	id, ok := lookup(tok)
+	if !ok {
+		return nil, fmt.Errorf("unknown license %q", tok)
+	}
+	e := &Expression{License: id}
+	if p.peek() == "WITH" {
+		p.pos++
+		exc := p.peek()
+		if exc == "" || exc == "(" || exc == ")" || exc == "AND" || exc == "OR" {
+			return nil, fmt.Errorf("missing exception")
+		}
+		p.pos++
+		e.Exception = exc
+		for known := range exceptions {
+			if strings.EqualFold(known, exc) {
+				e.Exception = known
+			}
+		}
+	}
+	return e, nil
+}
+
+// tokenize splits a license string into parentheses, operators and
+// license names, which may span several words. "/", "," and ";" separate
+// alternatives. Loose also takes lower case "and" and "or" as operators.
+func tokenize(s string, loose bool) []string {
+	var tokens, words []string
+	flush := func() {
+		if len(words) > 0 {
+			tokens = append(tokens, strings.Join(words, " "))
+			words = nil
+		}
+	}
+	operator := func(w string) string {
+		switch {
+		case w == "AND" || w == "OR" || w == "WITH":
+			return w
+		case loose && (w == "and" || w == "or"):
+			return strings.ToUpper(w)
+		case w == "&":
+			return "AND"
+		}
+		return ""
+	}
+
+	s = strings.NewReplacer("(", " ( ", ")", " ) ", "/", " OR ", ",", " OR ", ";", " OR ").Replace(s)
+	for _, w := range strings.Fields(s) {
+		if op := operator(w); op != "" {
+			flush()
+			tokens = append(tokens, op)
+			continue
+		}
+		if w == "(" || w == ")" {
+			flush()
+			tokens = append(tokens, w)
+			continue
+		}
+		words = append(words, w)
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,73 @@
+package license
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"MIT", "MIT"},
+		{"mit", "MIT"},
+		{"MIT License", "MIT"},
+		{"Apache License, Version 2.0", "Apache-2.0"},
+		{"Apache 2.0", "Apache-2.0"},
+		{"New BSD License", "BSD-3-Clause"},
+		{"BSD", ""},
+		{"BSD License", ""},
+		{"GPL-2.0+", "GPL-2.0-or-later"},
+		{"GPL-3.0", "GPL-3.0-only"},
+		{"GNU General Public License v3 (GPLv3)", "GPL-3.0-only"},
+		{"LGPLv2.1+", "LGPL-2.1-or-later"},
+		{"MIT/Apache-2.0", "MIT OR Apache-2.0"},
+		{"MIT or Apache-2.0", "MIT OR Apache-2.0"},
+		{"(MIT OR Apache-2.0) AND Unicode-DFS-2016", "(MIT OR Apache-2.0) AND Unicode-DFS-2016"},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{"https://licenses.nuget.org/MIT", "MIT"},
+		{"http://www.apache.org/licenses/LICENSE-2.0.txt", "Apache-2.0"},
+		{"Permission is hereby granted, free of charge, to any person obtaining a copy\nof this software", "MIT"},
+		{"UNLICENSED", RefProprietary},
+		{"NOASSERTION", ""},
+		{"", ""},
+		{"Some Custom License", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFamily(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"MIT", Permissive},
+		{"CC0-1.0", PublicDomain},
+		{"MPL-2.0", WeakCopyleft},
+		{"GPL-3.0-only", StrongCopyleft},
+		{"AGPL-3.0-only", NetworkCopyleft},
+		{"MIT OR GPL-3.0-only", Permissive},
+		{"MIT AND GPL-3.0-only", StrongCopyleft},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", WeakCopyleft},
+		{"UNLICENSED", Proprietary},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := e.Family(); got != tt.want {
+			t.Errorf("Family(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestChoices(t *testing.T) {
+	e, err := Parse("MIT OR (Apache-2.0 AND BSD-3-Clause)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	choices := e.Choices()
+	if len(choices) != 2 || len(choices[0]) != 1 || len(choices[1]) != 2 {
+		t.Fatalf("choices = %v", choices)
+	}
+	if choices[1][1].License != "BSD-3-Clause" {
+		t.Errorf("second choice = %v", choices[1])
+	}
+}
@@ -0,0 +1,288 @@
+package license
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+// Policy says which licenses a project's dependencies may have. Allow and
+// Deny hold SPDX identifiers and family names (strong-copyleft).
+type Policy struct {
+	// Project is the license the project is distributed under, which the
+	// licenses of its dependencies must be compatible with. Empty means
+	// the license of each root package.
+	Project string `yaml:"project"`
+	// Allow, if set, is every license dependencies may have.
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+	// Unknown is what to make of dependencies without a known license:
+	// "allow", "warn" (the default) or "deny".
+	Unknown string `yaml:"unknown"`
+	// Ignore are packages not checked, such as ones whose license was
+	// reviewed by hand.
+	Ignore []string `yaml:"ignore"`
+}
+
+// Kinds of findings.
+const (
+	KindDenied       = "denied"
+	KindIncompatible = "incompatible"
+	KindUnknown      = "unknown"
+)
+
+// Finding is a dependency whose license breaks the policy.
+type Finding struct {
+	Package string     `json:"package"`
+	Version string     `json:"version,omitempty"`
+	License string     `json:"license,omitempty"`
+	Kind    string     `json:"kind"`
+	Reason  string     `json:"reason"`
+	Warning bool       `json:"warning,omitempty"`
+	Paths   [][]string `json:"paths"`
+}
+
+// maxPaths caps the dependency paths listed for a finding.
+const maxPaths = 5
+
+// LoadPolicy reads a policy from a YAML file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// normalize checks the policy and turns its licenses into SPDX
+// identifiers.
+func (p *Policy) normalize() error {
+	p.Unknown = cmp.Or(p.Unknown, "warn")
+	if !slices.Contains([]string{"allow", "warn", "deny"}, p.Unknown) {
+		return fmt.Errorf("unknown: got %q, want allow, warn or deny", p.Unknown)
+	}
+	if p.Project != "" {
+		e, err := Parse(p.Project)
+		if err != nil {
+			return fmt.Errorf("project: %w", err)
+		}
+		p.Project = e.String()
+	}
+	for _, list := range []*[]string{&p.Allow, &p.Deny} {
+		for i, l := range *list {
+			if slices.Contains(Families, l) {
+				continue
+			}
+			e, err := Parse(l)
+			if err != nil || e.Op != "" {
+				return fmt.Errorf("%q is neither a license nor a family (%s)", l, strings.Join(Families, ", "))
+			}
+			(*list)[i] = e.String()
+		}
+	}
+	return nil
+}
+
+// permits reports whether the policy lets a dependency have a single
+// license, and if not, why.
+func (p *Policy) permits(l *Expression) (bool, string) {
+	family := l.Family()
+	if slices.Contains(p.Deny, l.License) || slices.Contains(p.Deny, family) {
+		return false, fmt.Sprintf("%s (%s) is denied", l, family)
+	}
+	if len(p.Allow) > 0 && !slices.Contains(p.Allow, l.License) && !slices.Contains(p.Allow, family) {
+		return false, fmt.Sprintf("%s (%s) is not allowed", l, family)
+	}
+	return true, ""
+}
+
+// projectLicenses returns the license each root is distributed under:
+// project, if set, or else the root's own.
+func projectLicenses(g *dag.DAG, roots []string, project string) (map[string]*Expression, error) {
+	licenses := make(map[string]*Expression, len(roots))
+	var unknown []string
+	for _, r := range roots {
+		l := project
+		if l == "" {
+			if n, ok := g.Node(r); ok {
+				l = NodeLicense(n)
+			}
+		}
+		e, err := Parse(l)
+		if err != nil {
+			unknown = append(unknown, r)
+			continue
+		}
+		licenses[r] = e
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("no known license for %s: set the project license in the policy", strings.Join(unknown, ", "))
+	}
+	return licenses, nil
+}
+
+// NodeLicense returns the license of a node: its "license" meta, or else
+// the "repo_license" of its repository.
+func NodeLicense(n *dag.Node) string {
+	if l, _ := n.Meta["license"].(string); l != "" {
+		return l
+	}
+	l, _ := n.Meta["repo_license"].(string)
+	return l
+}
+
+// Check returns the dependencies below roots whose licenses the policy
+// denies, that are incompatible with the project's license, or, unless
+// the policy allows it, that have no known license. Each finding lists the
+// shortest paths from a root that bring the dependency in, one through
+// each package depending on it. Without a project license in the policy,
+// every root must have a known license of its own.
+func Check(g *dag.DAG, roots []string, p Policy) ([]Finding, error) {
+	if err := p.normalize(); err != nil {
+		return nil, err
+	}
+	projects, err := projectLicenses(g, roots, p.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, n := range g.Nodes() {
+		if slices.Contains(roots, n.ID) || n.IsSynthetic() || slices.Contains(p.Ignore, n.ID) {
+			continue
+		}
+		raw := NodeLicense(n)
+		f := Finding{Package: n.ID, License: raw}
+		f.Version, _ = n.Meta["version"].(string)
+
+		var checkRoots []string
+		dep, err := Parse(raw)
+		switch {
+		case err != nil:
+			if p.Unknown == "allow" {
+				continue
+			}
+			f.Kind, f.Warning = KindUnknown, p.Unknown == "warn"
+			f.Reason = "no known license"
+			if raw != "" {
+				f.Reason = fmt.Sprintf("unknown license %q", raw)
+			}
+		default:
+			f.License = dep.String()
+			if reason := p.denied(dep); reason != "" {
+				f.Kind, f.Reason = KindDenied, reason
+				break
+			}
+			f.Kind = KindIncompatible
+			for _, r := range roots {
+				pl := projects[r]
+				if !CompatibleExpr(pl, dep) {
+					checkRoots = append(checkRoots, r)
+					f.Reason = fmt.Sprintf("%s is incompatible with the project's %s", dep, pl)
+				}
+			}
+			if len(checkRoots) == 0 {
+				continue
+			}
+		}
+
+		if checkRoots == nil {
+			checkRoots = roots
+		}
+		f.Paths = dependencyPaths(g, checkRoots, n.ID)
+		if len(f.Paths) == 0 {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	// Violations first, then warnings.
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		switch {
+		case a.Warning == b.Warning:
+			return 0
+		case b.Warning:
+			return -1
+		}
+		return 1
+	})
+	return findings, nil
+}
+
+// denied returns why no choice of licenses for dep is permitted, or "" if
+// one is.
+func (p *Policy) denied(dep *Expression) string {
+	reason := ""
+	for _, choice := range dep.Choices() {
+		ok := true
+		for _, l := range choice {
+			if permitted, why := p.permits(l); !permitted {
+				ok = false
+				reason = cmp.Or(reason, why)
+			}
+		}
+		if ok {
+			return ""
+		}
+	}
+	return reason
+}
+
+// dependencyPaths returns the shortest path from one of roots to id
+// through each of its parents, shortest first.
+func dependencyPaths(g *dag.DAG, roots []string, id string) [][]string {
+	prev := make(map[string]string)
+	seen := make(map[string]bool)
+	queue := slices.Clone(roots)
+	for _, r := range roots {
+		seen[r] = true
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, c := range g.Children(cur) {
+			if !seen[c] {
+				seen[c] = true
+				prev[c] = cur
+				queue = append(queue, c)
+			}
+		}
+	}
+	if !seen[id] {
+		return nil
+	}
+
+	var paths [][]string
+	for _, parent := range g.Parents(id) {
+		if !seen[parent] {
+			continue
+		}
+		path := []string{id}
+		for n := parent; ; n = prev[n] {
+			path = append(path, n)
+			if slices.Contains(roots, n) {
+				break
+			}
+		}
+		slices.Reverse(path)
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b []string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), slices.Compare(a, b))
+	})
+	return paths[:min(len(paths), maxPaths)]
+}
@@ -0,0 +1,127 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+func licenseGraph() *dag.DAG {
+	g := dag.New(nil)
+	for id, l := range map[string]string{
+		"app":     "MIT",
+		"web":     "BSD-3-Clause",
+		"cli":     "Apache-2.0",
+		"gplv3":   "GPL-3.0-only",
+		"either":  "MIT OR GPL-3.0-only",
+		"mystery": "",
+	} {
+		g.AddNode(dag.Node{ID: id, Meta: dag.Metadata{"license": l, "version": "1.0"}})
+	}
+	for _, e := range [][2]string{
+		{"app", "web"}, {"app", "cli"}, {"app", "either"},
+		{"web", "gplv3"}, {"cli", "gplv3"}, {"cli", "mystery"},
+	} {
+		g.AddEdge(dag.Edge{From: e[0], To: e[1]})
+	}
+	return g
+}
+
+func TestCheckIncompatible(t *testing.T) {
+	findings, err := Check(licenseGraph(), []string{"app"}, Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+
+	f := findings[0]
+	if f.Package != "gplv3" || f.Kind != KindIncompatible || f.Warning {
+		t.Errorf("first finding = %+v", f)
+	}
+	want := [][]string{{"app", "cli", "gplv3"}, {"app", "web", "gplv3"}}
+	if !slices.EqualFunc(f.Paths, want, slices.Equal) {
+		t.Errorf("paths = %v, want %v", f.Paths, want)
+	}
+
+	if u := findings[1]; u.Package != "mystery" || u.Kind != KindUnknown || !u.Warning {
+		t.Errorf("second finding = %+v", u)
+	}
+}
+
+func TestCheckDeny(t *testing.T) {
+	p := Policy{Project: "GPL-3.0-or-later", Deny: []string{StrongCopyleft, "Apache License 2.0"}, Unknown: "allow"}
+	findings, err := Check(licenseGraph(), []string{"app"}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var denied []string
+	for _, f := range findings {
+		if f.Kind != KindDenied {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		denied = append(denied, f.Package)
+	}
+	slices.Sort(denied)
+	// either can be taken under MIT.
+	if want := []string{"cli", "gplv3"}; !slices.Equal(denied, want) {
+		t.Errorf("denied = %v, want %v", denied, want)
+	}
+}
+
+func TestCheckIgnore(t *testing.T) {
+	p := Policy{Ignore: []string{"gplv3"}, Unknown: "allow"}
+	findings, err := Check(licenseGraph(), []string{"app"}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("got findings %+v", findings)
+	}
+}
+
+func TestCheckProjectLicense(t *testing.T) {
+	g := licenseGraph()
+	g.AddNode(dag.Node{ID: "monorepo"})
+	g.AddEdge(dag.Edge{From: "monorepo", To: "app"})
+
+	if _, err := Check(g, []string{"monorepo"}, Policy{}); err == nil {
+		t.Error("expected an error for a root without a license")
+	}
+	findings, err := Check(g, []string{"monorepo"}, Policy{Project: "MIT", Unknown: "allow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Package != "gplv3" {
+		t.Errorf("findings = %+v, want gplv3", findings)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	os.WriteFile(path, []byte("project: Apache 2.0\ndeny: [network-copyleft, GPLv2]\nunknown: deny\n"), 0o644)
+
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Project != "Apache-2.0" || p.Unknown != "deny" {
+		t.Errorf("policy = %+v", p)
+	}
+	if want := []string{NetworkCopyleft, "GPL-2.0-only"}; !slices.Equal(p.Deny, want) {
+		t.Errorf("deny = %v, want %v", p.Deny, want)
+	}
+
+	os.WriteFile(path, []byte("deny: [not-a-license]\n"), 0o644)
+	if _, err := LoadPolicy(path); err == nil {
+		t.Error("expected an error for an unknown license")
+	}
+	os.WriteFile(path, []byte("denied: [MIT]\n"), 0o644)
+	if _, err := LoadPolicy(path); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,283 @@
+// Package license turns the free-form license strings of package registries
+// into SPDX expressions (https://spdx.org/licenses/), sorts licenses into
+// families and checks a dependency graph against a license policy.
+package license
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Families of licenses, from the least to the most restrictive.
+const (
+	PublicDomain    = "public-domain"
+	Permissive      = "permissive"
+	WeakCopyleft    = "weak-copyleft"
+	StrongCopyleft  = "strong-copyleft"
+	NetworkCopyleft = "network-copyleft"
+	Proprietary     = "proprietary"
+	Unknown         = "unknown"
+)
+
+// Families lists the families from the least to the most restrictive.
+var Families = []string{PublicDomain, Permissive, WeakCopyleft, StrongCopyleft, NetworkCopyleft, Proprietary, Unknown}
+
+// Licenses outside the SPDX list that registries still name.
+const (
+	RefProprietary  = "LicenseRef-Proprietary"
+	RefPublicDomain = "LicenseRef-Public-Domain"
+)
+
+// families are the SPDX identifiers Parse knows, by family.
+var families = map[string]string{
+	"CC0-1.0": PublicDomain, "CC-PDDC": PublicDomain, "Unlicense": PublicDomain, RefPublicDomain: PublicDomain,
+
+	"0BSD": Permissive, "AFL-3.0": Permissive, "Apache-1.1": Permissive, "Apache-2.0": Permissive,
+	"Artistic-2.0": Permissive, "Beerware": Permissive, "BlueOak-1.0.0": Permissive, "BSD-1-Clause": Permissive,
+	"BSD-2-Clause": Permissive, "BSD-3-Clause": Permissive, "BSD-3-Clause-Clear": Permissive, "BSD-4-Clause": Permissive,
+	"BSL-1.0": Permissive, "CC-BY-3.0": Permissive, "CC-BY-4.0": Permissive, "curl": Permissive, "HPND": Permissive,
+	"ICU": Permissive, "ISC": Permissive, "MIT": Permissive, "MIT-0": Permissive, "MulanPSL-2.0": Permissive,
+	"NCSA": Permissive, "OpenSSL": Permissive, "PostgreSQL": Permissive, "PSF-2.0": Permissive, "Python-2.0": Permissive,
+	"Ruby": Permissive, "Unicode-3.0": Permissive, "Unicode-DFS-2016": Permissive, "UPL-1.0": Permissive,
+	"W3C": Permissive, "WTFPL": Permissive, "X11": Permissive, "Zlib": Permissive,
+
+	"CDDL-1.0": WeakCopyleft, "CDDL-1.1": WeakCopyleft, "CPL-1.0": WeakCopyleft, "EPL-1.0": WeakCopyleft,
+	"EPL-2.0": WeakCopyleft, "LGPL-2.0-only": WeakCopyleft, "LGPL-2.0-or-later": WeakCopyleft,
+	"LGPL-2.1-only": WeakCopyleft, "LGPL-2.1-or-later": WeakCopyleft, "LGPL-3.0-only": WeakCopyleft,
+	"LGPL-3.0-or-later": WeakCopyleft, "MPL-1.1": WeakCopyleft, "MPL-2.0": WeakCopyleft, "MS-PL": WeakCopyleft,
+	"MS-RL": WeakCopyleft, "OFL-1.1": WeakCopyleft,
+
+	"CC-BY-SA-3.0": StrongCopyleft, "CC-BY-SA-4.0": StrongCopyleft, "EUPL-1.1": StrongCopyleft, "EUPL-1.2": StrongCopyleft,
+	"GPL-1.0-only": StrongCopyleft, "GPL-1.0-or-later": StrongCopyleft, "GPL-2.0-only": StrongCopyleft,
+	"GPL-2.0-or-later": StrongCopyleft, "GPL-3.0-only": StrongCopyleft, "GPL-3.0-or-later": StrongCopyleft,
+
+	"AGPL-3.0-only": NetworkCopyleft, "AGPL-3.0-or-later": NetworkCopyleft, "OSL-3.0": NetworkCopyleft, "SSPL-1.0": NetworkCopyleft,
+
+	"BUSL-1.1": Proprietary, "CC-BY-NC-4.0": Proprietary, "Elastic-2.0": Proprietary, RefProprietary: Proprietary,
+}
+
+// exceptions are the SPDX license exceptions Parse knows. Those marked
+// true permit linking, making a copyleft license weak.
+var exceptions = map[string]bool{
+	"Autoconf-exception-3.0": true, "Bison-exception-2.2": true, "Classpath-exception-2.0": true,
+	"Font-exception-2.0": true, "GCC-exception-3.1": true, "LLVM-exception": true,
+	"OpenJDK-assembly-exception-1.0": true, "Qt-LGPL-exception-1.1": false, "WxWindows-exception-3.1": true,
+}
+
+// aliases are the names registries use for licenses, in the form key
+// reduces them to.
+var aliases = map[string]string{
+	"mit": "MIT", "expat": "MIT", "mit x11": "MIT", "x11": "X11",
+	"apache": "Apache-2.0", "apache 2": "Apache-2.0", "asl 2": "Apache-2.0", "apache software": "Apache-2.0",
+	"apache software 2": "Apache-2.0", "apache 1.1": "Apache-1.1",
+	"new bsd": "BSD-3-Clause", "modified bsd": "BSD-3-Clause", "revised bsd": "BSD-3-Clause",
+	"bsd 3": "BSD-3-Clause", "bsd 3 clause": "BSD-3-Clause", "3 clause bsd": "BSD-3-Clause", "bsd new": "BSD-3-Clause",
+	"simplified bsd": "BSD-2-Clause", "freebsd": "BSD-2-Clause", "bsd 2": "BSD-2-Clause", "bsd 2 clause": "BSD-2-Clause",
+	"2 clause bsd": "BSD-2-Clause", "bsd simplified": "BSD-2-Clause", "0bsd": "0BSD", "zero clause bsd": "0BSD",
+	"isc": "ISC", "iscl": "ISC", "zlib": "Zlib", "zlib libpng": "Zlib",
+	"unlicense": "Unlicense", "cc0": "CC0-1.0", "cc0 1": "CC0-1.0", "cc0 1 universal": "CC0-1.0",
+	"public domain": RefPublicDomain, "wtfpl": "WTFPL",
+	"boost": "BSL-1.0", "boost software": "BSL-1.0", "boost software 1": "BSL-1.0", "bsl 1": "BSL-1.0",
+	"psf": "PSF-2.0", "psf 2": "PSF-2.0", "psfl": "PSF-2.0", "python software foundation": "PSF-2.0",
+	"python": "Python-2.0", "ruby": "Ruby", "postgresql": "PostgreSQL", "artistic 2": "Artistic-2.0",
+	"mpl": "MPL-2.0", "mpl 2": "MPL-2.0", "mozilla public": "MPL-2.0", "mozilla public 2": "MPL-2.0",
+	"mpl 1.1": "MPL-1.1", "mozilla public 1.1": "MPL-1.1",
+	"epl": "EPL-1.0", "epl 1": "EPL-1.0", "eclipse public": "EPL-1.0", "eclipse public 1": "EPL-1.0",
+	"epl 2": "EPL-2.0", "eclipse public 2": "EPL-2.0",
+	"cddl": "CDDL-1.0", "cddl 1": "CDDL-1.0", "cddl 1.1": "CDDL-1.1",
+	"eupl 1.1": "EUPL-1.1", "eupl 1.2": "EUPL-1.2", "sspl": "SSPL-1.0", "sspl 1": "SSPL-1.0",
+	"unlicensed": RefProprietary, "proprietary": RefProprietary, "commercial": RefProprietary,
+	"all rights reserved": RefProprietary, "other proprietary": RefProprietary,
+}
+
+var (
+	// versionPrefix separates the names of versioned licenses from their
+	// version: GPLv3, apache2.
+	versionPrefix = regexp.MustCompile(`\b((?:a|l)?gpl|mpl|epl|apache|asl|cddl|eupl|bsd|psf|bsl)v?(\d)`)
+	versionToken  = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(\+?)$`)
+	gnuLicense    = regexp.MustCompile(`^(a|l)?gpl(?: (\d(?:\.\d)?)(\+)?)?(?: (or later|or any later|or newer|only))?$`)
+	parenthesized = regexp.MustCompile(`\s*\(([^()]*)\)`)
+)
+
+// gnuNames shortens the names of the GNU licenses. Longer names come first,
+// so they win over their suffixes.
+var gnuNames = strings.NewReplacer(
+	"affero general public", "agpl", "lesser general public", "lgpl", "library general public", "lgpl",
+	"general public", "gpl", "affero gpl", "agpl", "lesser gpl", "lgpl",
+)
+
+// noise are words key leaves out.
+var noise = map[string]bool{"the": true, "license": true, "version": true, "v": true}
+
+// key reduces a license name to lower case words, trailing .0s of
+// versions dropped: "The Apache License, Version 2.0" is "apache 2".
+func key(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "licence", "license")
+	s = versionPrefix.ReplaceAllString(s, "$1 $2")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '+')
+	})
+	out := words[:0]
+	for _, w := range words {
+		w = strings.Trim(w, ".")
+		if noise[w] || w == "" {
+			continue
+		}
+		if m := versionToken.FindStringSubmatch(w); m != nil {
+			v := m[1]
+			for strings.HasSuffix(v, ".0") {
+				v = strings.TrimSuffix(v, ".0")
+			}
+			w = v + m[2]
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " ")
+}
+
+// lookup finds the SPDX identifier of a single license: an identifier in
+// any case, a deprecated one, a name registries use, a license URL or the
+// text of a license.
+func lookup(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", false
+	}
+	if id, ok := canonicalIDs[strings.ToLower(s)]; ok {
+		return id, true
+	}
+	if strings.HasPrefix(s, "LicenseRef-") {
+		return s, true
+	}
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return lookupURL(u)
+	}
+	if strings.Contains(s, "\n") || len(s) > 200 {
+		return lookupText(s)
+	}
+	if id, ok := lookupName(s); ok {
+		return id, true
+	}
+	// "GNU General Public License v3 (GPLv3)": the name outside the
+	// parentheses, or else the one inside.
+	if m := parenthesized.FindStringSubmatch(s); m != nil {
+		if id, ok := lookupName(parenthesized.ReplaceAllString(s, "")); ok {
+			return id, true
+		}
+		return lookupName(m[1])
+	}
+	return "", false
+}
+
+var canonicalIDs = func() map[string]string {
+	ids := make(map[string]string, len(families))
+	for id := range families {
+		ids[strings.ToLower(id)] = id
+	}
+	return ids
+}()
+
+func lookupName(s string) (string, bool) {
+	k := key(s)
+	if id, ok := aliases[k]; ok {
+		return id, true
+	}
+	return gnuID(k)
+}
+
+// gnuID names the GNU license of a key such as "gnu lesser general public 2.1
+// or later". Without a version, the GPL is GPL-2.0-or-later, the LGPL
+// LGPL-2.1-or-later and the AGPL AGPL-3.0-or-later.
+func gnuID(k string) (string, bool) {
+	k = strings.TrimPrefix(strings.ReplaceAll(" "+k, " gnu ", " "), " ")
+	k = gnuNames.Replace(k)
+	m := gnuLicense.FindStringSubmatch(k)
+	if m == nil {
+		return "", false
+	}
+	name := strings.ToUpper(m[1]) + "GPL"
+	version, plus, suffix := m[2], m[3], m[4]
+	if version == "" {
+		version = map[string]string{"GPL": "2.0", "LGPL": "2.1", "AGPL": "3.0"}[name]
+		plus = "+"
+	}
+	if !strings.Contains(version, ".") {
+		version += ".0"
+	}
+	id := name + "-" + version + "-only"
+	if plus != "" || suffix != "" && suffix != "only" {
+		id = name + "-" + version + "-or-later"
+	}
+	_, ok := families[id]
+	return id, ok
+}
+
+// lookupURL recognizes links to a license: licenses.nuget.org, spdx.org
+// and opensource.org pages and the Apache and GNU license texts.
+func lookupURL(u *url.URL) (string, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	p := strings.TrimSuffix(u.Path, "/")
+	switch {
+	case host == "licenses.nuget.org":
+		if id, err := url.PathUnescape(strings.TrimPrefix(p, "/")); err == nil {
+			return lookup(id)
+		}
+	case host == "apache.org" && strings.HasPrefix(p, "/licenses/"):
+		return "Apache-2.0", strings.Contains(p, "2.0")
+	}
+	name := path.Base(p)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return "", false
+	}
+	if id, ok := canonicalIDs[strings.ToLower(name)]; ok {
+		return id, true
+	}
+	return lookupName(strings.ReplaceAll(name, "-", " "))
+}
+
+// lookupText recognizes the full text of common licenses, which some
+// packages put where their license's name belongs.
+func lookupText(s string) (string, bool) {
+	first, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if id, ok := lookupName(first); ok && len(first) < 80 {
+		return id, true
+	}
+	has := func(phrase string) bool { return strings.Contains(strings.ToLower(s), strings.ToLower(phrase)) }
+	switch {
+	case has("Permission is hereby granted, free of charge"):
+		return "MIT", true
+	case has("Apache License") && has("Version 2.0"):
+		return "Apache-2.0", true
+	case has("Redistribution and use in source and binary forms"):
+		if has("endorse or promote") {
+			return "BSD-3-Clause", true
+		}
+		return "BSD-2-Clause", true
+	case has("Permission to use, copy, modify, and/or distribute this software for any purpose"):
+		return "ISC", true
+	case has("free and unencumbered software released into the public domain"):
+		return "Unlicense", true
+	case has("Mozilla Public License") && has("2.0"):
+		return "MPL-2.0", true
+	case has("GNU"):
+		name := "gpl"
+		switch {
+		case has("Affero General Public License"):
+			name = "agpl"
+		case has("Lesser General Public License"), has("Library General Public License"):
+			name = "lgpl"
+		}
+		for _, v := range []string{"3", "2.1", "2"} {
+			if has("version " + v) {
+				if has("any later version") {
+					return gnuID(name + " " + v + "+")
+				}
+				return gnuID(name + " " + v)
+			}
+		}
+	}
+	return "", false
+}
@@ -37,16 +37,33 @@ func ToDOT(g *dag.DAG, opts Options) string {
 
 	buf.WriteString("\n")
 	for _, e := range g.Edges() {
-		fmt.Fprintf(&buf, "  %q -> %q;\n", e.From, e.To)
+		kind, _ := e.Meta["kind"].(string)
+		if attrs, ok := edgeAttrs[kind]; ok {
+			fmt.Fprintf(&buf, "  %q -> %q [%s];\n", e.From, e.To, attrs)
+		} else {
+			fmt.Fprintf(&buf, "  %q -> %q;\n", e.From, e.To)
+		}
 	}
 
 	buf.WriteString("}\n")
 	return buf.String()
 }
 
+// edgeAttrs styles the edges of dependency kinds other than runtime.
+var edgeAttrs = map[string]string{
+	"optional": "style=dotted",
+	"peer":     "style=dashed",
+	"dev":      "style=dashed, color=grey60",
+	"build":    "style=dotted, color=grey60",
+}
+
 func fmtLabel(n dag.Node, detailed bool) string {
+	name := n.ID
+	if label, ok := n.Meta["label"].(string); ok && label != "" {
+		name = label
+	}
 	if !detailed {
-		return n.ID
+		return name
 	}
 
 	parts := []string{fmt.Sprintf("row: %d", n.Row)}
@@ -54,13 +71,20 @@ func fmtLabel(n dag.Node, detailed bool) string {
 		parts = append(parts, fmt.Sprintf("%s: %v", k, n.Meta[k]))
 	}
 
-	return n.ID + "\n" + strings.Join(parts, "\n")
+	return name + "\n" + strings.Join(parts, "\n")
 }
 
 func fmtAttrs(n dag.Node, label string) []string {
 	attrs := []string{fmt.Sprintf("label=%q", label)}
-	if n.IsSubdivider() {
+	switch {
+	case n.IsSubdivider():
 		attrs = append(attrs, "style=\"rounded,filled,dashed\"", "fillcolor=lightgrey", "fontcolor=black")
+	case n.Meta["fetch_error"] != nil:
+		class, _ := n.Meta["fetch_error_class"].(string)
+		attrs = append(attrs, "style=\"rounded,filled,dashed\"", "color=red3", "fontcolor=red3",
+			fmt.Sprintf("tooltip=%q", fmt.Sprintf("fetch failed (%s): %v", class, n.Meta["fetch_error"])))
+	case n.Meta["truncated"] == true:
+		attrs = append(attrs, "style=\"rounded,filled,dashed\"", `tooltip="dependencies not crawled"`)
 	}
 	return attrs
 }
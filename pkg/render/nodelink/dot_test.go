@@ -32,6 +32,44 @@ func TestToDOT(t *testing.T) {
 			opts:     Options{},
 			contains: []string{`"a"`, `"b"`, `"a" -> "b"`},
 		},
+		{
+			name: "EdgeKinds",
+			setup: func() *dag.DAG {
+				g := dag.New(nil)
+				_ = g.AddNode(dag.Node{ID: "a"})
+				_ = g.AddNode(dag.Node{ID: "b"})
+				_ = g.AddNode(dag.Node{ID: "c"})
+				_ = g.AddEdge(dag.Edge{From: "a", To: "b", Meta: dag.Metadata{"kind": "runtime"}})
+				_ = g.AddEdge(dag.Edge{From: "a", To: "c", Meta: dag.Metadata{"kind": "dev"}})
+				return g
+			},
+			opts:     Options{},
+			contains: []string{`"a" -> "b";`, `"a" -> "c" [style=dashed, color=grey60];`},
+		},
+		{
+			name: "IncompleteNodes",
+			setup: func() *dag.DAG {
+				g := dag.New(nil)
+				_ = g.AddNode(dag.Node{ID: "a", Meta: dag.Metadata{"fetch_error": "timeout", "fetch_error_class": "timeout"}})
+				_ = g.AddNode(dag.Node{ID: "b", Meta: dag.Metadata{"truncated": true}})
+				return g
+			},
+			opts: Options{},
+			contains: []string{
+				`"a" [label="a", style="rounded,filled,dashed", color=red3, fontcolor=red3, tooltip="fetch failed (timeout): timeout"]`,
+				`"b" [label="b", style="rounded,filled,dashed", tooltip="dependencies not crawled"]`,
+			},
+		},
+		{
+			name: "Label",
+			setup: func() *dag.DAG {
+				g := dag.New(nil)
+				_ = g.AddNode(dag.Node{ID: "pkg:npm/react@18.3.1", Meta: dag.Metadata{"label": "react"}})
+				return g
+			},
+			opts:     Options{},
+			contains: []string{`"pkg:npm/react@18.3.1" [label="react"]`},
+		},
 		{
 			name: "Detailed",
 			setup: func() *dag.DAG {
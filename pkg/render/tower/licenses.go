@@ -0,0 +1,49 @@
+package tower
+
+import (
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/license"
+	"github.com/matzehuels/stacktower/pkg/render/tower/styles"
+)
+
+// LicenseColors are the block fills of the license families, from green
+// for the least restrictive to red for network copyleft.
+var LicenseColors = map[string]string{
+	license.PublicDomain:    "#e8f8f5",
+	license.Permissive:      "#d4efdf",
+	license.WeakCopyleft:    "#fcf3cf",
+	license.StrongCopyleft:  "#fad7a0",
+	license.NetworkCopyleft: "#f5b7b1",
+	license.Proprietary:     "#d7bde2",
+	license.Unknown:         "#e5e7e9",
+}
+
+// WithLicenseColors fills each block with the color of its package's
+// license family.
+func WithLicenseColors() RenderOption { return func(r *renderer) { r.licenseColors = true } }
+
+// colorByLicense sets the license family and fill of blocks from the
+// license of their packages; subdividers take their master's. Other
+// synthetic blocks keep the style's fill.
+func colorByLicense(blocks []styles.Block, g *dag.DAG) {
+	if g == nil {
+		return
+	}
+	for i, b := range blocks {
+		n, ok := g.Node(b.ID)
+		if !ok || n.IsAuxiliary() {
+			continue
+		}
+		if n.MasterID != "" {
+			if n, ok = g.Node(n.MasterID); !ok {
+				continue
+			}
+		}
+		family := license.Unknown
+		if e, err := license.Parse(license.NodeLicense(n)); err == nil {
+			family = e.Family()
+		}
+		blocks[i].LicenseFamily = family
+		blocks[i].Fill = LicenseColors[family]
+	}
+}
@@ -20,6 +20,8 @@ type renderer struct {
 	merged    bool
 	nebraska  []NebraskaRanking
 	popups    bool
+
+	licenseColors bool
 }
 
 func WithGraph(g *dag.DAG) RenderOption     { return func(r *renderer) { r.graph = g } }
@@ -51,6 +53,9 @@ func RenderSVG(layout Layout, opts ...RenderOption) []byte {
 	}
 
 	blocks := buildBlocks(layout, r.graph, r.popups)
+	if r.licenseColors {
+		colorByLicense(blocks, r.graph)
+	}
 	slices.SortFunc(blocks, func(a, b styles.Block) int {
 		return cmp.Compare(a.ID, b.ID)
 	})
@@ -275,7 +280,12 @@ func buildBlocks(l Layout, g *dag.DAG, withPopups bool) []styles.Block {
 		if g != nil {
 			if n, ok := g.Node(id); ok && n.Meta != nil {
 				blk.URL, _ = n.Meta["repo_url"].(string)
+				blk.Label, _ = n.Meta["label"].(string)
 				blk.Brittle = IsBrittle(n)
+				blk.FetchError, _ = n.Meta["fetch_error"].(string)
+				blk.ErrorClass, _ = n.Meta["fetch_error_class"].(string)
+				blk.Truncated, _ = n.Meta["truncated"].(bool)
+				blk.Vulns = nodeVulns(n)
 				if withPopups {
 					blk.Popup = extractPopupData(n)
 				}
@@ -294,6 +304,7 @@ func extractPopupData(n *dag.Node) *styles.PopupData {
 		Stars:       asInt(n.Meta["repo_stars"]),
 		Maintainers: countMaintainers(n.Meta["repo_maintainers"]),
 		Brittle:     IsBrittle(n),
+		Vulns:       nodeVulns(n),
 	}
 	p.LastCommit, _ = n.Meta["repo_last_commit"].(string)
 	p.LastRelease, _ = n.Meta["repo_last_release"].(string)
@@ -340,6 +351,7 @@ func buildSimpleEdges(l Layout, g *dag.DAG) []styles.Edge {
 			FromID: e.From, ToID: e.To,
 			X1: src.CenterX(), Y1: src.CenterY(),
 			X2: dst.CenterX(), Y2: dst.CenterY(),
+			Kind: edgeKind(e),
 		})
 	}
 	return edges
@@ -366,7 +378,7 @@ func buildMergedEdges(l Layout, g *dag.DAG) []styles.Edge {
 	}
 
 	type edgeKey struct{ from, to string }
-	seen := make(map[edgeKey]struct{})
+	seen := make(map[edgeKey]int)
 	var edges []styles.Edge
 
 	for _, e := range g.Edges() {
@@ -376,10 +388,13 @@ func buildMergedEdges(l Layout, g *dag.DAG) []styles.Edge {
 		}
 
 		key := edgeKey{fromMaster, toMaster}
-		if _, exists := seen[key]; exists {
+		if i, exists := seen[key]; exists {
+			// Merged edges are drawn as runtime if any of them is.
+			if kind := edgeKind(e); kind == "" || kind == "runtime" {
+				edges[i].Kind = kind
+			}
 			continue
 		}
-		seen[key] = struct{}{}
 
 		src, okS := blockFor(e.From)
 		dst, okD := blockFor(e.To)
@@ -387,11 +402,18 @@ func buildMergedEdges(l Layout, g *dag.DAG) []styles.Edge {
 			continue
 		}
 
+		seen[key] = len(edges)
 		edges = append(edges, styles.Edge{
 			FromID: fromMaster, ToID: toMaster,
 			X1: src.CenterX(), Y1: src.CenterY(),
 			X2: dst.CenterX(), Y2: dst.CenterY(),
+			Kind: edgeKind(e),
 		})
 	}
 	return edges
 }
+
+func edgeKind(e dag.Edge) string {
+	kind, _ := e.Meta["kind"].(string)
+	return kind
+}
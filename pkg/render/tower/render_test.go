@@ -1,10 +1,12 @@
 package tower
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/render/tower/styles/handdrawn"
 )
 
 func TestRenderSVG_Simple(t *testing.T) {
@@ -168,3 +170,105 @@ func TestRenderSVG_MergedDeduplicatesEdgesToSameMaster(t *testing.T) {
 		t.Errorf("Expected 3 edges (A→C, B→C, C→D), got %d", lineCount)
 	}
 }
+
+func TestRenderSVG_EdgeKinds(t *testing.T) {
+	g := dag.New(nil)
+	g.AddNode(dag.Node{ID: "A", Row: 0})
+	g.AddNode(dag.Node{ID: "B", Row: 1})
+	g.AddNode(dag.Node{ID: "C", Row: 1})
+	g.AddEdge(dag.Edge{From: "A", To: "B", Meta: dag.Metadata{"kind": "runtime"}})
+	g.AddEdge(dag.Edge{From: "A", To: "C", Meta: dag.Metadata{"kind": "dev"}})
+
+	layout := Build(g, 100, 100)
+	svgStr := string(RenderSVG(layout, WithGraph(g), WithEdges()))
+
+	if !strings.Contains(svgStr, `class="edge edge-runtime"`) {
+		t.Error("expected runtime edge class")
+	}
+	if !strings.Contains(svgStr, `class="edge edge-dev"`) || !strings.Contains(svgStr, `stroke-opacity="0.4"`) {
+		t.Error("expected faded dev edge")
+	}
+}
+
+func TestRenderSVG_Label(t *testing.T) {
+	g := dag.New(nil)
+	g.AddNode(dag.Node{ID: "pkg:pypi/requests@2.31.0", Row: 0, Meta: dag.Metadata{"label": "requests"}})
+
+	layout := Build(g, 100, 100)
+	svgStr := string(RenderSVG(layout, WithGraph(g)))
+
+	if !strings.Contains(svgStr, ">requests</text>") {
+		t.Error("expected block to show its label")
+	}
+	if !strings.Contains(svgStr, `data-block="pkg:pypi/requests@2.31.0"`) {
+		t.Error("expected block to keep its ID")
+	}
+}
+
+func TestRenderSVG_IncompleteNodes(t *testing.T) {
+	g := dag.New(nil)
+	g.AddNode(dag.Node{ID: "A", Row: 0})
+	g.AddNode(dag.Node{ID: "B", Row: 1, Meta: dag.Metadata{"fetch_error": "resource not found", "fetch_error_class": "not-found"}})
+	g.AddNode(dag.Node{ID: "C", Row: 1, Meta: dag.Metadata{"truncated": true}})
+	g.AddEdge(dag.Edge{From: "A", To: "B"})
+	g.AddEdge(dag.Edge{From: "A", To: "C"})
+
+	layout := Build(g, 100, 100)
+	svgStr := string(RenderSVG(layout, WithGraph(g)))
+
+	if !strings.Contains(svgStr, `class="block fetch-error"`) || !strings.Contains(svgStr, "<title>fetch failed (not-found): resource not found</title>") {
+		t.Error("expected failed block to be marked with its error")
+	}
+	if !strings.Contains(svgStr, `class="block truncated"`) {
+		t.Error("expected truncated block to be marked")
+	}
+	if strings.Count(svgStr, `stroke-dasharray="5,3"`) != 2 {
+		t.Error("expected dashed outlines on both incomplete blocks")
+	}
+}
+
+func TestRenderSVG_Vulnerable(t *testing.T) {
+	g := dag.New(nil)
+	g.AddNode(dag.Node{ID: "A", Row: 0, Meta: dag.Metadata{"vulns": []any{
+		map[string]any{"id": "GHSA-1", "severity": "HIGH", "fixed": "2.0.1"},
+		map[string]any{"id": "PYSEC-2"},
+	}}})
+
+	layout := Build(g, 100, 100)
+	svgStr := string(RenderSVG(layout, WithGraph(g), WithStyle(handdrawn.New(1)), WithPopups()))
+
+	if !strings.Contains(svgStr, `class="block vulnerable"`) || !strings.Contains(svgStr, `stroke="#e67e22"`) {
+		t.Error("expected vulnerable block to be marked")
+	}
+	if !strings.Contains(svgStr, "<title>known vulnerabilities (2):&#xA;  GHSA-1 (HIGH, fixed in 2.0.1)&#xA;  PYSEC-2</title>") {
+		t.Error("expected tooltip to list the advisories")
+	}
+	if !strings.Contains(svgStr, `class="popup-vuln"`) || !strings.Contains(svgStr, "⚠ GHSA-1 (HIGH, fixed in 2.0.1)") {
+		t.Error("expected popup to list the advisories")
+	}
+}
+
+func TestRenderSVG_LicenseColors(t *testing.T) {
+	g := dag.New(nil)
+	g.AddNode(dag.Node{ID: "A", Row: 0, Meta: dag.Metadata{"license": "MIT"}})
+	g.AddNode(dag.Node{ID: "B", Row: 1, Meta: dag.Metadata{"repo_license": "AGPL-3.0-only"}})
+	g.AddNode(dag.Node{ID: "C", Row: 1})
+	g.AddEdge(dag.Edge{From: "A", To: "B"})
+	g.AddEdge(dag.Edge{From: "A", To: "C"})
+
+	layout := Build(g, 100, 100)
+	svgStr := string(RenderSVG(layout, WithGraph(g), WithLicenseColors()))
+
+	for id, family := range map[string]string{"A": "permissive", "B": "network-copyleft", "C": "unknown"} {
+		want := fmt.Sprintf(`id="block-%s" class="block license-%s"`, id, family)
+		if !strings.Contains(svgStr, want) {
+			t.Errorf("expected %s", want)
+		}
+	}
+	if !strings.Contains(svgStr, `fill="#f5b7b1"`) {
+		t.Error("expected network copyleft block to be filled red")
+	}
+	if strings.Contains(string(RenderSVG(layout, WithGraph(g))), "license-") {
+		t.Error("license classes without WithLicenseColors")
+	}
+}
@@ -2,6 +2,7 @@ package handdrawn
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
 	"strings"
 
@@ -22,6 +23,7 @@ const (
 	warnSymbolShift = 8.0
 	textWidthRatio  = 0.45
 	textHeightRatio = 1.0
+	maxPopupVulns   = 4
 
 	// Font stack: Patrick Hand (Google Fonts), then common casual/handwriting fonts
 	fontFamily = `'Patrick Hand', 'Comic Sans MS', 'Bradley Hand', 'Segoe Script', sans-serif`
@@ -46,17 +48,15 @@ func (h *HandDrawn) RenderDefs(buf *bytes.Buffer) {
 }
 
 func (h *HandDrawn) RenderBlock(buf *bytes.Buffer, b styles.Block) {
-	grey := greyForID(b.ID)
+	grey := cmp.Or(b.Fill, greyForID(b.ID))
 	rot := rotationFor(b.ID, b.W, b.H)
 	path := wobbledRect(b.X, b.Y, b.W, b.H, h.seed, b.ID)
 
+	o := styles.OutlineFor(b)
 	styles.WrapURL(buf, b.URL, func() {
-		class := "block"
-		if b.Brittle {
-			class = "block brittle"
-		}
-		fmt.Fprintf(buf, `<path id="block-%s" class="%s" d="%s" fill="%s" stroke="#333" stroke-width="2" stroke-linejoin="round" transform="rotate(%.3f %.2f %.2f)"/>`,
-			styles.EscapeXML(b.ID), class, path, grey, rot, b.CX, b.CY)
+		fmt.Fprintf(buf, `<path id="block-%s" class="%s" d="%s" fill="%s" stroke="%s" stroke-width="2" stroke-linejoin="round"%s transform="rotate(%.3f %.2f %.2f)"`,
+			styles.EscapeXML(b.ID), styles.BlockClass(b), path, grey, cmp.Or(o.Stroke, "#333"), o.DashAttr(), rot, b.CX, b.CY)
+		styles.CloseShape(buf, "path", b)
 	})
 	buf.WriteByte('\n')
 
@@ -68,7 +68,9 @@ func (h *HandDrawn) RenderBlock(buf *bytes.Buffer, b styles.Block) {
 
 func (h *HandDrawn) RenderEdge(buf *bytes.Buffer, e styles.Edge) {
 	path := curvedEdge(e.X1, e.Y1, e.X2, e.Y2)
-	fmt.Fprintf(buf, `  <path class="edge" d="%s" fill="none" stroke="#333" stroke-width="2.5" stroke-dasharray="8,5" stroke-linecap="round"/>`+"\n", path)
+	s := styles.StrokeFor(e, "8,5")
+	fmt.Fprintf(buf, `  <path class="%s" d="%s" fill="none" stroke="#333" stroke-width="2.5" stroke-dasharray="%s" stroke-opacity="%.2g" stroke-linecap="round"/>`+"\n",
+		styles.EdgeClass(e), path, s.Dash, s.Opacity)
 }
 
 func (h *HandDrawn) RenderText(buf *bytes.Buffer, b styles.Block) {
@@ -77,9 +79,9 @@ func (h *HandDrawn) RenderText(buf *bytes.Buffer, b styles.Block) {
 	if rotate {
 		size = styles.FontSizeRotated(b)
 	}
-	grey := greyForID(b.ID)
+	grey := cmp.Or(b.Fill, greyForID(b.ID))
 
-	textW, textH := float64(len(b.ID))*size*textWidthRatio, size*textHeightRatio
+	textW, textH := float64(len(b.Text()))*size*textWidthRatio, size*textHeightRatio
 	if rotate {
 		textW, textH = textH, textW
 	}
@@ -91,10 +93,10 @@ func (h *HandDrawn) RenderText(buf *bytes.Buffer, b styles.Block) {
 
 		if rotate {
 			fmt.Fprintf(buf, `    <text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="middle" font-family="%s" font-size="%.1f" fill="#333" transform="rotate(-90 %.2f %.2f)">%s</text>`+"\n",
-				b.CX, b.CY, fontFamily, size, b.CX, b.CY, styles.EscapeXML(b.ID))
+				b.CX, b.CY, fontFamily, size, b.CX, b.CY, styles.EscapeXML(b.Text()))
 		} else {
 			fmt.Fprintf(buf, `    <text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="middle" font-family="%s" font-size="%.1f" fill="#333">%s</text>`+"\n",
-				b.CX, b.CY, fontFamily, size, styles.EscapeXML(b.ID))
+				b.CX, b.CY, fontFamily, size, styles.EscapeXML(b.Text()))
 		}
 	})
 	buf.WriteString("  </g>\n")
@@ -120,7 +122,9 @@ func (h *HandDrawn) RenderPopup(buf *bytes.Buffer, b styles.Block) {
 		}
 	}
 
-	height := float64(numDescLines+statsRows)*popupLineHeight + popupPadding
+	vulnLines := vulnLines(p.Vulns)
+
+	height := float64(numDescLines+statsRows+len(vulnLines))*popupLineHeight + popupPadding
 	path := wobbledRect(0, 0, popupWidth, height, h.seed, b.ID+"_popup")
 
 	fmt.Fprintf(buf, `  <g class="popup" data-for="%s" visibility="hidden">`+"\n", styles.EscapeXML(b.ID))
@@ -161,11 +165,32 @@ func (h *HandDrawn) RenderPopup(buf *bytes.Buffer, b styles.Block) {
 			fmt.Fprintf(buf, `    <text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="middle" font-family="%s" font-size="%.0f" fill="#222" font-weight="bold">★ %s</text>`+"\n",
 				leftCenterX, starsCenterY, fontFamily, popupStarSize, formatNumber(p.Stars))
 		}
+		textY += popupLineHeight * float64(statsRows)
+	}
+
+	for _, line := range vulnLines {
+		fmt.Fprintf(buf, `    <text class="popup-vuln" x="%.1f" y="%.1f" font-family="%s" font-size="%.0f" fill="#c0392b">%s</text>`+"\n",
+			popupTextX, textY, fontFamily, popupTextSize, styles.EscapeXML(line))
+		textY += popupLineHeight
 	}
 
 	buf.WriteString("  </g>\n")
 }
 
+// vulnLines lists the vulnerabilities of a popup, one per line, the ones
+// beyond maxPopupVulns summed up in a last line.
+func vulnLines(vulns []styles.Vuln) []string {
+	var lines []string
+	for i, v := range vulns {
+		if i == maxPopupVulns && len(vulns) > maxPopupVulns+1 {
+			lines = append(lines, fmt.Sprintf("⚠ and %d more advisories", len(vulns)-maxPopupVulns))
+			break
+		}
+		lines = append(lines, "⚠ "+v.String())
+	}
+	return lines
+}
+
 func formatNumber(n int) string {
 	switch {
 	case n >= 1_000_000:
@@ -2,6 +2,7 @@ package styles
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
 )
 
@@ -18,16 +19,19 @@ func (Simple) RenderDefs(*bytes.Buffer) {}
 
 func (Simple) RenderBlock(buf *bytes.Buffer, b Block) {
 	radius := min(maxCornerRadius, b.W/cornerRatioDivisor, b.H/cornerRatioDivisor)
+	o := OutlineFor(b)
 	WrapURL(buf, b.URL, func() {
-		fmt.Fprintf(buf, `<rect id="block-%s" class="block" x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.1f" ry="%.1f" fill="white" stroke="#333" stroke-width="1"/>`,
-			EscapeXML(b.ID), b.X, b.Y, b.W, b.H, radius, radius)
+		fmt.Fprintf(buf, `<rect id="block-%s" class="%s" x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.1f" ry="%.1f" fill="%s" stroke="%s" stroke-width="1"%s`,
+			EscapeXML(b.ID), BlockClass(b), b.X, b.Y, b.W, b.H, radius, radius, cmp.Or(b.Fill, "white"), cmp.Or(o.Stroke, "#333"), o.DashAttr())
+		CloseShape(buf, "rect", b)
 	})
 	buf.WriteByte('\n')
 }
 
 func (Simple) RenderEdge(buf *bytes.Buffer, e Edge) {
-	fmt.Fprintf(buf, `  <line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#333" stroke-width="1.5" stroke-dasharray="6,4"/>`+"\n",
-		e.X1, e.Y1, e.X2, e.Y2)
+	s := StrokeFor(e, "6,4")
+	fmt.Fprintf(buf, `  <line class="%s" x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#333" stroke-width="1.5" stroke-dasharray="%s" stroke-opacity="%.2g"/>`+"\n",
+		EdgeClass(e), e.X1, e.Y1, e.X2, e.Y2, s.Dash, s.Opacity)
 }
 
 func (Simple) RenderText(buf *bytes.Buffer, b Block) {
@@ -37,22 +41,22 @@ func (Simple) RenderText(buf *bytes.Buffer, b Block) {
 		size = FontSizeRotated(b)
 	}
 
-	textW, textH := float64(len(b.ID))*size*textWidthRatio, size*textHeightRatio
+	textW, textH := float64(len(b.Text()))*size*textWidthRatio, size*textHeightRatio
 	if rotate {
 		textW, textH = textH, textW
 	}
 
 	fmt.Fprintf(buf, `  <g class="block-text" data-block="%s">`+"\n", EscapeXML(b.ID))
 	WrapURL(buf, b.URL, func() {
-		fmt.Fprintf(buf, `    <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="white"/>`+"\n",
-			b.CX-textW/2, b.CY-textH/2, textW, textH)
+		fmt.Fprintf(buf, `    <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n",
+			b.CX-textW/2, b.CY-textH/2, textW, textH, cmp.Or(b.Fill, "white"))
 
 		if rotate {
 			fmt.Fprintf(buf, `    <text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="middle" font-family="Times,serif" font-size="%.1f" fill="#333" transform="rotate(-90 %.2f %.2f)">%s</text>`+"\n",
-				b.CX, b.CY, size, b.CX, b.CY, EscapeXML(b.ID))
+				b.CX, b.CY, size, b.CX, b.CY, EscapeXML(b.Text()))
 		} else {
 			fmt.Fprintf(buf, `    <text x="%.2f" y="%.2f" text-anchor="middle" dominant-baseline="middle" font-family="Times,serif" font-size="%.1f" fill="#333">%s</text>`+"\n",
-				b.CX, b.CY, size, EscapeXML(b.ID))
+				b.CX, b.CY, size, EscapeXML(b.Text()))
 		}
 	})
 	buf.WriteString("  </g>\n")
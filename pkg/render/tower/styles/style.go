@@ -1,6 +1,11 @@
 package styles
 
-import "bytes"
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"strings"
+)
 
 type Style interface {
 	RenderDefs(buf *bytes.Buffer)
@@ -12,11 +17,141 @@ type Style interface {
 
 type Block struct {
 	ID         string
+	Label      string // display name from the "label" node meta; empty means ID
 	X, Y, W, H float64
 	CX, CY     float64
 	URL        string
 	Popup      *PopupData
 	Brittle    bool
+	FetchError string // from the "fetch_error" node meta; the package could not be fetched
+	ErrorClass string // from the "fetch_error_class" node meta
+	Truncated  bool   // the crawl stopped before the package's dependencies
+	Vulns      []Vuln // from the "vulns" node meta; advisories affecting the package
+
+	// LicenseFamily and Fill are set when coloring by license; an empty
+	// Fill keeps the style's.
+	LicenseFamily string
+	Fill          string
+}
+
+// Vuln is a known vulnerability of a block's package version.
+type Vuln struct {
+	ID       string
+	Severity string // empty if unknown
+	Fixed    string // the first fixed version; empty if there is none
+
+	// Unverified is set when the version may not be affected: the
+	// advisory's ranges could not be evaluated for it.
+	Unverified bool
+}
+
+// String describes the vulnerability in one line:
+// GHSA-xxxx (HIGH, fixed in 2.0.1).
+func (v Vuln) String() string {
+	var details []string
+	if v.Severity != "" {
+		details = append(details, v.Severity)
+	}
+	if v.Fixed != "" {
+		details = append(details, "fixed in "+v.Fixed)
+	}
+	if v.Unverified {
+		details = append(details, "unverified")
+	}
+	if len(details) == 0 {
+		return v.ID
+	}
+	return v.ID + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Text returns the name a block is labelled with.
+func (b Block) Text() string { return cmp.Or(b.Label, b.ID) }
+
+// BlockOutline is how the outline of an incomplete block departs from a
+// style's own. Empty fields keep the style's stroke.
+type BlockOutline struct {
+	Stroke string
+	Dash   string
+}
+
+const (
+	incompleteDash   = "5,3"
+	vulnerableStroke = "#e67e22"
+)
+
+// OutlineFor returns the outline of a block: red and dashed when its
+// package could not be fetched, dashed when the crawl was cut off below it,
+// and orange when the package has known vulnerabilities.
+func OutlineFor(b Block) BlockOutline {
+	if b.FetchError != "" {
+		return BlockOutline{Stroke: "#c0392b", Dash: incompleteDash}
+	}
+	var o BlockOutline
+	if b.Truncated {
+		o.Dash = incompleteDash
+	}
+	if len(b.Vulns) > 0 {
+		o.Stroke = vulnerableStroke
+	}
+	return o
+}
+
+// BlockClass returns the CSS classes of a block.
+func BlockClass(b Block) string {
+	class := "block"
+	if b.Brittle {
+		class += " brittle"
+	}
+	if b.FetchError != "" {
+		class += " fetch-error"
+	} else if b.Truncated {
+		class += " truncated"
+	}
+	if len(b.Vulns) > 0 {
+		class += " vulnerable"
+	}
+	if b.LicenseFamily != "" {
+		class += " license-" + b.LicenseFamily
+	}
+	return class
+}
+
+// BlockTitle returns the tooltip of an incomplete or vulnerable block, or
+// "".
+func BlockTitle(b Block) string {
+	if b.FetchError != "" {
+		return fmt.Sprintf("fetch failed (%s): %s", cmp.Or(b.ErrorClass, "other"), b.FetchError)
+	}
+	var lines []string
+	if b.Truncated {
+		lines = append(lines, "dependencies not crawled (max depth or max nodes reached)")
+	}
+	if len(b.Vulns) > 0 {
+		lines = append(lines, fmt.Sprintf("known vulnerabilities (%d):", len(b.Vulns)))
+		for _, v := range b.Vulns {
+			lines = append(lines, "  "+v.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DashAttr returns the stroke-dasharray attribute of the outline, or "".
+func (o BlockOutline) DashAttr() string {
+	if o.Dash == "" {
+		return ""
+	}
+	return fmt.Sprintf(` stroke-dasharray="%s"`, o.Dash)
+}
+
+// CloseShape ends the open tag of a block's shape element, nesting the
+// block's tooltip in it if it has one.
+func CloseShape(buf *bytes.Buffer, tag string, b Block) {
+	title := BlockTitle(b)
+	if title == "" {
+		buf.WriteString("/>")
+		return
+	}
+	fmt.Fprintf(buf, "><title>%s</title></%s>", EscapeXML(title), tag)
 }
 
 type PopupData struct {
@@ -27,9 +162,47 @@ type PopupData struct {
 	Maintainers int
 	Archived    bool
 	Brittle     bool
+	Vulns       []Vuln
 }
 
 type Edge struct {
 	FromID, ToID   string
 	X1, Y1, X2, Y2 float64
+	Kind           string // dependency kind from the "kind" edge meta; empty means runtime
+}
+
+// EdgeStroke is how an edge of some dependency kind departs from a style's
+// runtime edges. An empty Dash keeps the style's own dash pattern.
+type EdgeStroke struct {
+	Dash    string
+	Opacity float64
+}
+
+var edgeStrokes = map[string]EdgeStroke{
+	"optional": {Dash: "2,4", Opacity: 1},
+	"peer":     {Dash: "12,4,2,4", Opacity: 1},
+	"dev":      {Opacity: 0.4},
+	"build":    {Dash: "1,3", Opacity: 0.7},
+}
+
+// StrokeFor returns the stroke of an edge, falling back to dash at full
+// opacity for runtime and unknown kinds.
+func StrokeFor(e Edge, dash string) EdgeStroke {
+	s, ok := edgeStrokes[e.Kind]
+	if !ok {
+		return EdgeStroke{Dash: dash, Opacity: 1}
+	}
+	if s.Dash == "" {
+		s.Dash = dash
+	}
+	return s
+}
+
+// EdgeClass returns the class attribute of an edge, so kinds can also be
+// styled with CSS.
+func EdgeClass(e Edge) string {
+	if e.Kind == "" {
+		return "edge"
+	}
+	return "edge edge-" + EscapeXML(e.Kind)
 }
@@ -15,8 +15,8 @@ const (
 	rotateSizeDampen = 0.75
 )
 
-func FontSize(b Block) float64        { return fontSizeFor(b.W, b.H, len(b.ID)) }
-func FontSizeRotated(b Block) float64 { return fontSizeFor(b.H*rotateSizeDampen, b.W, len(b.ID)) }
+func FontSize(b Block) float64        { return fontSizeFor(b.W, b.H, len(b.Text())) }
+func FontSizeRotated(b Block) float64 { return fontSizeFor(b.H*rotateSizeDampen, b.W, len(b.Text())) }
 
 func fontSizeFor(availWidth, availHeight float64, textLen int) float64 {
 	n := max(1, textLen)
@@ -26,9 +26,9 @@ func fontSizeFor(availWidth, availHeight float64, textLen int) float64 {
 }
 
 func ShouldRotate(b Block, _ float64) bool {
-	horizSize := fontSizeFor(b.W, b.H, len(b.ID))
-	rotSize := fontSizeFor(b.H, b.W, len(b.ID))
-	if len(b.ID) > 10 {
+	horizSize := fontSizeFor(b.W, b.H, len(b.Text()))
+	rotSize := fontSizeFor(b.H, b.W, len(b.Text()))
+	if len(b.Text()) > 10 {
 		return rotSize*1.1 >= horizSize
 	}
 	return rotSize > horizSize
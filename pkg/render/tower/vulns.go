@@ -0,0 +1,35 @@
+package tower
+
+import (
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/render/tower/styles"
+)
+
+// nodeVulns reads the "vulns" meta of a node: a list of maps with an "id"
+// and optionally "severity", "fixed" and "unverified".
+func nodeVulns(n *dag.Node) []styles.Vuln {
+	var items []map[string]any
+	switch list := n.Meta["vulns"].(type) {
+	case []map[string]any:
+		items = list
+	case []any:
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+	}
+
+	var vulns []styles.Vuln
+	for _, m := range items {
+		var v styles.Vuln
+		v.ID, _ = m["id"].(string)
+		v.Severity, _ = m["severity"].(string)
+		v.Fixed, _ = m["fixed"].(string)
+		v.Unverified, _ = m["unverified"].(bool)
+		if v.ID != "" {
+			vulns = append(vulns, v)
+		}
+	}
+	return vulns
+}
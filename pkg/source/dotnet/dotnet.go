@@ -0,0 +1,114 @@
+package dotnet
+
+import (
+	"context"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations/nuget"
+	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+const DefaultFramework = "net8.0"
+
+// Parser implements source.Parser for NuGet. Each package contributes the
+// dependency group nearest to the configured target framework.
+type Parser struct {
+	client    *nuget.Client
+	framework nuget.Framework
+}
+
+func NewParser(framework string, cacheTTL time.Duration) (*Parser, error) {
+	if framework == "" {
+		framework = DefaultFramework
+	}
+	fw, err := nuget.ParseFramework(framework)
+	if err != nil {
+		return nil, err
+	}
+	c, err := nuget.NewClient(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{client: c, framework: fw}, nil
+}
+
+func (p *Parser) Parse(ctx context.Context, pkgs []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLNuGet
+	opts.VersionScheme = version.NuGet
+	// Dependencies name packages by lower-case ID; roots must match them.
+	ids := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		ids[i] = nuget.NormalizeName(pkg)
+	}
+	return source.Parse(ctx, source.Roots(ids), opts, p.fetch)
+}
+
+// fetch fetches the version NuGet restores for dep: the lowest listed one
+// its range allows. Roots and dependencies whose range no listed version
+// satisfies get the latest.
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*packageInfo, error) {
+	var v string
+	if dep.Constraint != "" {
+		versions, err := p.client.FetchVersions(ctx, dep.Name, refresh)
+		if err != nil {
+			return nil, err
+		}
+		v, _ = version.Lowest(version.NuGet, versions, dep.Constraint)
+	}
+	info, err := p.client.FetchPackageVersion(ctx, dep.Name, v, refresh)
+	if err != nil {
+		return nil, err
+	}
+	pi := &packageInfo{PackageInfo: info}
+	pi.group, pi.hasGroup = nuget.NearestGroup(info.DependencyGroups, p.framework)
+	return pi, nil
+}
+
+type packageInfo struct {
+	*nuget.PackageInfo
+	group    nuget.DependencyGroup
+	hasGroup bool
+}
+
+func (pi *packageInfo) GetName() string    { return pi.Name }
+func (pi *packageInfo) GetVersion() string { return pi.Version }
+
+func (pi *packageInfo) GetDependencies() []source.Dependency {
+	deps := make([]source.Dependency, 0, len(pi.group.Dependencies))
+	for _, d := range pi.group.Dependencies {
+		deps = append(deps, source.Dependency{Name: d.ID, Constraint: d.Range})
+	}
+	return deps
+}
+
+func (pi *packageInfo) ToMetadata() map[string]any {
+	m := map[string]any{"version": pi.Version}
+	if pi.Description != "" {
+		m["description"] = pi.Description
+	}
+	if pi.License != "" {
+		m["license"] = pi.License
+	}
+	if pi.Authors != "" {
+		m["author"] = pi.Authors
+	}
+	if pi.hasGroup && pi.group.TargetFramework != "" {
+		m["target_framework"] = pi.group.TargetFramework
+	}
+	return m
+}
+
+func (pi *packageInfo) ToRepoInfo() *source.RepoInfo {
+	urls := make(map[string]string, 1)
+	if pi.ProjectURL != "" {
+		urls["homepage"] = pi.ProjectURL
+	}
+	return &source.RepoInfo{
+		Name:        pi.Name,
+		Version:     pi.Version,
+		ProjectURLs: urls,
+		HomePage:    pi.ProjectURL,
+	}
+}
@@ -0,0 +1,126 @@
+package dotnet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestNewParser(t *testing.T) {
+	p, err := NewParser("", time.Hour)
+	if err != nil {
+		t.Fatalf("NewParser failed: %v", err)
+	}
+	if p.client == nil {
+		t.Error("client not initialized")
+	}
+	if p.framework.String() != ".NETCoreApp8" {
+		t.Errorf("expected default framework net8.0, got %s", p.framework)
+	}
+}
+
+func TestNewParser_InvalidFramework(t *testing.T) {
+	if _, err := NewParser("not a framework!", time.Hour); err == nil {
+		t.Error("expected error for invalid framework")
+	}
+}
+
+func TestParse_RootIDCase(t *testing.T) {
+	entry := func(id string, deps ...string) string {
+		var list []string
+		for _, d := range deps {
+			list = append(list, fmt.Sprintf(`{"id": %q, "range": "[13.0.1, )"}`, d))
+		}
+		return fmt.Sprintf(`{"items": [{"items": [{"catalogEntry": {"id": %q, "version": "1.0.0",
+			"dependencyGroups": [{"targetFramework": "net8.0", "dependencies": [%s]}]}}]}]}`, id, strings.Join(list, ","))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/serilog/index.json":
+			fmt.Fprint(w, entry("Serilog", "Newtonsoft.Json"))
+		case "/newtonsoft.json/index.json":
+			fmt.Fprint(w, entry("Newtonsoft.Json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("STACKTOWER_NUGET_URL", server.URL)
+	if err := integrations.SetCacheBackend(integrations.CacheMemory); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = integrations.SetCacheBackend(integrations.CacheFile) })
+
+	p, err := NewParser("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := p.Parse(context.Background(), []string{"Serilog", "Newtonsoft.Json"}, source.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NodeCount() != 2 {
+		var ids []string
+		for _, n := range g.Nodes() {
+			ids = append(ids, n.ID)
+		}
+		t.Errorf("nodes = %v, want serilog and newtonsoft.json", ids)
+	}
+	if parents := g.Parents("newtonsoft.json"); len(parents) != 1 || parents[0] != "serilog" {
+		t.Errorf("parents of newtonsoft.json = %v, want [serilog]", parents)
+	}
+}
+
+func TestParse_LowestInRange(t *testing.T) {
+	leaf := func(id, version string, deps string) string {
+		return fmt.Sprintf(`{"catalogEntry": {"id": %q, "version": %q,
+			"dependencyGroups": [{"targetFramework": "net8.0", "dependencies": [%s]}]}}`, id, version, deps)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/index.json":
+			fmt.Fprintf(w, `{"items": [{"items": [%s]}]}`, leaf("App", "1.0.0", `{"id": "Lib", "range": "[12.0.0, )"}`))
+		case "/lib/index.json":
+			fmt.Fprintf(w, `{"items": [{"items": [%s, %s, %s]}]}`,
+				leaf("Lib", "11.0.2", ""),
+				leaf("Lib", "13.0.3", `{"id": "New", "range": "[1.0.0, )"}`),
+				leaf("Lib", "12.0.1", `{"id": "Old", "range": "[1.0.0, )"}`))
+		case "/old/index.json", "/new/index.json":
+			fmt.Fprintf(w, `{"items": [{"items": [%s]}]}`, leaf("Dep", "1.0.0", ""))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("STACKTOWER_NUGET_URL", server.URL)
+	if err := integrations.SetCacheBackend(integrations.CacheMemory); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = integrations.SetCacheBackend(integrations.CacheFile) })
+
+	p, err := NewParser("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := p.Parse(context.Background(), []string{"App"}, source.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, ok := g.Node("lib")
+	if !ok {
+		t.Fatal("lib missing")
+	}
+	if got := n.Meta["version"]; got != "12.0.1" {
+		t.Errorf("lib version = %v, want the lowest match 12.0.1", got)
+	}
+	if children := g.Children("lib"); len(children) != 1 || children[0] != "old" {
+		t.Errorf("children of lib = %v, want [old]", children)
+	}
+}
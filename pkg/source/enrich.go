@@ -0,0 +1,126 @@
+package source
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+// manifestFiles are the manifests that name packages of each Package URL
+// type, which providers search forges for when a package has no
+// repository URL.
+var manifestFiles = map[string]string{
+	PURLCargo:    "Cargo.toml",
+	PURLComposer: "composer.json",
+	PURLGem:      "Gemfile",
+	PURLGolang:   "go.mod",
+	PURLMaven:    "pom.xml",
+	PURLNPM:      "package.json",
+	PURLPyPI:     "pyproject.toml",
+}
+
+// NodeRepoInfo reconstructs what a parser knew about the package of a node
+// from its meta: the name and version from its "purl" (or its "label" or
+// ID and "version"), and its repository from "repo_url", "repository" and
+// "homepage". purlType is the registry of nodes without a Package URL.
+func NodeRepoInfo(n *dag.Node, purlType string) *RepoInfo {
+	label, _ := n.Meta["label"].(string)
+	repo := &RepoInfo{Name: cmp.Or(label, n.ID), PURLType: purlType}
+	if purl, ok := n.Meta["purl"].(string); ok {
+		if typ, name, version, ok := ParsePackageURL(purl); ok {
+			repo.PURLType, repo.Name, repo.Version = typ, name, version
+		}
+	}
+	if v, _ := n.Meta["version"].(string); v != "" {
+		repo.Version = v
+	}
+	repo.ManifestFile = manifestFiles[repo.PURLType]
+
+	repo.ProjectURLs = make(map[string]string)
+	for _, key := range []string{"repo_url", "repository"} {
+		if u, _ := n.Meta[key].(string); u != "" {
+			repo.ProjectURLs["repository"] = u
+			break
+		}
+	}
+	repo.HomePage, _ = n.Meta["homepage"].(string)
+	if repo.HomePage == "" && repo.PURLType == PURLGolang {
+		// Module paths on forges are the repository's address.
+		repo.HomePage = "https://" + repo.Name
+	}
+	return repo
+}
+
+// Enrich runs the metadata providers of opts over the packages of an
+// existing graph, merging what they return into the node meta. Synthetic
+// nodes and a root placed above the parsed roots are left alone. Failures
+// of a provider are logged and skip the package.
+func Enrich(ctx context.Context, g *dag.DAG, opts Options) error {
+	opts = opts.withDefaults()
+	if opts.Offline {
+		ctx = integrations.WithOffline(ctx)
+	}
+
+	var nodes []*dag.Node
+	for _, n := range g.Nodes() {
+		if !n.IsSynthetic() && !syntheticRoot(g, n.ID) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	results := make([]map[string]any, len(nodes))
+	sem := make(chan struct{}, numWorkers)
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			repo := NodeRepoInfo(n, opts.PURLType)
+			m := make(map[string]any)
+			for _, provider := range opts.MetadataProviders {
+				enriched, err := provider.Enrich(ctx, repo, opts.Refresh)
+				if err != nil {
+					opts.Logger("failed to enrich %s via %s: %v", n.ID, provider.Name(), err)
+					continue
+				}
+				maps.Copy(m, enriched)
+			}
+			results[i] = m
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i, n := range nodes {
+		if len(results[i]) == 0 {
+			continue
+		}
+		if n.Meta == nil {
+			n.Meta = make(dag.Metadata)
+		}
+		maps.Copy(n.Meta, results[i])
+	}
+	return nil
+}
+
+// syntheticRoot reports whether id is the node parse --root or merge
+// --root placed above the packages a graph was parsed from.
+func syntheticRoot(g *dag.DAG, id string) bool {
+	meta := g.Meta()
+	roots := MetaStrings(meta["roots"])
+	return meta["root"] == id && len(roots) > 0 && !slices.Contains(roots, id)
+}
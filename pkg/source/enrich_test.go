@@ -0,0 +1,80 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+// recordingProvider returns the repository URL of each package it is
+// asked about and fails for "broken".
+type recordingProvider struct {
+	mu    sync.Mutex
+	repos map[string]RepoInfo
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) Enrich(_ context.Context, repo *RepoInfo, _ bool) (map[string]any, error) {
+	p.mu.Lock()
+	p.repos[repo.Name] = *repo
+	p.mu.Unlock()
+	if repo.Name == "broken" {
+		return nil, errors.New("boom")
+	}
+	return map[string]any{"repo_stars": 7}, nil
+}
+
+func TestEnrich(t *testing.T) {
+	g := dag.New(dag.Metadata{"root": "monorepo", "roots": []any{"app"}})
+	g.AddNode(dag.Node{ID: "monorepo"})
+	g.AddNode(dag.Node{ID: "app", Meta: dag.Metadata{"version": "1.0", "repo_url": "https://github.com/acme/app"}})
+	g.AddNode(dag.Node{ID: "pkg:maven/org.acme/lib@2.1", Meta: dag.Metadata{"purl": "pkg:maven/org.acme/lib@2.1", "label": "lib"}})
+	g.AddNode(dag.Node{ID: "broken", Meta: dag.Metadata{"homepage": "https://broken.example"}})
+	g.AddNode(dag.Node{ID: "app_sub_1", Kind: dag.NodeKindSubdivider, MasterID: "app"})
+	g.AddEdge(dag.Edge{From: "monorepo", To: "app"})
+	g.AddEdge(dag.Edge{From: "app", To: "pkg:maven/org.acme/lib@2.1"})
+	g.AddEdge(dag.Edge{From: "app", To: "broken"})
+
+	p := &recordingProvider{repos: make(map[string]RepoInfo)}
+	var logged int
+	opts := Options{
+		MetadataProviders: []MetadataProvider{p},
+		PURLType:          PURLNPM,
+		Logger:            func(string, ...any) { logged++ },
+	}
+	if err := Enrich(context.Background(), g, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.repos) != 3 {
+		t.Errorf("enriched %d packages, want app, lib and broken: %v", len(p.repos), p.repos)
+	}
+	app := p.repos["app"]
+	if app.Version != "1.0" || app.PURLType != PURLNPM || app.ManifestFile != "package.json" || app.ProjectURLs["repository"] != "https://github.com/acme/app" {
+		t.Errorf("app repo info = %+v", app)
+	}
+	lib := p.repos["org.acme:lib"]
+	if lib.Version != "2.1" || lib.PURLType != PURLMaven || lib.ManifestFile != "pom.xml" {
+		t.Errorf("lib repo info = %+v", lib)
+	}
+	if p.repos["broken"].HomePage != "https://broken.example" {
+		t.Errorf("broken repo info = %+v", p.repos["broken"])
+	}
+
+	if n, _ := g.Node("app"); n.Meta["repo_stars"] != 7 || n.Meta["version"] != "1.0" {
+		t.Errorf("app meta = %v", n.Meta)
+	}
+	if n, _ := g.Node("broken"); n.Meta["repo_stars"] != nil {
+		t.Errorf("broken meta = %v", n.Meta)
+	}
+	if n, _ := g.Node("monorepo"); n.Meta["repo_stars"] != nil {
+		t.Error("synthetic root was enriched")
+	}
+	if logged != 1 {
+		t.Errorf("logged %d failures, want 1", logged)
+	}
+}
@@ -0,0 +1,78 @@
+package golang
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations/goproxy"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type Parser struct {
+	client *goproxy.Client
+}
+
+func NewParser(proxyURL string, cacheTTL time.Duration) (*Parser, error) {
+	c, err := goproxy.NewClient(proxyURL, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{client: c}, nil
+}
+
+func (p *Parser) Parse(ctx context.Context, mods []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLGolang
+	return source.Parse(ctx, source.Roots(mods), opts, p.fetch)
+}
+
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*moduleInfo, error) {
+	info, err := p.client.FetchModule(ctx, dep.Name, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleInfo{info}, nil
+}
+
+type moduleInfo struct {
+	*goproxy.ModuleInfo
+}
+
+func (mi *moduleInfo) GetName() string                      { return mi.Path }
+func (mi *moduleInfo) GetVersion() string                   { return mi.Version }
+func (mi *moduleInfo) GetDependencies() []source.Dependency { return mi.Dependencies }
+
+func (mi *moduleInfo) ToMetadata() map[string]any {
+	m := map[string]any{"version": mi.Version}
+	if mi.Time != nil && !mi.Time.IsZero() {
+		m["published"] = mi.Time.Format("2006-01-02")
+	}
+	return m
+}
+
+func (mi *moduleInfo) ToRepoInfo() *source.RepoInfo {
+	urls := make(map[string]string, 1)
+	if repo := repoURL(mi.Path); repo != "" {
+		urls["repository"] = repo
+	}
+	return &source.RepoInfo{
+		Name:         mi.Path,
+		Version:      mi.Version,
+		ProjectURLs:  urls,
+		ManifestFile: "go.mod",
+	}
+}
+
+// repoURL maps module paths hosted on a known forge to their repository,
+// dropping subdirectories and major version suffixes (github.com/a/b/v2/c).
+func repoURL(path string) string {
+	parts := strings.Split(path, "/")
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "codeberg.org":
+		if len(parts) >= 3 {
+			return "https://" + strings.Join(parts[:3], "/")
+		}
+	}
+	return ""
+}
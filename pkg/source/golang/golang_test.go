@@ -0,0 +1,37 @@
+package golang
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewParser(t *testing.T) {
+	p, err := NewParser("", time.Hour)
+	if err != nil {
+		t.Fatalf("NewParser failed: %v", err)
+	}
+	if p.client == nil {
+		t.Error("client not initialized")
+	}
+}
+
+func TestRepoURL(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"github.com/spf13/cobra", "https://github.com/spf13/cobra"},
+		{"github.com/goccy/go-graphviz/v2/cgraph", "https://github.com/goccy/go-graphviz"},
+		{"gitlab.com/group/project", "https://gitlab.com/group/project"},
+		{"golang.org/x/mod", ""},
+		{"github.com/short", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := repoURL(tt.path); got != tt.want {
+				t.Errorf("repoURL(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
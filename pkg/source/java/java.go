@@ -0,0 +1,90 @@
+package java
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations/maven"
+	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+// Parser implements source.Parser for Maven Central artifacts, identified
+// as groupId:artifactId.
+type Parser struct {
+	client *maven.Client
+}
+
+func NewParser(cacheTTL time.Duration) (*Parser, error) {
+	c, err := maven.NewClient(cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{client: c}, nil
+}
+
+func (p *Parser) Parse(ctx context.Context, coords []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLMaven
+	opts.VersionScheme = version.Maven
+	return source.Parse(ctx, source.Roots(coords), opts, p.fetch)
+}
+
+// fetch fetches the release a build of the parent would use: the version
+// its effective POM names, or the highest release in a version range.
+// Roots and dependencies whose version is missing or still holds a
+// property get the highest release.
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*artifactInfo, error) {
+	v := dep.Constraint
+	switch {
+	case strings.Contains(v, "${"):
+		v = ""
+	case strings.ContainsAny(v, "[("):
+		var err error
+		if v, err = source.ResolveVersion(ctx, dep, version.Maven, p.client.FetchVersions, refresh); err != nil {
+			return nil, err
+		}
+	}
+	info, err := p.client.FetchArtifactVersion(ctx, dep.Name, v, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactInfo{info}, nil
+}
+
+type artifactInfo struct {
+	*maven.ArtifactInfo
+}
+
+func (ai *artifactInfo) GetName() string                      { return ai.Name }
+func (ai *artifactInfo) GetVersion() string                   { return ai.Version }
+func (ai *artifactInfo) GetDependencies() []source.Dependency { return ai.Dependencies }
+
+func (ai *artifactInfo) ToMetadata() map[string]any {
+	m := map[string]any{"version": ai.Version}
+	if ai.Description != "" {
+		m["description"] = ai.Description
+	}
+	if ai.License != "" {
+		m["license"] = ai.License
+	}
+	return m
+}
+
+func (ai *artifactInfo) ToRepoInfo() *source.RepoInfo {
+	urls := make(map[string]string, 2)
+	if ai.Repository != "" {
+		urls["repository"] = ai.Repository
+	}
+	if ai.HomePage != "" {
+		urls["homepage"] = ai.HomePage
+	}
+	return &source.RepoInfo{
+		Name:         ai.Name,
+		Version:      ai.Version,
+		ProjectURLs:  urls,
+		HomePage:     ai.HomePage,
+		ManifestFile: "pom.xml",
+	}
+}
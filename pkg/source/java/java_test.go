@@ -0,0 +1,101 @@
+package java
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestNewParser(t *testing.T) {
+	p, err := NewParser(time.Hour)
+	if err != nil {
+		t.Fatalf("NewParser failed: %v", err)
+	}
+	if p.client == nil {
+		t.Error("client not initialized")
+	}
+}
+
+func TestParse_ManagedVersions(t *testing.T) {
+	pom := func(artifact, version, body string) string {
+		return fmt.Sprintf(`<project><groupId>org.acme</groupId><artifactId>%s</artifactId><version>%s</version>%s</project>`, artifact, version, body)
+	}
+	dep := func(artifact, version string) string {
+		return fmt.Sprintf(`<dependency><groupId>org.acme</groupId><artifactId>%s</artifactId><version>%s</version></dependency>`, artifact, version)
+	}
+	metadata := func(versions ...string) string {
+		s := "<metadata><versioning><versions>"
+		for _, v := range versions {
+			s += "<version>" + v + "</version>"
+		}
+		return s + "</versions></versioning></metadata>"
+	}
+	repo := map[string]string{
+		"/org/acme/app/maven-metadata.xml": metadata("1.0"),
+		"/org/acme/app/1.0/app-1.0.pom": pom("app", "1.0", `
+			<dependencyManagement><dependencies>`+dep("core", "1.2.0")+`</dependencies></dependencyManagement>
+			<dependencies>
+			  <dependency><groupId>org.acme</groupId><artifactId>core</artifactId></dependency>
+			  `+dep("util", "[2.0,3.0)")+`
+			</dependencies>`),
+		"/org/acme/core/maven-metadata.xml":   metadata("1.2.0", "2.0.0"),
+		"/org/acme/core/1.2.0/core-1.2.0.pom": pom("core", "1.2.0", "<dependencies>"+dep("legacy", "1.0")+"</dependencies>"),
+		"/org/acme/core/2.0.0/core-2.0.0.pom": pom("core", "2.0.0", "<dependencies>"+dep("modern", "1.0")+"</dependencies>"),
+		"/org/acme/util/maven-metadata.xml":   metadata("2.1", "3.0", "2.4"),
+		"/org/acme/util/2.4/util-2.4.pom":     pom("util", "2.4", ""),
+		"/org/acme/legacy/1.0/legacy-1.0.pom": pom("legacy", "1.0", ""),
+		"/org/acme/modern/1.0/modern-1.0.pom": pom("modern", "1.0", ""),
+		"/org/acme/legacy/maven-metadata.xml": metadata("1.0"),
+		"/org/acme/modern/maven-metadata.xml": metadata("1.0"),
+		"/org/acme/util/3.0/util-3.0.pom":     pom("util", "3.0", ""),
+		"/org/acme/util/2.1/util-2.1.pom":     pom("util", "2.1", ""),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := repo[r.URL.Path]; ok {
+			fmt.Fprint(w, body)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	t.Setenv("STACKTOWER_MAVEN_URL", server.URL)
+	if err := integrations.SetCacheBackend(integrations.CacheMemory); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = integrations.SetCacheBackend(integrations.CacheFile) })
+
+	p, err := NewParser(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := p.Parse(context.Background(), []string{"org.acme:app"}, source.Options{MaxDepth: 5, MaxNodes: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]string{"org.acme:core": "1.2.0", "org.acme:util": "2.4"} {
+		n, ok := g.Node(id)
+		if !ok {
+			t.Errorf("node %s missing", id)
+			continue
+		}
+		if got := n.Meta["version"]; got != want {
+			t.Errorf("%s version = %v, want %s", id, got, want)
+		}
+	}
+	if got := g.Children("org.acme:core"); !slices.Equal(got, []string{"org.acme:legacy"}) {
+		t.Errorf("children of core = %v, want the managed release's [org.acme:legacy]", got)
+	}
+	for _, e := range g.Edges() {
+		if e.Meta["constraint_unsatisfied"] == true {
+			t.Errorf("edge %s -> %s marked unsatisfied", e.From, e.To)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package javascript
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/npm"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Homepage             string            `json:"homepage"`
+	Repository           json.RawMessage   `json:"repository"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+// manifestFiles mark a directory as a project.
+var manifestFiles = []string{"package.json"}
+
+// readManifest reads the dependencies of every kind from a project's
+// package.json.
+func readManifest(dir string) (*source.Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	var pj packageJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return nil, fmt.Errorf("package.json: %w", err)
+	}
+
+	m := &source.Manifest{
+		Name:        cmp.Or(strings.ToLower(pj.Name), source.ProjectName(dir)),
+		Version:     pj.Version,
+		HomePage:    pj.Homepage,
+		ProjectURLs: map[string]string{},
+		File:        "package.json",
+	}
+	if repo := repositoryURL(pj.Repository); repo != "" {
+		m.ProjectURLs["repository"] = repo
+	}
+	m.Dependencies = npm.ExtractDeps(pj.Dependencies, pj.OptionalDependencies, pj.PeerDependencies, pj.DevDependencies)
+	return m, nil
+}
+
+// repositoryURL accepts both the string and the {type, url} object form.
+func repositoryURL(raw json.RawMessage) string {
+	var url string
+	if err := json.Unmarshal(raw, &url); err != nil {
+		var obj struct {
+			URL string `json:"url"`
+		}
+		if json.Unmarshal(raw, &obj) != nil {
+			return ""
+		}
+		url = obj.URL
+	}
+	url = strings.TrimPrefix(url, "git+")
+	return strings.TrimSuffix(url, ".git")
+}
@@ -0,0 +1,44 @@
+package javascript
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	pj := `{
+  "name": "web-app",
+  "version": "2.1.0",
+  "repository": {"type": "git", "url": "git+https://github.com/org/web-app.git"},
+  "dependencies": {"react": "^18.2.0", "Lodash": "^4.17.21"},
+  "optionalDependencies": {"fsevents": "^2.3.3"},
+  "devDependencies": {"vitest": "^1.0.0"}
+}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(pj), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "web-app" || m.Version != "2.1.0" {
+		t.Errorf("got %s@%s", m.Name, m.Version)
+	}
+	if !reflect.DeepEqual(m.Dependencies, []source.Dependency{
+		{Name: "fsevents", Constraint: "^2.3.3", Kind: "optional"},
+		{Name: "lodash", Constraint: "^4.17.21", Kind: "runtime"},
+		{Name: "react", Constraint: "^18.2.0", Kind: "runtime"},
+		{Name: "vitest", Constraint: "^1.0.0", Kind: "dev"},
+	}) {
+		t.Errorf("deps = %v", m.Dependencies)
+	}
+	if got := m.ProjectURLs["repository"]; got != "https://github.com/org/web-app" {
+		t.Errorf("repository = %q", got)
+	}
+}
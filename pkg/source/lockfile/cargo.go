@@ -0,0 +1,72 @@
+package lockfile
+
+import (
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+type cargoLock struct {
+	Packages []cargoPackage `toml:"package"`
+}
+
+type cargoPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Dependencies []string `toml:"dependencies"`
+}
+
+// parseCargo reads Cargo.lock. Packages without a source are local crates;
+// a single top-level local crate becomes the root, otherwise the workspace
+// members hang off a synthesized root.
+func parseCargo(data []byte, _ string) (*lockfile, error) {
+	var lock cargoLock
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	lf := &lockfile{manifest: "Cargo.toml"}
+	for _, p := range lock.Packages {
+		pkg := &lockPackage{key: p.Name + "@" + p.Version, name: p.Name, version: p.Version}
+		if git, ok := strings.CutPrefix(p.Source, "git+"); ok {
+			pkg.repository, _, _ = strings.Cut(git, "?")
+			pkg.repository, _, _ = strings.Cut(pkg.repository, "#")
+		}
+		lf.packages = append(lf.packages, pkg)
+	}
+
+	idx := newByName(lf.packages)
+	for i, p := range lock.Packages {
+		pkg := lf.packages[i]
+		for _, dep := range p.Dependencies {
+			// Entries are "name", "name version" or "name version (source)".
+			fields := strings.Fields(dep)
+			if len(fields) == 0 {
+				continue
+			}
+			version := ""
+			if len(fields) > 1 {
+				version = fields[1]
+			}
+			if key, ok := idx.resolve(fields[0], version); ok {
+				pkg.deps = append(pkg.deps, key)
+			}
+		}
+	}
+
+	var members []string
+	for _, key := range parentless(lf.packages) {
+		for i, p := range lock.Packages {
+			if lf.packages[i].key == key && p.Source == "" {
+				members = append(members, key)
+			}
+		}
+	}
+	if len(members) == 1 {
+		lf.root = members[0]
+	} else if len(members) > 1 {
+		lf.direct = members
+	}
+	return lf, nil
+}
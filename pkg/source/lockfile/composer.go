@@ -0,0 +1,48 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type composerLock struct {
+	Packages []composerPackage `json:"packages"`
+}
+
+type composerPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+	Source  struct {
+		URL string `json:"url"`
+	} `json:"source"`
+}
+
+// parseComposer reads composer.lock. Only the "packages" section is used;
+// "packages-dev" holds require-dev packages. Platform requirements such as
+// php or ext-json have no entry and are dropped.
+func parseComposer(data []byte, _ string) (*lockfile, error) {
+	var lock composerLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	lf := &lockfile{manifest: "composer.json"}
+	for _, p := range lock.Packages {
+		name := strings.ToLower(p.Name)
+		pkg := &lockPackage{
+			key:        name,
+			name:       name,
+			version:    p.Version,
+			repository: strings.TrimSuffix(p.Source.URL, ".git"),
+		}
+		for _, dep := range slices.Sorted(maps.Keys(p.Require)) {
+			pkg.deps = append(pkg.deps, strings.ToLower(dep))
+		}
+		lf.packages = append(lf.packages, pkg)
+	}
+	dropUnknown(lf)
+	return lf, nil
+}
@@ -0,0 +1,94 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// parseGemfile reads Gemfile.lock. Specs from the GEM, GIT and PATH
+// sections become packages; the DEPENDENCIES section lists the root's
+// direct dependencies. Platform-specific variants of a gem collapse into
+// one package.
+func parseGemfile(data []byte, _ string) (*lockfile, error) {
+	lf := &lockfile{manifest: "Gemfile", direct: []string{}}
+	var (
+		section, remote string
+		current         *lockPackage
+		pending         = make(map[*lockPackage][]string)
+		seen            = make(map[string]*lockPackage)
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if indent == 0 {
+			section, remote, current = trimmed, "", nil
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			switch {
+			case indent == 2 && strings.HasPrefix(trimmed, "remote:"):
+				remote = strings.TrimSpace(strings.TrimPrefix(trimmed, "remote:"))
+			case indent == 4:
+				name, version := gemSpec(trimmed)
+				if existing, ok := seen[name]; ok {
+					current = existing
+					continue
+				}
+				current = &lockPackage{key: name, name: name, version: stripPlatform(version)}
+				if section == "GIT" {
+					current.repository = strings.TrimSuffix(remote, ".git")
+				}
+				seen[name] = current
+				lf.packages = append(lf.packages, current)
+			case indent == 6 && current != nil:
+				name, _ := gemSpec(trimmed)
+				pending[current] = append(pending[current], name)
+			}
+		case "DEPENDENCIES":
+			if indent == 2 {
+				name, _ := gemSpec(strings.TrimSuffix(trimmed, "!"))
+				lf.direct = append(lf.direct, name)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, p := range lf.packages {
+		for _, dep := range pending[p] {
+			if _, ok := seen[dep]; ok && dep != p.key {
+				p.deps = append(p.deps, dep)
+			}
+		}
+	}
+	return lf, nil
+}
+
+// gemSpec splits "name (version)" or "name (>= 1.0, < 2)".
+func gemSpec(s string) (name, version string) {
+	name, rest, found := strings.Cut(s, " (")
+	if !found {
+		return strings.ToLower(strings.TrimSpace(s)), ""
+	}
+	return strings.ToLower(strings.TrimSpace(name)), strings.TrimSuffix(rest, ")")
+}
+
+// stripPlatform removes platform suffixes such as -x86_64-linux from
+// resolved versions.
+func stripPlatform(version string) string {
+	if i := strings.IndexByte(version, '-'); i > 0 {
+		return version[:i]
+	}
+	return version
+}
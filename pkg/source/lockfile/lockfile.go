@@ -0,0 +1,294 @@
+package lockfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type format struct {
+	name     string
+	purl     string // Package URL type of the locked packages
+	parse    func(data []byte, dir string) (*lockfile, error)
+	skipsDev bool // dev dependencies are left out of the graph
+}
+
+// formats is ordered by detection priority when a directory is given.
+var formats = []format{
+	{"package-lock.json", source.PURLNPM, parseNPM, true},
+	{"npm-shrinkwrap.json", source.PURLNPM, parseNPM, true},
+	{"poetry.lock", source.PURLPyPI, parsePoetry, true},
+	{"uv.lock", source.PURLPyPI, parseUV, true},
+	{"Cargo.lock", source.PURLCargo, parseCargo, false},
+	{"Gemfile.lock", source.PURLGem, parseGemfile, false},
+	{"composer.lock", source.PURLComposer, parseComposer, true},
+}
+
+// lockfile is the format-independent view of a parsed lockfile. Packages
+// are identified by format-specific keys (install paths, name@version, ...)
+// until ids are assigned.
+type lockfile struct {
+	root     string   // key of the root package; empty to synthesize one
+	direct   []string // dependencies of a synthesized root; nil means every package nothing depends on
+	manifest string   // manifest file name handed to metadata providers
+	packages []*lockPackage
+}
+
+type lockPackage struct {
+	key        string
+	name       string
+	version    string
+	repository string
+	deps       []string
+}
+
+// Parser implements source.Parser for lockfiles. The single "package"
+// argument is the path to a lockfile or to a directory containing one.
+// The resulting graph contains exactly the pinned versions in the lockfile
+// and never touches a registry; metadata providers still run when
+// configured. Unset depth and node limits are lifted since the lockfile
+// already bounds the graph; limits the caller sets are kept.
+//
+// Dev dependencies are left out of npm, Poetry, uv and Composer lockfiles
+// whatever the IncludeKinds; Cargo.lock and Gemfile.lock do not mark them,
+// so they are always included.
+type Parser struct{}
+
+func NewParser() *Parser { return &Parser{} }
+
+func (p *Parser) Parse(ctx context.Context, paths []string, opts source.Options) (*dag.DAG, error) {
+	if len(paths) != 1 {
+		return nil, fmt.Errorf("expected one lockfile, got %d", len(paths))
+	}
+	lf, err := Load(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = len(lf.packages)
+	}
+	if opts.MaxNodes == 0 {
+		opts.MaxNodes = len(lf.packages)
+	}
+	if lf.format.skipsDev && slices.Contains(opts.IncludeKinds, integrations.KindDev) && opts.Logger != nil {
+		opts.Logger("dev dependencies are not read from %s; the graph leaves them out", lf.format.name)
+	}
+	opts.PURLType = lf.purl
+	return source.Parse(ctx, source.Roots([]string{lf.rootID}), opts, lf.fetch)
+}
+
+// Detect returns the lockfile path for path, which may be a lockfile or a
+// directory containing one of the supported lockfiles.
+func Detect(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		if _, ok := formatFor(path); !ok {
+			return "", fmt.Errorf("unsupported lockfile %s", filepath.Base(path))
+		}
+		return path, nil
+	}
+	for _, f := range formats {
+		candidate := filepath.Join(path, f.name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no supported lockfile found in %s", path)
+}
+
+func formatFor(path string) (format, bool) {
+	base := filepath.Base(path)
+	for _, f := range formats {
+		if f.name == base {
+			return f, true
+		}
+	}
+	return format{}, false
+}
+
+// Graph is a loaded lockfile, indexed by node id.
+type Graph struct {
+	rootID   string
+	manifest string
+	purl     string
+	format   format
+	packages map[string]*lockPackage
+}
+
+func Load(path string) (*Graph, error) {
+	path, err := Detect(path)
+	if err != nil {
+		return nil, err
+	}
+	f, _ := formatFor(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(abs)
+
+	lf, err := f.parse(data, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.name, err)
+	}
+	g := lf.index(filepath.Base(dir))
+	g.purl = f.purl
+	g.format = f
+	return g, nil
+}
+
+// index assigns node ids and synthesizes a root if the lockfile has none.
+// A package keeps its bare name as id unless the lockfile pins several
+// versions of it, in which case every version becomes name@version.
+func (lf *lockfile) index(project string) *Graph {
+	versions := make(map[string]map[string]bool)
+	for _, p := range lf.packages {
+		if versions[p.name] == nil {
+			versions[p.name] = make(map[string]bool)
+		}
+		versions[p.name][p.version] = true
+	}
+
+	ids := make(map[string]string, len(lf.packages))
+	for _, p := range lf.packages {
+		if len(versions[p.name]) > 1 {
+			ids[p.key] = p.name + "@" + p.version
+		} else {
+			ids[p.key] = p.name
+		}
+	}
+
+	g := &Graph{manifest: lf.manifest, packages: make(map[string]*lockPackage, len(lf.packages))}
+	for _, p := range lf.packages {
+		id := ids[p.key]
+		pkg, exists := g.packages[id]
+		if !exists {
+			pkg = &lockPackage{key: id, name: p.name, version: p.version, repository: p.repository}
+			g.packages[id] = pkg
+		}
+		for _, dep := range p.deps {
+			if depID, ok := ids[dep]; ok && depID != id && !slices.Contains(pkg.deps, depID) {
+				pkg.deps = append(pkg.deps, depID)
+			}
+		}
+	}
+
+	if lf.root != "" {
+		if id, ok := ids[lf.root]; ok {
+			g.rootID = id
+			return g
+		}
+	}
+
+	root := &lockPackage{key: project, name: project}
+	direct := lf.direct
+	if direct == nil {
+		direct = parentless(lf.packages)
+	}
+	for _, dep := range direct {
+		if depID, ok := ids[dep]; ok && !slices.Contains(root.deps, depID) {
+			root.deps = append(root.deps, depID)
+		}
+	}
+	g.rootID = root.key
+	g.packages[root.key] = root
+	return g
+}
+
+func parentless(packages []*lockPackage) []string {
+	hasParent := make(map[string]bool)
+	for _, p := range packages {
+		for _, d := range p.deps {
+			if d != p.key {
+				hasParent[d] = true
+			}
+		}
+	}
+	var roots []string
+	for _, p := range packages {
+		if !hasParent[p.key] {
+			roots = append(roots, p.key)
+		}
+	}
+	return roots
+}
+
+func (g *Graph) fetch(_ context.Context, dep source.Dependency, _ bool) (*packageInfo, error) {
+	p, ok := g.packages[dep.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s not in lockfile", integrations.ErrNotFound, dep.Name)
+	}
+	return &packageInfo{lockPackage: p, manifest: g.manifest}, nil
+}
+
+type packageInfo struct {
+	*lockPackage
+	manifest string
+}
+
+func (pi *packageInfo) GetName() string    { return pi.key }
+func (pi *packageInfo) GetVersion() string { return pi.version }
+func (pi *packageInfo) GetDependencies() []source.Dependency {
+	deps := make([]source.Dependency, len(pi.deps))
+	for i, id := range pi.deps {
+		deps[i] = source.Dependency{Name: id}
+	}
+	return deps
+}
+
+func (pi *packageInfo) ToMetadata() map[string]any {
+	m := map[string]any{}
+	if pi.version != "" {
+		m["version"] = pi.version
+	}
+	return m
+}
+
+func (pi *packageInfo) ToRepoInfo() *source.RepoInfo {
+	urls := make(map[string]string, 1)
+	if pi.repository != "" {
+		urls["repository"] = pi.repository
+	}
+	return &source.RepoInfo{
+		Name:         pi.name,
+		Version:      pi.version,
+		ProjectURLs:  urls,
+		ManifestFile: pi.manifest,
+	}
+}
+
+// byName resolves name-only dependency references. When a lockfile pins a
+// name more than once the first entry wins, unless a version is given.
+type byName map[string][]*lockPackage
+
+func newByName(packages []*lockPackage) byName {
+	idx := make(byName)
+	for _, p := range packages {
+		idx[p.name] = append(idx[p.name], p)
+	}
+	return idx
+}
+
+func (idx byName) resolve(name, version string) (string, bool) {
+	candidates := idx[name]
+	for _, p := range candidates {
+		if version == "" || p.version == version {
+			return p.key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,526 @@
+package lockfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+const npmFixture = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "app",
+      "version": "1.0.0",
+      "dependencies": {"express": "^4.18.0", "ws": "^8.0.0"},
+      "devDependencies": {"jest": "^29.0.0"}
+    },
+    "node_modules/express": {
+      "version": "4.18.2",
+      "dependencies": {"debug": "2.6.9", "ms": "^2.0.0"}
+    },
+    "node_modules/debug": {
+      "version": "2.6.9",
+      "dependencies": {"ms": "2.0.0"}
+    },
+    "node_modules/debug/node_modules/ms": {"version": "2.0.0"},
+    "node_modules/ms": {"version": "2.1.3"},
+    "node_modules/ws": {"resolved": "packages/ws", "link": true},
+    "packages/ws": {"name": "ws", "version": "8.0.0"},
+    "node_modules/jest": {"version": "29.7.0", "dev": true}
+  }
+}`
+
+const poetryFixture = `
+[[package]]
+name = "FastAPI"
+version = "0.110.0"
+
+[package.dependencies]
+pydantic = ">=1.7.4"
+starlette = ">=0.36.3,<0.37.0"
+typing-extensions = {version = ">=4.8.0", markers = "python_version < \"3.9\""}
+
+[[package]]
+name = "pydantic"
+version = "2.6.1"
+
+[[package]]
+name = "starlette"
+version = "0.36.3"
+
+[package.dependencies]
+anyio = ">=3.4.0,<5"
+
+[[package]]
+name = "anyio"
+version = "4.2.0"
+
+[[package]]
+name = "pytest"
+version = "8.0.0"
+category = "dev"
+`
+
+// poetryModernFixture is a lockfile from Poetry 1.5 or later, which no
+// longer marks dev packages.
+const poetryModernFixture = `
+[[package]]
+name = "fastapi"
+version = "0.110.0"
+
+[package.dependencies]
+starlette = ">=0.36.3,<0.37.0"
+
+[[package]]
+name = "starlette"
+version = "0.36.3"
+
+[[package]]
+name = "pytest"
+version = "8.0.0"
+
+[package.dependencies]
+pluggy = ">=1.3.0,<2.0"
+
+[[package]]
+name = "pluggy"
+version = "1.4.0"
+
+[[package]]
+name = "mypy"
+version = "1.8.0"
+`
+
+const uvFixture = `
+version = 1
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { virtual = "." }
+dependencies = [
+    { name = "httpx" },
+]
+
+[package.dev-dependencies]
+dev = [
+    { name = "pytest" },
+]
+
+[[package]]
+name = "httpx"
+version = "0.27.0"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "anyio" },
+    { name = "idna", version = "3.6" },
+]
+
+[[package]]
+name = "anyio"
+version = "4.2.0"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "idna", version = "3.7" },
+]
+
+[[package]]
+name = "idna"
+version = "3.6"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "idna"
+version = "3.7"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pytest"
+version = "8.0.0"
+source = { registry = "https://pypi.org/simple" }
+`
+
+const cargoFixture = `
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+ "syn 2.0.48",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.195"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "syn 1.0.109",
+]
+
+[[package]]
+name = "syn"
+version = "1.0.109"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "syn"
+version = "2.0.48"
+source = "git+https://github.com/dtolnay/syn?branch=master#abc123"
+`
+
+const gemfileFixture = `GIT
+  remote: https://github.com/rails/rails.git
+  revision: abc123
+  specs:
+    activesupport (7.1.3)
+      concurrent-ruby (~> 1.0, >= 1.0.2)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    concurrent-ruby (1.2.3)
+    nokogiri (1.16.0-arm64-darwin)
+      racc (~> 1.4)
+    nokogiri (1.16.0-x86_64-linux)
+      racc (~> 1.4)
+    racc (1.7.3)
+    rspec (3.13.0)
+
+PLATFORMS
+  arm64-darwin
+  x86_64-linux
+
+DEPENDENCIES
+  activesupport!
+  nokogiri (~> 1.16)
+
+BUNDLED WITH
+   2.5.3
+`
+
+const composerFixture = `{
+  "packages": [
+    {
+      "name": "monolog/monolog",
+      "version": "3.5.0",
+      "source": {"type": "git", "url": "https://github.com/Seldaek/monolog.git"},
+      "require": {"php": ">=8.1", "psr/log": "^2.0 || ^3.0"}
+    },
+    {"name": "psr/log", "version": "3.0.0", "require": {"php": ">=8.0.0"}}
+  ],
+  "packages-dev": [
+    {"name": "phpunit/phpunit", "version": "10.5.0"}
+  ]
+}`
+
+func writeLockfile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func parse(t *testing.T, path string) *dag.DAG {
+	t.Helper()
+	g, err := NewParser().Parse(context.Background(), []string{path}, source.Options{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return g
+}
+
+func children(g *dag.DAG, id string) []string {
+	c := slices.Clone(g.Children(id))
+	slices.Sort(c)
+	return c
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file     string
+		content  string
+		root     string
+		edges    map[string][]string
+		versions map[string]string
+		absent   []string
+	}{
+		{
+			file:    "package-lock.json",
+			content: npmFixture,
+			root:    "app",
+			edges: map[string][]string{
+				"app":      {"express", "ws"},
+				"express":  {"debug", "ms@2.1.3"},
+				"debug":    {"ms@2.0.0"},
+				"ms@2.1.3": nil,
+				"ms@2.0.0": nil,
+				"ws":       nil,
+			},
+			versions: map[string]string{"express": "4.18.2", "ms@2.0.0": "2.0.0", "ws": "8.0.0"},
+			absent:   []string{"jest"},
+		},
+		{
+			file:    "poetry.lock",
+			content: poetryFixture,
+			root:    "project",
+			edges: map[string][]string{
+				"project":   {"fastapi"},
+				"fastapi":   {"pydantic", "starlette"},
+				"starlette": {"anyio"},
+			},
+			versions: map[string]string{"fastapi": "0.110.0", "anyio": "4.2.0"},
+			absent:   []string{"pytest", "typing-extensions"},
+		},
+		{
+			file:    "uv.lock",
+			content: uvFixture,
+			root:    "app",
+			edges: map[string][]string{
+				"app":   {"httpx"},
+				"httpx": {"anyio", "idna@3.6"},
+				"anyio": {"idna@3.7"},
+			},
+			versions: map[string]string{"app": "0.1.0", "idna@3.7": "3.7"},
+			absent:   []string{"pytest"},
+		},
+		{
+			file:    "Cargo.lock",
+			content: cargoFixture,
+			root:    "app",
+			edges: map[string][]string{
+				"app":   {"serde", "syn@2.0.48"},
+				"serde": {"syn@1.0.109"},
+			},
+			versions: map[string]string{"serde": "1.0.195", "syn@2.0.48": "2.0.48"},
+		},
+		{
+			file:    "Gemfile.lock",
+			content: gemfileFixture,
+			root:    "project",
+			edges: map[string][]string{
+				"project":       {"activesupport", "nokogiri"},
+				"activesupport": {"concurrent-ruby"},
+				"nokogiri":      {"racc"},
+			},
+			versions: map[string]string{"nokogiri": "1.16.0", "racc": "1.7.3"},
+			absent:   []string{"rspec"},
+		},
+		{
+			file:    "composer.lock",
+			content: composerFixture,
+			root:    "project",
+			edges: map[string][]string{
+				"project":         {"monolog/monolog"},
+				"monolog/monolog": {"psr/log"},
+			},
+			versions: map[string]string{"monolog/monolog": "3.5.0"},
+			absent:   []string{"php", "phpunit/phpunit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			g := parse(t, writeLockfile(t, tt.file, tt.content))
+
+			if _, ok := g.Node(tt.root); !ok {
+				t.Fatalf("root %q missing", tt.root)
+			}
+			if n := len(g.Parents(tt.root)); n != 0 {
+				t.Errorf("root has %d parents", n)
+			}
+			for id, want := range tt.edges {
+				if got := children(g, id); !slices.Equal(got, want) {
+					t.Errorf("children(%s) = %v, want %v", id, got, want)
+				}
+			}
+			for id, want := range tt.versions {
+				n, ok := g.Node(id)
+				if !ok {
+					t.Errorf("node %s missing", id)
+					continue
+				}
+				if got := n.Meta["version"]; got != want {
+					t.Errorf("%s version = %v, want %s", id, got, want)
+				}
+			}
+			for _, id := range tt.absent {
+				if _, ok := g.Node(id); ok {
+					t.Errorf("node %s should not be in the graph", id)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePoetryGroups(t *testing.T) {
+	tests := []struct {
+		name      string
+		lock      string
+		pyproject string
+	}{
+		{
+			name: "poetry dependencies",
+			lock: poetryModernFixture,
+			pyproject: `
+[tool.poetry.dependencies]
+python = "^3.11"
+FastAPI = "^0.110"
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+mypy = "^1.8"
+`,
+		},
+		{
+			name: "project dependencies",
+			lock: poetryModernFixture,
+			pyproject: `
+[project]
+name = "app"
+dependencies = ["fastapi[standard] (>=0.110); python_version >= '3.8'"]
+
+[tool.poetry.group.test.dependencies]
+pytest = "^8.0"
+`,
+		},
+		{
+			name: "groups",
+			lock: strings.NewReplacer(
+				`version = "0.110.0"`, `version = "0.110.0"`+"\ngroups = [\"main\"]",
+				`version = "0.36.3"`, `version = "0.36.3"`+"\ngroups = [\"main\"]",
+				`version = "8.0.0"`, `version = "8.0.0"`+"\ngroups = [\"dev\"]",
+				`version = "1.4.0"`, `version = "1.4.0"`+"\ngroups = [\"dev\"]",
+				`version = "1.8.0"`, `version = "1.8.0"`+"\ngroups = [\"dev\"]",
+			).Replace(poetryModernFixture),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeLockfile(t, "poetry.lock", tt.lock)
+			if tt.pyproject != "" {
+				if err := os.WriteFile(filepath.Join(filepath.Dir(path), "pyproject.toml"), []byte(tt.pyproject), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			g := parse(t, path)
+
+			if got := children(g, "project"); !slices.Equal(got, []string{"fastapi"}) {
+				t.Errorf("children(project) = %v, want [fastapi]", got)
+			}
+			for _, id := range []string{"pytest", "pluggy", "mypy"} {
+				if _, ok := g.Node(id); ok {
+					t.Errorf("dev package %s should not be in the graph", id)
+				}
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	path := writeLockfile(t, "Cargo.lock", cargoFixture)
+
+	got, err := Detect(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Detect(dir): %v", err)
+	}
+	if got != path {
+		t.Errorf("Detect(dir) = %s, want %s", got, path)
+	}
+
+	if _, err := Detect(t.TempDir()); err == nil {
+		t.Error("expected error for directory without lockfile")
+	}
+	if _, err := Detect(writeLockfile(t, "yarn.lock", "")); err == nil {
+		t.Error("expected error for unsupported lockfile")
+	}
+}
+
+func TestParseNPMRejectsV1(t *testing.T) {
+	path := writeLockfile(t, "package-lock.json", `{"lockfileVersion": 1, "dependencies": {}}`)
+	if _, err := NewParser().Parse(context.Background(), []string{path}, source.Options{}); err == nil {
+		t.Error("expected error for lockfileVersion 1")
+	}
+}
+
+type recordingProvider struct {
+	mu    sync.Mutex
+	repos []*source.RepoInfo
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) Enrich(_ context.Context, repo *source.RepoInfo, _ bool) (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.repos = append(p.repos, repo)
+	return map[string]any{"enriched": true}, nil
+}
+
+func TestParseEnrich(t *testing.T) {
+	provider := &recordingProvider{}
+	path := writeLockfile(t, "Cargo.lock", cargoFixture)
+	g, err := NewParser().Parse(context.Background(), []string{path}, source.Options{
+		MetadataProviders: []source.MetadataProvider{provider},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := g.Node("syn@2.0.48")
+	if n.Meta["enriched"] != true {
+		t.Errorf("meta = %v, want enriched", n.Meta)
+	}
+	for _, repo := range provider.repos {
+		if repo.Name == "syn" && repo.Version == "2.0.48" {
+			if got := repo.ProjectURLs["repository"]; got != "https://github.com/dtolnay/syn" {
+				t.Errorf("repository = %q", got)
+			}
+			return
+		}
+	}
+	t.Error("provider not called for syn 2.0.48")
+}
+
+func TestParseLimits(t *testing.T) {
+	path := writeLockfile(t, "package-lock.json", npmFixture)
+
+	var logged []string
+	g, err := NewParser().Parse(context.Background(), []string{path}, source.Options{
+		MaxDepth:     1,
+		IncludeKinds: []string{integrations.KindRuntime, integrations.KindDev},
+		Logger:       func(msg string, args ...any) { logged = append(logged, fmt.Sprintf(msg, args...)) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Node("debug"); ok {
+		t.Error("debug is below --max-depth 1 but was crawled")
+	}
+	if _, ok := g.Node("express"); !ok {
+		t.Error("express missing")
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "dev dependencies") {
+		t.Errorf("logged %q, want a warning about dev dependencies", logged)
+	}
+}
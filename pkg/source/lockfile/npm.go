@@ -0,0 +1,114 @@
+package lockfile
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type npmLock struct {
+	Name            string                `json:"name"`
+	LockfileVersion int                   `json:"lockfileVersion"`
+	Packages        map[string]npmPackage `json:"packages"`
+}
+
+type npmPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// parseNPM reads the "packages" section of lockfile versions 2 and 3.
+// Entries are keyed by install path; dependencies are resolved with the
+// node_modules lookup algorithm so nested duplicates stay distinct.
+func parseNPM(data []byte, dir string) (*lockfile, error) {
+	var lock npmLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	if lock.LockfileVersion < 2 || lock.Packages == nil {
+		return nil, fmt.Errorf("lockfileVersion %d is not supported, regenerate with npm 7 or later", lock.LockfileVersion)
+	}
+
+	lf := &lockfile{root: npmKey(""), manifest: "package.json"}
+	for key, p := range lock.Packages {
+		if p.Link || (p.Dev && key != "") {
+			continue
+		}
+		name := npmName(key, p)
+		if key == "" && name == "" {
+			name = cmp.Or(lock.Name, filepath.Base(dir))
+		}
+		pkg := &lockPackage{key: npmKey(key), name: name, version: p.Version}
+		for _, deps := range []map[string]string{p.Dependencies, p.OptionalDependencies, p.PeerDependencies} {
+			for _, dep := range slices.Sorted(maps.Keys(deps)) {
+				if target, ok := resolveNodeModule(lock.Packages, key, dep); ok && !lock.Packages[target].Dev {
+					pkg.deps = append(pkg.deps, npmKey(target))
+				}
+			}
+		}
+		lf.packages = append(lf.packages, pkg)
+	}
+	return lf, nil
+}
+
+// npmKey maps the root entry, keyed by the empty path, to a non-empty key.
+func npmKey(key string) string {
+	if key == "" {
+		return "."
+	}
+	return key
+}
+
+// npmName returns the package name for an install path such as
+// node_modules/a/node_modules/@scope/b, or the declared name for
+// workspace packages and aliases.
+func npmName(key string, p npmPackage) string {
+	if i := strings.LastIndex(key, "node_modules/"); i >= 0 {
+		return key[i+len("node_modules/"):]
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	if key != "" {
+		return path.Base(key)
+	}
+	return ""
+}
+
+// resolveNodeModule finds the install path that a require of name from
+// the package at from would load, walking up the node_modules hierarchy.
+func resolveNodeModule(packages map[string]npmPackage, from, name string) (string, bool) {
+	dir := from
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/node_modules/" + name
+		}
+		if p, ok := packages[candidate]; ok {
+			if p.Link {
+				_, ok := packages[p.Resolved]
+				return p.Resolved, ok
+			}
+			return candidate, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		if i := strings.LastIndex(dir, "node_modules/"); i > 0 {
+			dir = strings.TrimSuffix(dir[:i], "/")
+		} else {
+			dir = ""
+		}
+	}
+}
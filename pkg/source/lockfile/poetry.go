@@ -0,0 +1,130 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
+)
+
+type poetryLock struct {
+	Packages []poetryPackage `toml:"package"`
+}
+
+type poetryPackage struct {
+	Name         string         `toml:"name"`
+	Version      string         `toml:"version"`
+	Category     string         `toml:"category"`
+	Groups       []string       `toml:"groups"`
+	Dependencies map[string]any `toml:"dependencies"`
+	Source       struct {
+		Type string `toml:"type"`
+		URL  string `toml:"url"`
+	} `toml:"source"`
+}
+
+// poetryProject is the part of pyproject.toml that names the runtime
+// dependencies.
+type poetryProject struct {
+	Project struct {
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// parsePoetry reads poetry.lock. The lockfile does not record the project
+// itself, so the root is synthesized from the runtime dependencies in the
+// pyproject.toml next to it, or from the packages nothing depends on when
+// there is none. Packages outside the main group are skipped, as are those
+// in the legacy "dev" category of older lockfiles.
+func parsePoetry(data []byte, dir string) (*lockfile, error) {
+	var lock poetryLock
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	lf := &lockfile{manifest: "pyproject.toml"}
+	direct, err := poetryDirect(dir)
+	if err != nil {
+		return nil, err
+	}
+	lf.direct = direct
+	for _, p := range lock.Packages {
+		if p.Category == "dev" || len(p.Groups) > 0 && !slices.Contains(p.Groups, "main") {
+			continue
+		}
+		name := pypi.NormalizeName(p.Name)
+		pkg := &lockPackage{key: name, name: name, version: p.Version}
+		if p.Source.Type == "git" {
+			pkg.repository = p.Source.URL
+		}
+		for _, dep := range slices.Sorted(maps.Keys(p.Dependencies)) {
+			pkg.deps = append(pkg.deps, pypi.NormalizeName(dep))
+		}
+		lf.packages = append(lf.packages, pkg)
+	}
+	dropUnknown(lf)
+	return lf, nil
+}
+
+// poetryDirect returns the runtime dependencies declared in dir's
+// pyproject.toml under [project.dependencies] or [tool.poetry.dependencies].
+// Dev and other groups are not read. It returns nil when there is no
+// pyproject.toml or it declares neither table.
+func poetryDirect(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pp poetryProject
+	if err := toml.Unmarshal(data, &pp); err != nil {
+		return nil, fmt.Errorf("pyproject.toml: %w", err)
+	}
+	poetry := pp.Tool.Poetry.Dependencies
+	if pp.Project.Dependencies == nil && poetry == nil {
+		return nil, nil
+	}
+
+	// The lockfile covers every platform, so markers are not evaluated.
+	specs := make([]string, len(pp.Project.Dependencies))
+	for i, req := range pp.Project.Dependencies {
+		specs[i], _, _ = strings.Cut(req, ";")
+	}
+	direct := []string{}
+	for _, dep := range pypi.ExtractDeps(specs, pypi.DefaultEnvironment) {
+		direct = append(direct, dep.Name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(poetry)) {
+		if name = pypi.NormalizeName(name); name != "python" && !slices.Contains(direct, name) {
+			direct = append(direct, name)
+		}
+	}
+	return direct, nil
+}
+
+// dropUnknown removes dependency keys that have no package entry, such as
+// dependencies excluded by markers or skipped dev packages, so they do not
+// affect root detection.
+func dropUnknown(lf *lockfile) {
+	known := make(map[string]bool, len(lf.packages))
+	for _, p := range lf.packages {
+		known[p.key] = true
+	}
+	for _, p := range lf.packages {
+		p.deps = slices.DeleteFunc(p.deps, func(d string) bool { return !known[d] })
+	}
+}
@@ -0,0 +1,66 @@
+package lockfile
+
+import (
+	"github.com/BurntSushi/toml"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
+)
+
+type uvLock struct {
+	Packages []uvPackage `toml:"package"`
+}
+
+type uvPackage struct {
+	Name         string         `toml:"name"`
+	Version      string         `toml:"version"`
+	Source       map[string]any `toml:"source"`
+	Dependencies []uvDependency `toml:"dependencies"`
+}
+
+type uvDependency struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+}
+
+// parseUV reads uv.lock. The project is the package whose source is the
+// lockfile's own directory; dev-dependencies and optional-dependencies
+// are not followed.
+func parseUV(data []byte, _ string) (*lockfile, error) {
+	var lock uvLock
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	lf := &lockfile{manifest: "pyproject.toml"}
+	for _, p := range lock.Packages {
+		name := pypi.NormalizeName(p.Name)
+		pkg := &lockPackage{key: name + "@" + p.Version, name: name, version: p.Version}
+		if git, ok := p.Source["git"].(string); ok {
+			pkg.repository = git
+		}
+		if isProjectSource(p.Source) {
+			lf.root = pkg.key
+		}
+		lf.packages = append(lf.packages, pkg)
+	}
+
+	idx := newByName(lf.packages)
+	for i, p := range lock.Packages {
+		pkg := lf.packages[i]
+		for _, dep := range p.Dependencies {
+			if key, ok := idx.resolve(pypi.NormalizeName(dep.Name), dep.Version); ok {
+				pkg.deps = append(pkg.deps, key)
+			}
+		}
+	}
+	return lf, nil
+}
+
+func isProjectSource(src map[string]any) bool {
+	for _, kind := range []string{"virtual", "editable"} {
+		if path, ok := src[kind].(string); ok && path == "." {
+			return true
+		}
+	}
+	return false
+}
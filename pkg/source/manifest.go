@@ -0,0 +1,115 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+// Manifest is a local project read from its manifest file. It stands in
+// for the root package when the project is not published to a registry.
+type Manifest struct {
+	Name         string
+	Version      string
+	Dependencies []Dependency
+	ProjectURLs  map[string]string
+	HomePage     string
+	File         string
+}
+
+func (m *Manifest) GetName() string               { return m.Name }
+func (m *Manifest) GetVersion() string            { return m.Version }
+func (m *Manifest) GetDependencies() []Dependency { return m.Dependencies }
+
+func (m *Manifest) ToMetadata() map[string]any {
+	meta := map[string]any{}
+	if m.Version != "" {
+		meta["version"] = m.Version
+	}
+	return meta
+}
+
+func (m *Manifest) ToRepoInfo() *RepoInfo {
+	return &RepoInfo{
+		Name:         m.Name,
+		Version:      m.Version,
+		ProjectURLs:  m.ProjectURLs,
+		HomePage:     m.HomePage,
+		ManifestFile: m.File,
+	}
+}
+
+// IsProjectDir reports whether pkg names a local project rather than a
+// registry package: a path such as ".", "./api" or "/src/api" to a
+// directory holding one of the manifest files. A bare name is always a
+// package, even if a directory of that name exists. A path to a directory
+// holding none of the files is an error.
+func IsProjectDir(pkg string, files ...string) (bool, error) {
+	if !isPath(pkg) {
+		return false, nil
+	}
+	info, err := os.Stat(pkg)
+	if err != nil || !info.IsDir() {
+		return false, nil
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(pkg, f)); err == nil {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("%s is not a project: it has no %s", pkg, strings.Join(files, " or "))
+}
+
+// isPath reports whether pkg is written as a file path rather than a
+// package name.
+func isPath(pkg string) bool {
+	return pkg == "." || pkg == ".." || filepath.IsAbs(pkg) ||
+		strings.ContainsRune(pkg, '/') || strings.ContainsRune(pkg, filepath.Separator)
+}
+
+// ProjectName is the fallback root name for a project whose manifest does
+// not declare one: the directory's base name.
+func ProjectName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
+
+// ParseRoots is Parse for roots that may name local projects: a root that
+// IsProjectDir for manifestFiles is read with readManifest, and the
+// manifest provides that root and its direct dependencies. Everything
+// below is crawled from the registry through fetch.
+func ParseRoots[T PackageInfo](ctx context.Context, roots []Dependency, opts Options, manifestFiles []string, readManifest func(dir string) (*Manifest, error), fetch fetchFunc[T]) (*dag.DAG, error) {
+	manifests := make(map[string]*Manifest)
+	roots = slices.Clone(roots)
+	for i, r := range roots {
+		project, err := IsProjectDir(r.Name, manifestFiles...)
+		if err != nil {
+			return nil, err
+		}
+		if !project {
+			continue
+		}
+		m, err := readManifest(r.Name)
+		if err != nil {
+			return nil, err
+		}
+		manifests[m.Name] = m
+		roots[i].Name = m.Name
+	}
+	if len(manifests) == 0 {
+		return Parse(ctx, roots, opts, fetch)
+	}
+	return Parse(ctx, roots, opts, func(ctx context.Context, dep Dependency, refresh bool) (PackageInfo, error) {
+		if m, ok := manifests[dep.Name]; ok {
+			return m, nil
+		}
+		return fetch(ctx, dep, refresh)
+	})
+}
@@ -0,0 +1,105 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+)
+
+type fakeInfo struct {
+	name    string
+	deps    []Dependency
+	license string
+}
+
+func (f *fakeInfo) GetName() string               { return f.name }
+func (f *fakeInfo) GetVersion() string            { return "1.0.0" }
+func (f *fakeInfo) GetDependencies() []Dependency { return f.deps }
+func (f *fakeInfo) ToRepoInfo() *RepoInfo         { return &RepoInfo{Name: f.name} }
+
+func (f *fakeInfo) ToMetadata() map[string]any {
+	m := map[string]any{"version": "1.0.0"}
+	if f.license != "" {
+		m["license"] = f.license
+	}
+	return m
+}
+
+func TestParseRoots_Project(t *testing.T) {
+	registry := map[string][]Dependency{
+		"requests": {{Name: "urllib3", Constraint: ">=1.21.1,<3"}, {Name: "idna"}},
+		"urllib3":  nil,
+		"idna":     nil,
+	}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		deps, ok := registry[dep.Name]
+		if !ok {
+			return nil, integrations.ErrNotFound
+		}
+		return &fakeInfo{name: dep.Name, deps: deps}, nil
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "manifest"), nil, 0o644)
+	readManifest := func(string) (*Manifest, error) {
+		return &Manifest{Name: "myservice", Version: "0.3.0", Dependencies: []Dependency{{Name: "requests"}, {Name: "idna"}}}, nil
+	}
+	g, err := ParseRoots(context.Background(), Roots([]string{dir}), Options{}, []string{"manifest"}, readManifest, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.NodeCount() != 4 {
+		t.Errorf("NodeCount = %d, want 4", g.NodeCount())
+	}
+	root, ok := g.Node("myservice")
+	if !ok {
+		t.Fatal("root node missing")
+	}
+	if root.Meta["version"] != "0.3.0" {
+		t.Errorf("root version = %v", root.Meta["version"])
+	}
+	children := slices.Sorted(slices.Values(g.Children("myservice")))
+	if !slices.Equal(children, []string{"idna", "requests"}) {
+		t.Errorf("root children = %v", children)
+	}
+	if got := g.Children("requests"); len(got) != 2 {
+		t.Errorf("requests children = %v", got)
+	}
+	for _, e := range g.Edges() {
+		if e.From == "requests" && e.To == "urllib3" && e.Meta["constraint"] != ">=1.21.1,<3" {
+			t.Errorf("edge meta = %v, want constraint", e.Meta)
+		}
+	}
+}
+
+func TestIsProjectDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "requests"), 0o755)
+	os.WriteFile(filepath.Join(dir, "requests", "pyproject.toml"), nil, 0o644)
+	os.MkdirAll(filepath.Join(dir, "docs"), 0o755)
+	t.Chdir(dir)
+
+	tests := []struct {
+		pkg     string
+		project bool
+		err     bool
+	}{
+		{"requests", false, false}, // a bare name is a package, even with a directory of that name
+		{"./requests", true, false},
+		{filepath.Join(dir, "requests"), true, false},
+		{"./docs", false, true},
+		{"@babel/core", false, false},
+		{".", false, true},
+	}
+	for _, tt := range tests {
+		project, err := IsProjectDir(tt.pkg, "pyproject.toml", "requirements.txt")
+		if project != tt.project || (err != nil) != tt.err {
+			t.Errorf("IsProjectDir(%q) = %v, %v; want %v, error %v", tt.pkg, project, err, tt.project, tt.err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/gitea"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+// Gitea enriches packages hosted on Gitea-compatible forges: Codeberg and
+// the self-hosted Gitea or Forgejo instance of the gitea endpoint, if one
+// is set.
+type Gitea struct {
+	clients []*gitea.Client
+}
+
+func NewGitea(token string, cacheTTL time.Duration) (*Gitea, error) {
+	clients, err := gitea.NewClients(token, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Gitea{clients}, nil
+}
+
+func (g *Gitea) Name() string { return "gitea" }
+
+func (g *Gitea) Enrich(ctx context.Context, repo *source.RepoInfo, refresh bool) (map[string]any, error) {
+	for _, c := range g.clients {
+		owner, name, ok := c.ExtractURL(repo.ProjectURLs, repo.HomePage)
+		if !ok {
+			continue
+		}
+		m, err := c.Fetch(ctx, owner, name, refresh)
+		if err != nil {
+			return nil, err
+		}
+		return repoMetadata(m), nil
+	}
+	return nil, nil
+}
@@ -23,7 +23,7 @@ func NewGitHub(token string, cacheTTL time.Duration) (*GitHub, error) {
 func (g *GitHub) Name() string { return "github" }
 
 func (g *GitHub) Enrich(ctx context.Context, repo *source.RepoInfo, refresh bool) (map[string]any, error) {
-	owner, name, ok := github.ExtractURL(repo.ProjectURLs, repo.HomePage)
+	owner, name, ok := g.client.ExtractURL(repo.ProjectURLs, repo.HomePage)
 
 	if !ok && repo.ManifestFile != "" {
 		owner, name, ok = g.client.SearchPackageRepo(ctx, repo.Name, repo.ManifestFile)
@@ -38,32 +38,5 @@ func (g *GitHub) Enrich(ctx context.Context, repo *source.RepoInfo, refresh bool
 		return nil, err
 	}
 
-	result := map[string]any{
-		RepoURL:      m.RepoURL,
-		RepoOwner:    m.Owner,
-		RepoStars:    m.Stars,
-		RepoArchived: m.Archived,
-	}
-
-	if m.Language != "" {
-		result[RepoLanguage] = m.Language
-	}
-	if len(m.Topics) > 0 {
-		result[RepoTopics] = m.Topics
-	}
-	if m.LastCommitAt != nil {
-		result[RepoLastCommit] = m.LastCommitAt.Format("2006-01-02")
-	}
-	if m.LastReleaseAt != nil {
-		result[RepoLastRelease] = m.LastReleaseAt.Format("2006-01-02")
-	}
-	if len(m.Contributors) > 0 {
-		maintainers := make([]string, len(m.Contributors))
-		for i, c := range m.Contributors {
-			maintainers[i] = c.Login
-		}
-		result[RepoMaintainers] = maintainers
-	}
-
-	return result, nil
+	return repoMetadata(m), nil
 }
@@ -23,9 +23,14 @@ func NewGitLab(token string, cacheTTL time.Duration) (*GitLab, error) {
 func (g *GitLab) Name() string { return "gitlab" }
 
 func (g *GitLab) Enrich(ctx context.Context, repo *source.RepoInfo, refresh bool) (map[string]any, error) {
-	_, _, ok := gitlab.ExtractURL(repo.ProjectURLs, repo.HomePage)
+	namespace, project, ok := g.client.ExtractURL(repo.ProjectURLs, repo.HomePage)
 	if !ok {
 		return nil, nil
 	}
-	return nil, nil
+
+	m, err := g.client.Fetch(ctx, namespace, project, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return repoMetadata(m), nil
 }
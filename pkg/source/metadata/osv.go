@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/osv"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+// osvEcosystems are the OSV ecosystems of the Package URL types.
+var osvEcosystems = map[string]string{
+	source.PURLCargo:    "crates.io",
+	source.PURLComposer: "Packagist",
+	source.PURLGem:      "RubyGems",
+	source.PURLGolang:   "Go",
+	source.PURLMaven:    "Maven",
+	source.PURLNPM:      "npm",
+	source.PURLNuGet:    "NuGet",
+	source.PURLPyPI:     "PyPI",
+}
+
+// OSV records the known vulnerabilities of each package version, looked up
+// in an OSV database export (osv.Load) or the osv.dev API (osv.NewClient).
+// Advisories whose ranges cannot be evaluated for a version are recorded
+// with "unverified" set, and logged to logf if it is not nil.
+type OSV struct {
+	source osv.Source
+	logf   func(string, ...any)
+}
+
+func NewOSV(src osv.Source, logf func(string, ...any)) *OSV {
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &OSV{src, logf}
+}
+
+func (o *OSV) Name() string { return "osv" }
+
+func (o *OSV) Enrich(ctx context.Context, repo *source.RepoInfo, refresh bool) (map[string]any, error) {
+	ecosystem, ok := osvEcosystems[repo.PURLType]
+	if !ok || repo.Name == "" || repo.Version == "" {
+		return nil, nil
+	}
+
+	advisories, err := o.source.Advisories(ctx, ecosystem, repo.Name, repo.Version, refresh)
+	if err != nil {
+		return nil, err
+	}
+	vulns := osv.Vulns(advisories, ecosystem, repo.Name, repo.Version)
+	if len(vulns) == 0 {
+		return nil, nil
+	}
+
+	// A list of maps, the shape the meta has once read back from JSON.
+	list := make([]any, len(vulns))
+	for i, v := range vulns {
+		m := map[string]any{"id": v.ID}
+		if v.Severity != "" {
+			m["severity"] = v.Severity
+		}
+		if v.Fixed != "" {
+			m["fixed"] = v.Fixed
+		}
+		if v.Unverified {
+			m["unverified"] = true
+			o.logf("cannot tell whether %s %s is affected by %s: unrecognized versions", repo.Name, repo.Version, v.ID)
+		}
+		list[i] = m
+	}
+	return map[string]any{Vulns: list}, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"maps"
 
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/license"
 	"github.com/matzehuels/stacktower/pkg/source"
 )
 
@@ -26,3 +28,39 @@ func (c *Composite) Enrich(ctx context.Context, repo *source.RepoInfo, refresh b
 	}
 	return m, nil
 }
+
+// repoMetadata maps the metrics of a repository to the repo_* keys.
+func repoMetadata(m *integrations.RepoMetrics) map[string]any {
+	result := map[string]any{
+		RepoURL:      m.RepoURL,
+		RepoOwner:    m.Owner,
+		RepoStars:    m.Stars,
+		RepoArchived: m.Archived,
+	}
+
+	if m.Language != "" {
+		result[RepoLanguage] = m.Language
+	}
+	if len(m.Topics) > 0 {
+		result[RepoTopics] = m.Topics
+	}
+	// Forges report NOASSERTION and the like for licenses they can't
+	// identify, which Normalize drops.
+	if l := license.Normalize(m.License); l != "" {
+		result[RepoLicense] = l
+	}
+	if m.LastCommitAt != nil {
+		result[RepoLastCommit] = m.LastCommitAt.Format("2006-01-02")
+	}
+	if m.LastReleaseAt != nil {
+		result[RepoLastRelease] = m.LastReleaseAt.Format("2006-01-02")
+	}
+	if len(m.Contributors) > 0 {
+		maintainers := make([]string, len(m.Contributors))
+		for i, c := range m.Contributors {
+			maintainers[i] = c.Login
+		}
+		result[RepoMaintainers] = maintainers
+	}
+	return result
+}
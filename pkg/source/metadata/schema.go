@@ -12,3 +12,7 @@ const (
 	RepoLastRelease = "repo_last_release"
 	RepoLicense     = "repo_license"
 )
+
+// Vulns lists the advisories affecting a package version, each a map with
+// "id" and, when known, "severity" and "fixed" (the first fixed version).
+const Vulns = "vulns"
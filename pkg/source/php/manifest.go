@@ -0,0 +1,53 @@
+package php
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/packagist"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type composerJSON struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	Homepage   string            `json:"homepage"`
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+	Support    struct {
+		Source string `json:"source"`
+	} `json:"support"`
+}
+
+// manifestFiles mark a directory as a project.
+var manifestFiles = []string{"composer.json"}
+
+// readManifest reads the require and require-dev sections of a project's
+// composer.json, the latter as dev dependencies.
+func readManifest(dir string) (*source.Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return nil, err
+	}
+	var cj composerJSON
+	if err := json.Unmarshal(data, &cj); err != nil {
+		return nil, fmt.Errorf("composer.json: %w", err)
+	}
+
+	m := &source.Manifest{
+		Name:        cmp.Or(strings.ToLower(cj.Name), source.ProjectName(dir)),
+		Version:     cj.Version,
+		HomePage:    cj.Homepage,
+		ProjectURLs: map[string]string{},
+		File:        "composer.json",
+	}
+	if cj.Support.Source != "" {
+		m.ProjectURLs["repository"] = cj.Support.Source
+	}
+	m.Dependencies = packagist.ExtractDeps(cj.Require, cj.RequireDev)
+	return m, nil
+}
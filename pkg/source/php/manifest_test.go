@@ -0,0 +1,42 @@
+package php
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	cj := `{
+  "name": "Acme/Shop",
+  "require": {"php": ">=8.2", "ext-json": "*", "symfony/console": "^7.0", "Monolog/Monolog": "^3.0"},
+  "require-dev": {"phpunit/phpunit": "^10"},
+  "support": {"source": "https://github.com/acme/shop"}
+}`
+	if err := os.WriteFile(filepath.Join(dir, "composer.json"), []byte(cj), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "acme/shop" {
+		t.Errorf("name = %s", m.Name)
+	}
+	want := []source.Dependency{
+		{Name: "monolog/monolog", Constraint: "^3.0", Kind: source.KindRuntime},
+		{Name: "symfony/console", Constraint: "^7.0", Kind: source.KindRuntime},
+		{Name: "phpunit/phpunit", Constraint: "^10", Kind: source.KindDev},
+	}
+	if !reflect.DeepEqual(m.Dependencies, want) {
+		t.Errorf("deps = %v, want %v", m.Dependencies, want)
+	}
+	if m.ProjectURLs["repository"] != "https://github.com/acme/shop" {
+		t.Errorf("repository = %q", m.ProjectURLs["repository"])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/matzehuels/stacktower/pkg/dag"
 	"github.com/matzehuels/stacktower/pkg/integrations/packagist"
 	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
 )
 
 // Parser implements source.Parser for PHP/Composer via Packagist
@@ -22,12 +23,18 @@ func NewParser(cacheTTL time.Duration) (*Parser, error) {
 	return &Parser{client: c}, nil
 }
 
-func (p *Parser) Parse(ctx context.Context, pkg string, opts source.Options) (*dag.DAG, error) {
-	return source.Parse(ctx, pkg, opts, p.fetch)
+func (p *Parser) Parse(ctx context.Context, pkgs []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLComposer
+	opts.VersionScheme = version.Composer
+	return source.ParseRoots(ctx, source.Roots(pkgs), opts, manifestFiles, readManifest, p.fetch)
 }
 
-func (p *Parser) fetch(ctx context.Context, name string, refresh bool) (*packageInfo, error) {
-	info, err := p.client.FetchPackage(ctx, name, refresh)
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*packageInfo, error) {
+	v, err := source.ResolveVersion(ctx, dep, version.Composer, p.client.FetchVersions, refresh)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.client.FetchPackageVersion(ctx, dep.Name, v, refresh)
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +43,9 @@ func (p *Parser) fetch(ctx context.Context, name string, refresh bool) (*package
 
 type packageInfo struct{ *packagist.PackageInfo }
 
-func (pi *packageInfo) GetName() string           { return pi.Name }
-func (pi *packageInfo) GetVersion() string        { return pi.Version }
-func (pi *packageInfo) GetDependencies() []string { return pi.Dependencies }
+func (pi *packageInfo) GetName() string                      { return pi.Name }
+func (pi *packageInfo) GetVersion() string                   { return pi.Version }
+func (pi *packageInfo) GetDependencies() []source.Dependency { return pi.Dependencies }
 
 func (pi *packageInfo) ToMetadata() map[string]any {
 	m := map[string]any{"version": pi.Version}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/matzehuels/stacktower/pkg/integrations"
 	"github.com/matzehuels/stacktower/pkg/integrations/packagist"
 )
 
@@ -21,7 +22,7 @@ func TestPackageInfo_Getters(t *testing.T) {
 	pi := &packageInfo{&packagist.PackageInfo{
 		Name:         "vendor/pkg",
 		Version:      "1.0.0",
-		Dependencies: []string{"vendor/dep"},
+		Dependencies: []integrations.Dependency{{Name: "vendor/dep", Constraint: "^1.0"}},
 	}}
 
 	if pi.GetName() != "vendor/pkg" {
@@ -31,7 +32,7 @@ func TestPackageInfo_Getters(t *testing.T) {
 		t.Errorf("GetVersion = %s", pi.GetVersion())
 	}
 	deps := pi.GetDependencies()
-	if len(deps) != 1 || deps[0] != "vendor/dep" {
+	if len(deps) != 1 || deps[0].Name != "vendor/dep" {
 		t.Errorf("GetDependencies = %#v", deps)
 	}
 }
@@ -0,0 +1,145 @@
+package source
+
+import (
+	"cmp"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+// Package URL types of the registries StackTower parses.
+const (
+	PURLCargo    = "cargo"
+	PURLComposer = "composer"
+	PURLGem      = "gem"
+	PURLGolang   = "golang"
+	PURLMaven    = "maven"
+	PURLNPM      = "npm"
+	PURLNuGet    = "nuget"
+	PURLPyPI     = "pypi"
+)
+
+// PackageURL formats a Package URL (https://github.com/package-url/purl-spec)
+// such as pkg:pypi/requests@2.31.0. Names are normalized the way the purl
+// spec asks for their type, and a Maven groupId:artifactId becomes the
+// namespace and name. An empty version is left out.
+func PackageURL(typ, name, version string) string {
+	switch typ {
+	case PURLPyPI:
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case PURLNPM, PURLComposer:
+		name = strings.ToLower(name)
+	case PURLMaven:
+		name = strings.Replace(name, ":", "/", 1)
+	}
+
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = escapePURL(s)
+	}
+	purl := "pkg:" + typ + "/" + strings.Join(segments, "/")
+	if version != "" {
+		purl += "@" + escapePURL(version)
+	}
+	return purl
+}
+
+// ParsePackageURL splits a Package URL written by PackageURL into its
+// type, name and version, turning a Maven namespace back into a groupId.
+// Qualifiers and subpaths are dropped.
+func ParsePackageURL(purl string) (typ, name, version string, ok bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return "", "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	typ, rest, ok = strings.Cut(rest, "/")
+	if !ok || typ == "" || rest == "" {
+		return "", "", "", false
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest, version = rest[:i], rest[i+1:]
+	}
+
+	segments := strings.Split(rest, "/")
+	for i, s := range segments {
+		if segments[i], ok = unescapePURL(s); !ok {
+			return "", "", "", false
+		}
+	}
+	if version, ok = unescapePURL(version); !ok {
+		return "", "", "", false
+	}
+	name = strings.Join(segments, "/")
+	if typ == PURLMaven {
+		name = strings.Replace(name, "/", ":", 1)
+	}
+	return typ, name, version, true
+}
+
+func unescapePURL(s string) (string, bool) {
+	s, err := url.PathUnescape(s)
+	return s, err == nil
+}
+
+func escapePURL(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// RelabelPURLs returns a copy of g whose nodes are identified by their
+// "purl" meta, keeping the old ID as "label" meta for display. Nodes
+// without a Package URL, such as a synthetic root, keep their ID. The
+// "root" and "roots" graph meta follow the new IDs.
+func RelabelPURLs(g *dag.DAG) *dag.DAG {
+	ids := make(map[string]string)
+	out := g.Relabel(func(n *dag.Node) string {
+		purl, ok := n.Meta["purl"].(string)
+		if !ok || purl == n.ID {
+			return n.ID
+		}
+		ids[n.ID] = purl
+		return purl
+	})
+	for _, id := range slices.Sorted(maps.Keys(ids)) {
+		purl := ids[id]
+		if n, ok := out.Node(purl); ok {
+			if _, ok := n.Meta["label"]; !ok {
+				n.Meta["label"] = id
+			}
+		}
+	}
+
+	meta := out.Meta()
+	if root, ok := meta["root"].(string); ok {
+		meta["root"] = cmp.Or(ids[root], root)
+	}
+	if roots := MetaStrings(meta["roots"]); roots != nil {
+		for i, r := range roots {
+			roots[i] = cmp.Or(ids[r], r)
+		}
+		meta["roots"] = roots
+	}
+	return out
+}
+
+// MetaStrings returns a list of strings held in meta, which is a []string
+// when set by a parser and a []any once read back from JSON.
+func MetaStrings(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return slices.Clone(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/dag"
+)
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		typ, name, version string
+		want               string
+	}{
+		{PURLPyPI, "Django_Rest", "3.15.1", "pkg:pypi/django-rest@3.15.1"},
+		{PURLNPM, "@Babel/core", "7.24.0", "pkg:npm/%40babel/core@7.24.0"},
+		{PURLMaven, "com.google.guava:guava", "33.0-jre", "pkg:maven/com.google.guava/guava@33.0-jre"},
+		{PURLGolang, "github.com/spf13/cobra", "v1.8.0", "pkg:golang/github.com/spf13/cobra@v1.8.0"},
+		{PURLCargo, "serde", "", "pkg:cargo/serde"},
+		{PURLComposer, "Monolog/Monolog", "3.5.0", "pkg:composer/monolog/monolog@3.5.0"},
+	}
+	for _, tt := range tests {
+		if got := PackageURL(tt.typ, tt.name, tt.version); got != tt.want {
+			t.Errorf("PackageURL(%q, %q, %q) = %q, want %q", tt.typ, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParse_PURLIDs(t *testing.T) {
+	registry := map[string][]Dependency{
+		"api":    {{Name: "Requests"}},
+		"worker": {{Name: "Requests"}},
+	}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		return &fakeInfo{name: dep.Name, deps: registry[dep.Name]}, nil
+	}
+
+	opts := Options{PURLType: PURLPyPI, PURLIDs: true, RootName: "svc"}
+	g, err := Parse(context.Background(), Roots([]string{"api", "worker"}), opts, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, ok := g.Node("pkg:pypi/requests@1.0.0")
+	if !ok {
+		t.Fatalf("no node for pkg:pypi/requests@1.0.0 in %v", g.Nodes())
+	}
+	if n.Meta["label"] != "Requests" || n.Meta["purl"] != "pkg:pypi/requests@1.0.0" {
+		t.Errorf("meta = %v", n.Meta)
+	}
+	if _, ok := g.Node("svc"); !ok {
+		t.Error("synthetic root should keep its name")
+	}
+	meta := g.Meta()
+	if meta["root"] != "svc" {
+		t.Errorf("graph meta root = %v, want svc", meta["root"])
+	}
+	if roots := MetaStrings(meta["roots"]); !slices.Equal(roots, []string{"pkg:pypi/api@1.0.0", "pkg:pypi/worker@1.0.0"}) {
+		t.Errorf("graph meta roots = %v", roots)
+	}
+}
+
+func TestParsePackageURL(t *testing.T) {
+	tests := []struct {
+		purl, typ, name, version string
+	}{
+		{"pkg:pypi/django-rest@3.15.1", PURLPyPI, "django-rest", "3.15.1"},
+		{"pkg:npm/%40babel/core@7.24.0", PURLNPM, "@babel/core", "7.24.0"},
+		{"pkg:maven/com.google.guava/guava@33.0-jre", PURLMaven, "com.google.guava:guava", "33.0-jre"},
+		{"pkg:golang/github.com/spf13/cobra@v1.8.0", PURLGolang, "github.com/spf13/cobra", "v1.8.0"},
+		{"pkg:cargo/serde", PURLCargo, "serde", ""},
+		{"pkg:gem/rails@7.1.0?platform=ruby", PURLGem, "rails", "7.1.0"},
+	}
+	for _, tt := range tests {
+		typ, name, version, ok := ParsePackageURL(tt.purl)
+		if !ok || typ != tt.typ || name != tt.name || version != tt.version {
+			t.Errorf("ParsePackageURL(%q) = %q, %q, %q, %v", tt.purl, typ, name, version, ok)
+		}
+	}
+	for _, bad := range []string{"requests", "pkg:pypi", "pkg:/x", "pkg:npm/%zz"} {
+		if _, _, _, ok := ParsePackageURL(bad); ok {
+			t.Errorf("ParsePackageURL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMerge_TruncatedAcrossEcosystems(t *testing.T) {
+	parse := func(purlType string) *dag.DAG {
+		fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+			if dep.Name == "broken" {
+				return nil, errors.New("unreachable")
+			}
+			// MaxNodes leaves requests out.
+			return &fakeInfo{name: dep.Name, deps: []Dependency{{Name: "broken"}, {Name: "requests"}}}, nil
+		}
+		opts := Options{PURLType: purlType, MaxNodes: 2}
+		g, err := Parse(context.Background(), Roots([]string{"app-" + purlType}), opts, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return RelabelPURLs(g)
+	}
+
+	merged := dag.Merge(parse(PURLPyPI), parse(PURLNPM))
+	for _, id := range []string{"pkg:pypi/requests", "pkg:npm/requests", "pkg:pypi/broken", "pkg:npm/broken"} {
+		n, ok := merged.Node(id)
+		if !ok {
+			t.Errorf("no node %s in %v", id, merged.Nodes())
+			continue
+		}
+		if _, ok := n.Meta["fetch_error"]; !ok && n.Meta["truncated"] != true {
+			t.Errorf("%s should be marked incomplete: %v", id, n.Meta)
+		}
+	}
+	if _, ok := merged.Node("requests"); ok {
+		t.Error("unfetched packages should not keep bare IDs")
+	}
+}
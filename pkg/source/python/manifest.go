@@ -0,0 +1,261 @@
+package python
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type pyproject struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		Version              string              `toml:"version"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		URLs                 map[string]string   `toml:"urls"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Name            string                 `toml:"name"`
+			Version         string                 `toml:"version"`
+			Homepage        string                 `toml:"homepage"`
+			Repository      string                 `toml:"repository"`
+			Dependencies    map[string]any         `toml:"dependencies"`
+			DevDependencies map[string]any         `toml:"dev-dependencies"`
+			Extras          map[string][]string    `toml:"extras"`
+			Group           map[string]poetryGroup `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+type poetryGroup struct {
+	Dependencies map[string]any `toml:"dependencies"`
+}
+
+// manifestFiles mark a directory as a project.
+var manifestFiles = []string{"pyproject.toml", "requirements.txt"}
+
+// readManifest reads a project's direct dependencies from pyproject.toml
+// (PEP 621 or Poetry) and falls back to requirements.txt. Dependencies
+// whose markers do not hold in env are left out; those of the requested
+// extras are added. Poetry's dependency groups other than main hold dev
+// dependencies.
+func readManifest(dir string, env pypi.Environment, extras ...string) (*source.Manifest, error) {
+	m := &source.Manifest{Name: source.ProjectName(dir), File: "pyproject.toml"}
+
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	switch {
+	case err == nil:
+		var pp pyproject
+		if err := toml.Unmarshal(data, &pp); err != nil {
+			return nil, fmt.Errorf("pyproject.toml: %w", err)
+		}
+		poetry := pp.Tool.Poetry
+		if name := cmp.Or(pp.Project.Name, poetry.Name); name != "" {
+			m.Name = pypi.NormalizeName(name)
+		}
+		m.Version = cmp.Or(pp.Project.Version, poetry.Version)
+		m.ProjectURLs = pp.Project.URLs
+		if poetry.Repository != "" {
+			m.ProjectURLs = map[string]string{"repository": poetry.Repository}
+		}
+		m.HomePage = poetry.Homepage
+		m.Dependencies = pypi.ExtractDeps(pp.Project.Dependencies, env)
+		for _, extra := range extras {
+			for _, dep := range pypi.ExtractDeps(optionalDeps(pp.Project.OptionalDependencies, extra), env) {
+				dep.Feature = extra
+				m.Dependencies = mergeDependency(m.Dependencies, dep)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(poetry.Dependencies)) {
+			spec := poetry.Dependencies[name]
+			if name == "python" || !poetryApplies(spec, env) {
+				continue
+			}
+			dep := source.Dependency{
+				Name:       pypi.NormalizeName(name),
+				Constraint: poetryConstraint(spec),
+				Extras:     poetryExtras(spec),
+			}
+			if poetryOptional(spec) {
+				if dep.Feature = poetryExtra(poetry.Extras, name, extras); dep.Feature == "" {
+					continue
+				}
+			}
+			m.Dependencies = append(m.Dependencies, dep)
+		}
+		for _, dep := range poetryDevDependencies(poetry.DevDependencies, poetry.Group, env) {
+			if !slices.ContainsFunc(m.Dependencies, func(d source.Dependency) bool { return d.Name == dep.Name }) {
+				m.Dependencies = append(m.Dependencies, dep)
+			}
+		}
+		if len(m.Dependencies) > 0 {
+			return m, nil
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	reqs, err := readRequirements(filepath.Join(dir, "requirements.txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		if m.Version != "" {
+			return m, nil
+		}
+		return nil, fmt.Errorf("no pyproject.toml or requirements.txt in %s", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Version == "" {
+		m.File = "requirements.txt"
+	}
+	m.Dependencies = pypi.ExtractDeps(reqs, env)
+	return m, nil
+}
+
+// poetryConstraint returns the version of a Poetry dependency, written
+// either as a plain string or as a table with a version key. Poetry's own
+// ^ and ~ operators are kept as they are.
+func poetryConstraint(spec any) string {
+	switch v := spec.(type) {
+	case string:
+		return v
+	case map[string]any:
+		s, _ := v["version"].(string)
+		return s
+	}
+	return ""
+}
+
+// poetryDevDependencies returns the dependencies of the legacy
+// [tool.poetry.dev-dependencies] table and of every group but main.
+func poetryDevDependencies(legacy map[string]any, groups map[string]poetryGroup, env pypi.Environment) []source.Dependency {
+	specs := make(map[string]any, len(legacy))
+	maps.Copy(specs, legacy)
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		if group == "main" {
+			continue
+		}
+		for name, spec := range groups[group].Dependencies {
+			if _, ok := specs[name]; !ok {
+				specs[name] = spec
+			}
+		}
+	}
+
+	var deps []source.Dependency
+	for _, name := range slices.Sorted(maps.Keys(specs)) {
+		if !poetryApplies(specs[name], env) {
+			continue
+		}
+		deps = append(deps, source.Dependency{
+			Name:       pypi.NormalizeName(name),
+			Constraint: poetryConstraint(specs[name]),
+			Extras:     poetryExtras(specs[name]),
+			Kind:       source.KindDev,
+		})
+	}
+	return deps
+}
+
+// optionalDeps returns the requirements of an extra, matching its name the
+// normalized way.
+func optionalDeps(groups map[string][]string, extra string) []string {
+	for name, reqs := range groups {
+		if pypi.NormalizeName(name) == extra {
+			return reqs
+		}
+	}
+	return nil
+}
+
+// mergeDependency appends dep, or adds its extras to the dependency of the
+// same name that is already listed.
+func mergeDependency(deps []source.Dependency, dep source.Dependency) []source.Dependency {
+	i := slices.IndexFunc(deps, func(d source.Dependency) bool { return d.Name == dep.Name })
+	if i < 0 {
+		return append(deps, dep)
+	}
+	for _, e := range dep.Extras {
+		if !slices.Contains(deps[i].Extras, e) {
+			deps[i].Extras = append(deps[i].Extras, e)
+		}
+	}
+	return deps
+}
+
+// poetryExtra returns the first requested extra whose [tool.poetry.extras]
+// entry lists the optional dependency name.
+func poetryExtra(groups map[string][]string, name string, extras []string) string {
+	for _, extra := range extras {
+		members := optionalDeps(groups, extra)
+		if slices.ContainsFunc(members, func(m string) bool { return pypi.NormalizeName(m) == pypi.NormalizeName(name) }) {
+			return extra
+		}
+	}
+	return ""
+}
+
+func poetryOptional(spec any) bool {
+	t, _ := spec.(map[string]any)
+	optional, _ := t["optional"].(bool)
+	return optional
+}
+
+func poetryExtras(spec any) []string {
+	t, _ := spec.(map[string]any)
+	list, _ := t["extras"].([]any)
+	var extras []string
+	for _, e := range list {
+		if s, ok := e.(string); ok {
+			extras = append(extras, s)
+		}
+	}
+	return pypi.ParseExtras(strings.Join(extras, ","))
+}
+
+// poetryApplies evaluates the markers key of a Poetry dependency table.
+func poetryApplies(spec any, env pypi.Environment) bool {
+	t, ok := spec.(map[string]any)
+	if !ok {
+		return true
+	}
+	marker, _ := t["markers"].(string)
+	if marker == "" {
+		return true
+	}
+	applies, err := env.Evaluate(marker)
+	return err != nil || applies
+}
+
+// readRequirements returns the requirement lines of a requirements file,
+// dropping comments, pip options and editable or URL installs.
+func readRequirements(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), " #")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		reqs = append(reqs, line)
+	}
+	return reqs, sc.Err()
+}
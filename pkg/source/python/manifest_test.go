@@ -0,0 +1,170 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "myservice")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantName    string
+		wantVersion string
+		wantDeps    []source.Dependency
+	}{
+		{
+			name: "pep621",
+			files: map[string]string{"pyproject.toml": `
+[project]
+name = "My_Service"
+version = "0.3.0"
+dependencies = [
+  "fastapi>=0.110",
+  "SQLAlchemy[asyncio]~=2.0",
+  "pytest; extra == 'test'",
+  "pywin32>=306; sys_platform == 'win32'",
+  "tomli>=1.1; python_version < '3.11'",
+]
+`},
+			wantName:    "my-service",
+			wantVersion: "0.3.0",
+			wantDeps:    []source.Dependency{{Name: "fastapi", Constraint: ">=0.110"}, {Name: "sqlalchemy", Constraint: "~=2.0", Extras: []string{"asyncio"}}},
+		},
+		{
+			name: "poetry",
+			files: map[string]string{"pyproject.toml": `
+[tool.poetry]
+name = "svc"
+version = "1.2.0"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+requests = "^2.31"
+Django = {version = "^5.0"}
+colorama = {version = "^0.4", markers = "sys_platform == 'win32'"}
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+`},
+			wantName:    "svc",
+			wantVersion: "1.2.0",
+			wantDeps: []source.Dependency{
+				{Name: "django", Constraint: "^5.0"},
+				{Name: "requests", Constraint: "^2.31"},
+				{Name: "pytest", Constraint: "^8.0", Kind: source.KindDev},
+			},
+		},
+		{
+			name: "requirements",
+			files: map[string]string{"requirements.txt": `
+# pinned
+flask==3.0.0
+-r base.txt
+--index-url https://example.com/simple
+gunicorn>=21 # server
+waitress>=3; os_name == "nt"
+git+https://github.com/org/lib.git
+`},
+			wantName: "myservice",
+			wantDeps: []source.Dependency{{Name: "flask", Constraint: "==3.0.0"}, {Name: "gunicorn", Constraint: ">=21"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := readManifest(writeFiles(t, tt.files), pypi.DefaultEnvironment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Name != tt.wantName || m.Version != tt.wantVersion {
+				t.Errorf("got %s@%s, want %s@%s", m.Name, m.Version, tt.wantName, tt.wantVersion)
+			}
+			if !reflect.DeepEqual(m.Dependencies, tt.wantDeps) {
+				t.Errorf("deps = %v, want %v", m.Dependencies, tt.wantDeps)
+			}
+		})
+	}
+
+	if _, err := readManifest(writeFiles(t, nil), pypi.DefaultEnvironment); err == nil {
+		t.Error("expected error for directory without manifest")
+	}
+}
+
+func TestReadManifest_Extras(t *testing.T) {
+	tests := []struct {
+		name      string
+		pyproject string
+		wantDeps  []source.Dependency
+	}{
+		{
+			name: "pep621",
+			pyproject: `
+[project]
+name = "svc"
+dependencies = ["httpx>=0.27"]
+
+[project.optional-dependencies]
+Server = ["uvicorn[standard]>=0.29", "httpx[http2]"]
+docs = ["mkdocs"]
+`,
+			wantDeps: []source.Dependency{
+				{Name: "httpx", Constraint: ">=0.27", Extras: []string{"http2"}},
+				{Name: "uvicorn", Constraint: ">=0.29", Extras: []string{"standard"}, Feature: "server"},
+			},
+		},
+		{
+			name: "poetry",
+			pyproject: `
+[tool.poetry]
+name = "svc"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+httpx = {version = "^0.27", extras = ["http2"]}
+uvicorn = {version = "^0.29", optional = true}
+mkdocs = {version = "^1.5", optional = true}
+
+[tool.poetry.extras]
+server = ["uvicorn"]
+docs = ["mkdocs"]
+`,
+			wantDeps: []source.Dependency{
+				{Name: "httpx", Constraint: "^0.27", Extras: []string{"http2"}},
+				{Name: "uvicorn", Constraint: "^0.29", Feature: "server"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"pyproject.toml": tt.pyproject})
+			m, err := readManifest(dir, pypi.DefaultEnvironment, "server")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Dependencies, tt.wantDeps) {
+				t.Errorf("deps = %+v, want %+v", m.Dependencies, tt.wantDeps)
+			}
+		})
+	}
+}
@@ -2,44 +2,81 @@ package python
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/matzehuels/stacktower/pkg/dag"
 	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
 	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
 )
 
+// Parser implements source.Parser for PyPI. Requirements are filtered by
+// their environment markers for the configured target interpreter.
 type Parser struct {
 	client *pypi.Client
+	env    pypi.Environment
 }
 
-func NewParser(cacheTTL time.Duration) (*Parser, error) {
+func NewParser(env pypi.Environment, cacheTTL time.Duration) (*Parser, error) {
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
 	c, err := pypi.NewClient(cacheTTL)
 	if err != nil {
 		return nil, err
 	}
-	return &Parser{client: c}, nil
+	return &Parser{client: c, env: env}, nil
 }
 
-func (p *Parser) Parse(ctx context.Context, pkg string, opts source.Options) (*dag.DAG, error) {
-	return source.Parse(ctx, pkg, opts, p.fetch)
+// Parse crawls pkg, which may request extras as in "fastapi[standard]";
+// they add to opts.Features.
+func (p *Parser) Parse(ctx context.Context, pkgs []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLPyPI
+	opts.VersionScheme = version.PEP440
+	extras := pypi.ParseExtras(strings.Join(opts.Features, ","))
+	opts.Features = extras
+	roots := make([]source.Dependency, len(pkgs))
+	for i, pkg := range pkgs {
+		project, err := source.IsProjectDir(pkg, manifestFiles...)
+		if err != nil {
+			return nil, err
+		}
+		if project {
+			roots[i] = source.Dependency{Name: pkg}
+			continue
+		}
+		name, more := pypi.SplitExtras(pkg)
+		roots[i] = source.Dependency{Name: name, Extras: more}
+	}
+	readProject := func(dir string) (*source.Manifest, error) { return readManifest(dir, p.env, extras...) }
+	return source.ParseRoots(ctx, roots, opts, manifestFiles, readProject, p.fetch)
 }
 
-func (p *Parser) fetch(ctx context.Context, name string, refresh bool) (*packageInfo, error) {
-	info, err := p.client.FetchPackage(ctx, name, refresh)
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*packageInfo, error) {
+	v, err := source.ResolveVersion(ctx, dep, version.PEP440, p.client.FetchVersions, refresh)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.client.FetchPackageVersion(ctx, dep.Name, v, refresh)
 	if err != nil {
 		return nil, err
 	}
-	return &packageInfo{info}, nil
+	return &packageInfo{PackageInfo: info, env: p.env, extras: dep.Extras}, nil
 }
 
 type packageInfo struct {
 	*pypi.PackageInfo
+	env    pypi.Environment
+	extras []string
 }
 
-func (pi *packageInfo) GetName() string           { return pi.Name }
-func (pi *packageInfo) GetVersion() string        { return pi.Version }
-func (pi *packageInfo) GetDependencies() []string { return pi.Dependencies }
+func (pi *packageInfo) GetName() string    { return pi.Name }
+func (pi *packageInfo) GetVersion() string { return pi.Version }
+
+func (pi *packageInfo) GetDependencies() []source.Dependency {
+	return pypi.ExtractDeps(pi.RequiresDist, pi.env, pi.extras...)
+}
 
 func (pi *packageInfo) ToMetadata() map[string]any {
 	m := map[string]any{"version": pi.Version}
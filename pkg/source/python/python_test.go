@@ -3,14 +3,43 @@ package python
 import (
 	"testing"
 	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/pypi"
 )
 
 func TestNewParser(t *testing.T) {
-	p, err := NewParser(time.Hour)
+	p, err := NewParser(pypi.DefaultEnvironment, time.Hour)
 	if err != nil {
 		t.Fatalf("NewParser failed: %v", err)
 	}
 	if p.client == nil {
 		t.Error("client not initialized")
 	}
+
+	if _, err := NewParser(pypi.Environment{Platform: "beos"}, time.Hour); err == nil {
+		t.Error("expected error for unknown platform")
+	}
+}
+
+func TestPackageInfo_GetDependencies(t *testing.T) {
+	info := &pypi.PackageInfo{
+		Name:    "black",
+		Version: "24.1.0",
+		RequiresDist: []string{
+			"click>=8.0.0",
+			"tomli>=1.1.0; python_version < '3.11'",
+			"colorama>=0.4.3; extra == 'colorama'",
+			"typing-extensions>=4.0.1; python_version < '3.11'",
+		},
+	}
+
+	deps := (&packageInfo{PackageInfo: info, env: pypi.DefaultEnvironment}).GetDependencies()
+	if len(deps) != 1 || deps[0].Name != "click" {
+		t.Errorf("3.12 deps = %v, want [click]", deps)
+	}
+
+	deps = (&packageInfo{PackageInfo: info, env: pypi.Environment{PythonVersion: "3.10"}}).GetDependencies()
+	if len(deps) != 3 {
+		t.Errorf("3.10 deps = %v, want click, tomli, typing-extensions", deps)
+	}
 }
@@ -1,12 +1,21 @@
 package source
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"maps"
+	"net"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/matzehuels/stacktower/pkg/dag"
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/license"
+	"github.com/matzehuels/stacktower/pkg/version"
 )
 
 const (
@@ -16,8 +25,10 @@ const (
 	numWorkers      = 20
 )
 
+// Parser crawls the dependencies of one or more packages, or project
+// directories, into a single graph.
 type Parser interface {
-	Parse(ctx context.Context, pkg string, opts Options) (*dag.DAG, error)
+	Parse(ctx context.Context, pkgs []string, opts Options) (*dag.DAG, error)
 }
 
 type MetadataProvider interface {
@@ -31,6 +42,9 @@ type RepoInfo struct {
 	ProjectURLs  map[string]string
 	HomePage     string
 	ManifestFile string
+	// PURLType is the Package URL type of the package's registry; Parse
+	// fills it in from Options when the parser leaves it empty.
+	PURLType string
 }
 
 type Options struct {
@@ -40,8 +54,48 @@ type Options struct {
 	Refresh           bool
 	MetadataProviders []MetadataProvider
 	Logger            func(string, ...any)
+	// Features are the extras or features enabled on the root packages.
+	Features []string
+	// RootName, when set, names a synthetic node placed above the roots,
+	// so several packages share one top block. Without it the roots form
+	// the top row themselves.
+	RootName string
+	// PURLType is the Package URL type of the crawled packages, set by
+	// each parser; nodes then carry their Package URL as "purl" meta.
+	PURLType string
+	// VersionScheme is how the crawled packages' versions and constraints
+	// are written, set by parsers that resolve constraints. Edges whose
+	// constraint the version reached does not satisfy are then marked
+	// "constraint_unsatisfied".
+	VersionScheme version.Scheme
+	// PURLIDs makes Package URLs the node IDs, so graphs of different
+	// ecosystems can be merged; the package name moves to "label" meta.
+	PURLIDs bool
+	// Offline serves every request from the cache, expired entries
+	// included. Parse then fails listing the responses that are missing.
+	Offline bool
+	// IncludeKinds are the dependency kinds to crawl; DefaultKinds if
+	// empty. Dev dependencies are only followed from the root, as no
+	// package manager installs those of a dependency.
+	IncludeKinds []string
 }
 
+// Dependency kinds, as recorded in the "kind" edge meta.
+const (
+	KindRuntime  = integrations.KindRuntime
+	KindDev      = integrations.KindDev
+	KindPeer     = integrations.KindPeer
+	KindOptional = integrations.KindOptional
+	KindBuild    = integrations.KindBuild
+)
+
+// DefaultKinds are the dependency kinds crawled unless Options say
+// otherwise: what an install puts on disk.
+var DefaultKinds = []string{KindRuntime, KindOptional}
+
+// Kinds lists every dependency kind, for validating user input.
+var Kinds = []string{KindRuntime, KindDev, KindPeer, KindOptional, KindBuild}
+
 func (o Options) withDefaults() Options {
 	if o.MaxDepth == 0 {
 		o.MaxDepth = DefaultMaxDepth
@@ -55,233 +109,418 @@ func (o Options) withDefaults() Options {
 	if o.Logger == nil {
 		o.Logger = func(string, ...any) {}
 	}
+	if len(o.IncludeKinds) == 0 {
+		o.IncludeKinds = DefaultKinds
+	}
 	return o
 }
 
+// Dependency is a package's requirement on another package. The crawl
+// visits every package once, so the constraint of the first parent that
+// reaches a package decides which version is fetched. Extras are the
+// exception: a package reached again with extras it was not fetched with
+// is fetched again with the union, so its graph covers all of them.
+type Dependency = integrations.Dependency
+
 type PackageInfo interface {
 	GetName() string
 	GetVersion() string
-	GetDependencies() []string
+	GetDependencies() []Dependency
 	ToMetadata() map[string]any
 	ToRepoInfo() *RepoInfo
 }
 
-type fetchFunc[T PackageInfo] func(ctx context.Context, name string, refresh bool) (T, error)
+type fetchFunc[T PackageInfo] func(ctx context.Context, dep Dependency, refresh bool) (T, error)
 
-func Parse[T PackageInfo](ctx context.Context, root string, opts Options, fetch fetchFunc[T]) (*dag.DAG, error) {
+// Parse crawls the dependency graphs of roots into one graph; a package
+// reached from several roots is fetched once. The graph's meta records
+// the roots and the options that shaped the crawl.
+func Parse[T PackageInfo](ctx context.Context, roots []Dependency, opts Options, fetch fetchFunc[T]) (*dag.DAG, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no packages to parse")
+	}
 	opts = opts.withDefaults()
+	if opts.Offline {
+		ctx = integrations.WithOffline(ctx)
+	}
 
 	p := &parser[T]{
-		ctx:     ctx,
-		opts:    opts,
-		fetch:   fetch,
-		g:       dag.New(nil),
-		visited: make(map[string]bool),
-		meta:    make(map[string]map[string]any),
-		jobs:    make(chan job, numWorkers*2),
-		results: make(chan result[T], numWorkers*2),
-		done:    make(chan struct{}),
-	}
-
-	return p.parse(root)
+		ctx:       ctx,
+		opts:      opts,
+		fetch:     fetch,
+		g:         dag.New(graphMeta(roots, opts)),
+		roots:     make(map[string]bool, len(roots)),
+		visited:   make(map[string]Dependency),
+		fetched:   make(map[string]bool),
+		truncated: make(map[string]bool),
+	}
+
+	return p.parse(roots)
+}
+
+// Roots turns package names into root dependencies.
+func Roots(pkgs []string) []Dependency {
+	roots := make([]Dependency, len(pkgs))
+	for i, pkg := range pkgs {
+		roots[i] = Dependency{Name: pkg}
+	}
+	return roots
 }
 
 type job struct {
-	name  string
+	dep   Dependency
 	depth int
 }
 
 type result[T PackageInfo] struct {
 	name  string
 	info  T
+	meta  map[string]any // node meta, for packages not fetched before
 	depth int
 	err   error
 }
 
+// parser crawls breadth first, one depth at a time. The packages of a
+// depth are fetched concurrently but handled in name order, so the graph,
+// and where MaxNodes cuts it off, do not depend on which fetch finishes
+// first.
 type parser[T PackageInfo] struct {
 	ctx   context.Context
 	opts  Options
 	fetch fetchFunc[T]
 
-	g       *dag.DAG
-	visited map[string]bool
-	meta    map[string]map[string]any
-
-	jobs    chan job
-	results chan result[T]
-	done    chan struct{}
-
-	mu        sync.Mutex
-	inflight  int64
-	nodeCount int32
+	g         *dag.DAG
+	roots     map[string]bool
+	visited   map[string]Dependency
+	fetched   map[string]bool
+	truncated map[string]bool
+	uncached  []string // cache keys missed offline
 }
 
-func (p *parser[T]) adjustInflight(delta int64) {
-	p.mu.Lock()
-	p.inflight += delta
-	isDone := p.inflight == 0
-	p.mu.Unlock()
-
-	if isDone {
-		close(p.done)
+func (p *parser[T]) parse(roots []Dependency) (*dag.DAG, error) {
+	for _, r := range roots {
+		p.roots[r.Name] = true
 	}
-}
-
-func (p *parser[T]) parse(root string) (*dag.DAG, error) {
-	var workerWg sync.WaitGroup
-	for range numWorkers {
-		workerWg.Add(1)
-		go func() {
-			defer workerWg.Done()
-			p.worker()
-		}()
+	if name := p.opts.RootName; name != "" {
+		if p.roots[name] {
+			return nil, fmt.Errorf("root name %q is also one of the packages", name)
+		}
+		_ = p.g.AddNode(dag.Node{ID: name})
+		for _, r := range roots {
+			_ = p.g.AddNode(dag.Node{ID: r.Name})
+			if !slices.Contains(p.g.Children(name), r.Name) {
+				_ = p.g.AddEdge(dag.Edge{From: name, To: r.Name, Meta: map[string]any{"kind": KindRuntime}})
+			}
+		}
 	}
 
-	p.submit(job{name: root, depth: 0})
-
-	rootErr := p.processResults(root)
-
-	close(p.jobs)
-	workerWg.Wait()
+	level := make([]job, len(roots))
+	for i, r := range roots {
+		r.Extras, _ = unionExtras(slices.Clone(p.opts.Features), r.Extras)
+		level[i] = job{dep: r}
+	}
+	level = p.schedule(level, false)
 
-	if rootErr != nil {
-		return nil, rootErr
+	for len(level) > 0 {
+		var next []job
+		for _, r := range p.fetchAll(level) {
+			if err := p.ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := p.handleResult(r); err != nil {
+				return nil, err
+			}
+			next = append(next, p.dependencyJobs(r)...)
+		}
+		level = p.schedule(next, true)
+	}
+	if len(p.uncached) > 0 {
+		slices.Sort(p.uncached)
+		return nil, fmt.Errorf("offline: %d responses %w (parse once without --offline to cache them): %s",
+			len(p.uncached), integrations.ErrNotCached, strings.Join(slices.Compact(p.uncached), ", "))
 	}
 
-	p.applyMetadata()
+	p.markTruncated()
+	p.markUnsatisfied()
+	if p.opts.PURLIDs {
+		return RelabelPURLs(p.g), nil
+	}
 	return p.g, nil
 }
 
-func (p *parser[T]) worker() {
-	for j := range p.jobs {
-		if p.ctx.Err() != nil {
-			p.adjustInflight(-1) // job cancelled
+// schedule turns the dependencies reached at one depth into the jobs of
+// the next, sorted by name. A new package is admitted while the crawl is
+// below MaxNodes, if capped; a known one is fetched again only when it is
+// reached with extras it was not fetched with.
+func (p *parser[T]) schedule(candidates []job, capped bool) []job {
+	slices.SortStableFunc(candidates, func(a, b job) int { return strings.Compare(a.dep.Name, b.dep.Name) })
+
+	var jobs []job
+	queued := make(map[string]int)
+	for _, j := range candidates {
+		name := j.dep.Name
+		first, ok := p.visited[name]
+		if !ok {
+			if capped && len(p.visited) >= p.opts.MaxNodes {
+				continue
+			}
+			p.visited[name] = j.dep
+			queued[name] = len(jobs)
+			jobs = append(jobs, j)
+			continue
+		}
+
+		extras, grew := unionExtras(first.Extras, j.dep.Extras)
+		if !grew {
+			continue
+		}
+		first.Extras = extras
+		p.visited[name] = first
+		if i, ok := queued[name]; ok {
+			jobs[i].dep = first
 			continue
 		}
-		info, err := p.fetch(p.ctx, j.name, p.opts.Refresh)
-		p.results <- result[T]{name: j.name, info: info, depth: j.depth, err: err}
+		j.dep = first
+		queued[name] = len(jobs)
+		jobs = append(jobs, j)
 	}
+	return jobs
 }
 
-func (p *parser[T]) submit(j job) bool {
-	p.mu.Lock()
-	if p.visited[j.name] {
-		p.mu.Unlock()
-		return false
+// fetchAll fetches the packages of one depth with up to numWorkers
+// requests in flight, returning the results in the order of jobs.
+func (p *parser[T]) fetchAll(jobs []job) []result[T] {
+	results := make([]result[T], len(jobs))
+	sem := make(chan struct{}, numWorkers)
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = p.fetchOne(j)
+		}()
 	}
-	p.visited[j.name] = true
-	p.inflight++
-	p.mu.Unlock()
-
-	p.jobs <- j
-	return true
+	wg.Wait()
+	return results
 }
 
-func (p *parser[T]) processResults(root string) error {
-	for {
-		select {
-		case r := <-p.results:
-			if err := p.handleResult(r, root); err != nil {
-				return err
-			}
-
-		case <-p.done:
-			return nil
-
-		case <-p.ctx.Done():
-			return p.ctx.Err()
-		}
+func (p *parser[T]) fetchOne(j job) result[T] {
+	r := result[T]{name: j.dep.Name, depth: j.depth}
+	if r.err = p.ctx.Err(); r.err != nil {
+		return r
+	}
+	r.info, r.err = p.fetch(p.ctx, j.dep, p.opts.Refresh)
+	if r.err == nil && !p.fetched[r.name] {
+		r.meta = p.nodeMeta(r.info)
 	}
+	return r
 }
 
-func (p *parser[T]) handleResult(r result[T], root string) error {
-	defer p.adjustInflight(-1)
+func (p *parser[T]) nodeMeta(info T) map[string]any {
+	meta := enrichMetadata(p.ctx, info, p.opts)
+	if p.opts.PURLType != "" {
+		name := cmp.Or(info.ToRepoInfo().Name, info.GetName())
+		meta["purl"] = PackageURL(p.opts.PURLType, name, info.GetVersion())
+	}
+	// Registries give licenses free-form; keep those not recognized as is.
+	if l, ok := meta["license"].(string); ok {
+		meta["license"] = cmp.Or(license.Normalize(l), l)
+	}
+	return meta
+}
 
+func (p *parser[T]) handleResult(r result[T]) error {
 	if r.err != nil {
-		if r.name == root {
+		var nc *integrations.NotCachedError
+		if errors.As(r.err, &nc) {
+			p.uncached = append(p.uncached, nc.Key)
+		} else if p.roots[r.name] {
 			return r.err
 		}
 		p.opts.Logger("failed to fetch %s: %v", r.name, r.err)
+		if n, ok := p.g.Node(r.name); ok && !p.fetched[r.name] {
+			n.Meta = dag.Metadata{"fetch_error": r.err.Error(), "fetch_error_class": ErrorClass(r.err)}
+		}
 		return nil
 	}
 
-	p.addNode(r)
-	p.submitDependencies(r)
+	// A package fetched again for more extras only contributes new edges.
+	if !p.fetched[r.name] {
+		p.fetched[r.name] = true
+		_ = p.g.AddNode(dag.Node{ID: r.name})
+		if n, ok := p.g.Node(r.name); ok && r.meta != nil {
+			n.Meta = r.meta
+		}
+	}
 	return nil
 }
 
-func (p *parser[T]) addNode(r result[T]) {
-	_ = p.g.AddNode(dag.Node{ID: r.name})
-
-	p.mu.Lock()
-	p.nodeCount++
-	p.mu.Unlock()
-
-	meta := enrichMetadata(p.ctx, r.info, p.opts)
-	if len(meta) > 0 {
-		p.mu.Lock()
-		p.meta[r.name] = meta
-		p.mu.Unlock()
+// dependencyJobs adds the edges from a fetched package to the dependencies
+// the crawl follows, and returns them as candidate jobs one level deeper.
+func (p *parser[T]) dependencyJobs(r result[T]) []job {
+	if r.err != nil {
+		return nil
 	}
-}
-
-func (p *parser[T]) submitDependencies(r result[T]) {
-	if r.depth >= p.opts.MaxDepth {
-		return
+	var deps []Dependency
+	for _, dep := range r.info.GetDependencies() {
+		dep.Kind = cmp.Or(dep.Kind, KindRuntime)
+		if slices.Contains(p.opts.IncludeKinds, dep.Kind) && (dep.Kind != KindDev || r.depth == 0) {
+			deps = append(deps, dep)
+		}
 	}
-
-	deps := r.info.GetDependencies()
 	if len(deps) == 0 {
-		return
+		return nil
+	}
+	if r.depth >= p.opts.MaxDepth {
+		p.truncated[r.name] = true
+		return nil
 	}
 
-	// Add edges and collect jobs
-	p.mu.Lock()
-	nodeCount := p.nodeCount
-	p.mu.Unlock()
-
-	var toSubmit []job
+	jobs := make([]job, 0, len(deps))
 	for _, dep := range deps {
-		_ = p.g.AddNode(dag.Node{ID: dep})
-		_ = p.g.AddEdge(dag.Edge{From: r.name, To: dep})
-
-		if int(nodeCount) < p.opts.MaxNodes {
-			toSubmit = append(toSubmit, job{name: dep, depth: r.depth + 1})
+		_ = p.g.AddNode(dag.Node{ID: dep.Name})
+		if !slices.Contains(p.g.Children(r.name), dep.Name) {
+			_ = p.g.AddEdge(dag.Edge{From: r.name, To: dep.Name, Meta: edgeMeta(dep)})
 		}
+		jobs = append(jobs, job{dep: dep, depth: r.depth + 1})
 	}
+	return jobs
+}
 
-	if len(toSubmit) == 0 {
-		return
+func graphMeta(roots []Dependency, opts Options) dag.Metadata {
+	options := map[string]any{
+		"max_depth":     opts.MaxDepth,
+		"max_nodes":     opts.MaxNodes,
+		"include_kinds": opts.IncludeKinds,
+	}
+	if len(opts.Features) > 0 {
+		options["features"] = opts.Features
+	}
+	meta := dag.Metadata{"options": options}
+	if len(roots) == 1 && opts.RootName == "" {
+		meta["root"] = roots[0].Name
+	} else {
+		names := make([]string, len(roots))
+		for i, r := range roots {
+			names[i] = r.Name
+		}
+		meta["roots"] = names
+		if opts.RootName != "" {
+			meta["root"] = opts.RootName
+		}
 	}
+	return meta
+}
 
-	// Reserve a slot for the async submitter BEFORE spawning it.
-	// This prevents processResults from exiting prematurely.
-	p.mu.Lock()
-	p.inflight++
-	p.mu.Unlock()
+func edgeMeta(dep Dependency) map[string]any {
+	meta := map[string]any{"kind": dep.Kind}
+	if dep.Constraint != "" {
+		meta["constraint"] = dep.Constraint
+	}
+	if dep.Feature != "" {
+		meta["feature"] = dep.Feature
+	}
+	return meta
+}
 
-	go func() {
-		defer p.adjustInflight(-1) // release slot when done submitting
+// unionExtras adds the extras of more to have and reports whether any
+// were new.
+func unionExtras(have, more []string) ([]string, bool) {
+	grew := false
+	for _, e := range more {
+		if !slices.Contains(have, e) {
+			have = append(slices.Clip(have), e)
+			grew = true
+		}
+	}
+	return have, grew
+}
 
-		for _, j := range toSubmit {
-			p.submit(j)
+// markTruncated flags the nodes whose dependencies the crawl left out:
+// packages at MaxDepth that have some, and packages that were never fetched
+// because MaxNodes was reached. Packages without meta from a fetch get a
+// versionless Package URL, so they stay apart from packages of the same
+// name in other ecosystems.
+func (p *parser[T]) markTruncated() {
+	for _, n := range p.g.Nodes() {
+		if n.ID == p.opts.RootName {
+			continue
+		}
+		if _, ok := n.Meta["purl"]; !ok && p.opts.PURLType != "" {
+			n.Meta["purl"] = PackageURL(p.opts.PURLType, n.ID, "")
+		}
+		_, failed := n.Meta["fetch_error"]
+		if p.truncated[n.ID] || !p.fetched[n.ID] && !failed {
+			n.Meta["truncated"] = true
 		}
-	}()
+	}
 }
 
-func (p *parser[T]) applyMetadata() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	for id, m := range p.meta {
-		if n, ok := p.g.Node(id); ok {
-			n.Meta = m
+// markUnsatisfied flags the edges whose constraint the version of the
+// package they lead to does not satisfy: no published version did, so the
+// latest was fetched, or another parent's constraint reached it first.
+// Constraints and versions the scheme cannot parse are left alone.
+func (p *parser[T]) markUnsatisfied() {
+	s := p.opts.VersionScheme
+	if s == nil {
+		return
+	}
+	for _, e := range p.g.Edges() {
+		constraint, _ := e.Meta["constraint"].(string)
+		n, ok := p.g.Node(e.To)
+		if constraint == "" || !ok {
+			continue
+		}
+		raw, _ := n.Meta["version"].(string)
+		c, err := s.ParseConstraint(constraint)
+		if err != nil {
+			continue
+		}
+		v, err := s.ParseVersion(raw)
+		if err != nil || c.Check(v) {
+			continue
 		}
+		e.Meta["constraint_unsatisfied"] = true
+		p.opts.Logger("%s %s does not satisfy %s of %s", e.To, raw, constraint, e.From)
+	}
+}
+
+// Classes of fetch errors, as recorded in the "fetch_error_class" node meta.
+const (
+	ErrorClassNotFound    = "not-found"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassRateLimited = "rate-limited"
+	ErrorClassNetwork     = "network"
+	ErrorClassNotCached   = "not-cached"
+	ErrorClassOther       = "other"
+)
+
+// ErrorClass tells why a package could not be fetched.
+func ErrorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, integrations.ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, integrations.ErrNotCached):
+		return ErrorClassNotCached
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, integrations.ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, integrations.ErrNetwork):
+		return ErrorClassNetwork
 	}
+	return ErrorClassOther
 }
 
 func enrichMetadata(ctx context.Context, info PackageInfo, opts Options) map[string]any {
 	m := info.ToMetadata()
 	repo := info.ToRepoInfo()
+	repo.PURLType = cmp.Or(repo.PURLType, opts.PURLType)
 	for _, provider := range opts.MetadataProviders {
 		enriched, err := provider.Enrich(ctx, repo, opts.Refresh)
 		if err != nil {
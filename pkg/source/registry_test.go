@@ -0,0 +1,329 @@
+package source
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations"
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+func TestParse_Extras(t *testing.T) {
+	// app[web] -> server, client; client -> server[tls]; server[tls] -> tls.
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		mu.Lock()
+		fetched[dep.Name]++
+		mu.Unlock()
+
+		info := &fakeInfo{name: dep.Name}
+		switch dep.Name {
+		case "app":
+			info.deps = []Dependency{{Name: "client"}}
+			if slices.Contains(dep.Extras, "web") {
+				info.deps = append(info.deps, Dependency{Name: "server", Feature: "web"})
+			}
+		case "client":
+			info.deps = []Dependency{{Name: "server", Extras: []string{"tls"}}}
+		case "server":
+			if slices.Contains(dep.Extras, "tls") {
+				info.deps = []Dependency{{Name: "tls", Feature: "tls"}}
+			}
+		}
+		return info, nil
+	}
+
+	g, err := Parse(context.Background(), Roots([]string{"app"}), Options{Features: []string{"web"}}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := g.Children("server"); !slices.Equal(got, []string{"tls"}) {
+		t.Errorf("server children = %v, want [tls] from the union of extras", got)
+	}
+	if g.EdgeCount() != 4 {
+		t.Errorf("EdgeCount = %d, want 4", g.EdgeCount())
+	}
+	if g.NodeCount() != 4 {
+		t.Errorf("NodeCount = %d, want 4", g.NodeCount())
+	}
+	for _, e := range g.Edges() {
+		want := map[string]string{"app>server": "web", "server>tls": "tls"}[e.From+">"+e.To]
+		if got, _ := e.Meta["feature"].(string); got != want {
+			t.Errorf("edge %s->%s feature = %q, want %q", e.From, e.To, got, want)
+		}
+	}
+	if fetched["client"] != 1 || fetched["tls"] != 1 {
+		t.Errorf("fetch counts = %v", fetched)
+	}
+	if g.Meta()["root"] != "app" {
+		t.Errorf("graph meta root = %v, want app", g.Meta()["root"])
+	}
+	if options, _ := g.Meta()["options"].(map[string]any); !slices.Equal(options["features"].([]string), []string{"web"}) {
+		t.Errorf("graph meta options = %v", g.Meta()["options"])
+	}
+}
+
+func TestParse_IncludeKinds(t *testing.T) {
+	// app -> lib, test (dev), types (peer); lib -> lint (dev), native (build).
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		info := &fakeInfo{name: dep.Name}
+		switch dep.Name {
+		case "app":
+			info.deps = []Dependency{{Name: "lib"}, {Name: "test", Kind: KindDev}, {Name: "types", Kind: KindPeer}}
+		case "lib":
+			info.deps = []Dependency{{Name: "lint", Kind: KindDev}, {Name: "native", Kind: KindBuild}}
+		}
+		return info, nil
+	}
+
+	tests := []struct {
+		name  string
+		kinds []string
+		want  []string
+	}{
+		{"default", nil, []string{"app", "lib"}},
+		{"all", Kinds, []string{"app", "lib", "native", "test", "types"}},
+		{"dev", []string{KindRuntime, KindDev}, []string{"app", "lib", "test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Parse(context.Background(), Roots([]string{"app"}), Options{IncludeKinds: tt.kinds}, fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, n := range g.Nodes() {
+				got = append(got, n.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("nodes = %v, want %v", got, tt.want)
+			}
+			for _, e := range g.Edges() {
+				want := map[string]string{"app>test": KindDev, "app>types": KindPeer, "lib>native": KindBuild}[e.From+">"+e.To]
+				if got := e.Meta["kind"]; got != cmp.Or(want, KindRuntime) {
+					t.Errorf("edge %s->%s kind = %v, want %v", e.From, e.To, got, cmp.Or(want, KindRuntime))
+				}
+			}
+		})
+	}
+}
+
+func TestParse_MultipleRoots(t *testing.T) {
+	// api -> http, json; worker -> json, queue; json is shared.
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	registry := map[string][]Dependency{
+		"api":    {{Name: "http"}, {Name: "json"}},
+		"worker": {{Name: "json"}, {Name: "queue"}},
+	}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		mu.Lock()
+		fetched[dep.Name]++
+		mu.Unlock()
+		return &fakeInfo{name: dep.Name, deps: registry[dep.Name]}, nil
+	}
+
+	g, err := Parse(context.Background(), Roots([]string{"api", "worker"}), Options{}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NodeCount() != 5 || g.EdgeCount() != 4 {
+		t.Errorf("got %d nodes, %d edges, want 5 and 4", g.NodeCount(), g.EdgeCount())
+	}
+	if fetched["json"] != 1 {
+		t.Errorf("json fetched %d times, want 1", fetched["json"])
+	}
+	if roots, _ := g.Meta()["roots"].([]string); !slices.Equal(roots, []string{"api", "worker"}) {
+		t.Errorf("graph meta roots = %v", g.Meta()["roots"])
+	}
+
+	g, err = Parse(context.Background(), Roots([]string{"api", "worker"}), Options{RootName: "svc"}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slices.Sorted(slices.Values(g.Children("svc"))); !slices.Equal(got, []string{"api", "worker"}) {
+		t.Errorf("svc children = %v, want [api worker]", got)
+	}
+	if g.Meta()["root"] != "svc" {
+		t.Errorf("graph meta root = %v, want svc", g.Meta()["root"])
+	}
+
+	if _, err := Parse(context.Background(), Roots([]string{"api"}), Options{RootName: "api"}, fetch); err == nil {
+		t.Error("expected error for a root name that clashes with a package")
+	}
+}
+
+func TestParse_IncompleteNodes(t *testing.T) {
+	// app -> gone, slow, lib -> deep -> deeper
+	registry := map[string][]Dependency{
+		"app":  {{Name: "gone"}, {Name: "slow"}, {Name: "lib"}},
+		"lib":  {{Name: "deep"}},
+		"deep": {{Name: "deeper"}},
+	}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		switch dep.Name {
+		case "gone":
+			return nil, fmt.Errorf("%w: gone", integrations.ErrNotFound)
+		case "slow":
+			return nil, fmt.Errorf("%w: %w", integrations.ErrNetwork, context.DeadlineExceeded)
+		}
+		return &fakeInfo{name: dep.Name, deps: registry[dep.Name]}, nil
+	}
+
+	g, err := Parse(context.Background(), Roots([]string{"app"}), Options{MaxDepth: 2}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id                string
+		class             string
+		truncated, failed bool
+	}{
+		{id: "app"},
+		{id: "lib"},
+		{id: "gone", failed: true, class: ErrorClassNotFound},
+		{id: "slow", failed: true, class: ErrorClassTimeout},
+		{id: "deep", truncated: true},
+	}
+	for _, tt := range tests {
+		n, ok := g.Node(tt.id)
+		if !ok {
+			t.Fatalf("missing node %s", tt.id)
+		}
+		if _, failed := n.Meta["fetch_error"]; failed != tt.failed {
+			t.Errorf("%s: fetch_error = %v, want set: %v", tt.id, n.Meta["fetch_error"], tt.failed)
+		}
+		if class, _ := n.Meta["fetch_error_class"].(string); class != tt.class {
+			t.Errorf("%s: fetch_error_class = %q, want %q", tt.id, class, tt.class)
+		}
+		if truncated := n.Meta["truncated"] == true; truncated != tt.truncated {
+			t.Errorf("%s: truncated = %v, want %v", tt.id, truncated, tt.truncated)
+		}
+	}
+
+	g, err = Parse(context.Background(), Roots([]string{"app"}), Options{MaxNodes: 1}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := g.Node("lib"); n.Meta["truncated"] != true {
+		t.Errorf("lib meta = %v, want truncated past MaxNodes", n.Meta)
+	}
+}
+
+func TestParse_MaxNodesDeterministic(t *testing.T) {
+	// root -> z, m, a; a -> b; m -> c; z -> d. With room for five packages
+	// the crawl keeps the root, all of depth 1 and, by name, only b of
+	// depth 2, however the fetches are scheduled.
+	registry := map[string][]Dependency{
+		"root": {{Name: "z"}, {Name: "m"}, {Name: "a"}},
+		"a":    {{Name: "b"}},
+		"m":    {{Name: "c"}},
+		"z":    {{Name: "d"}},
+	}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		if dep.Name == "a" {
+			time.Sleep(10 * time.Millisecond) // finish last
+		}
+		return &fakeInfo{name: dep.Name, deps: registry[dep.Name]}, nil
+	}
+
+	for range 3 {
+		g, err := Parse(context.Background(), Roots([]string{"root"}), Options{MaxNodes: 5}, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fetched []string
+		for _, n := range g.Nodes() {
+			if n.Meta["truncated"] != true {
+				fetched = append(fetched, n.ID)
+			}
+		}
+		slices.Sort(fetched)
+		if !slices.Equal(fetched, []string{"a", "b", "m", "root", "z"}) {
+			t.Fatalf("fetched %v, want [a b m root z]", fetched)
+		}
+	}
+}
+
+func TestParse_OfflineMissing(t *testing.T) {
+	registry := map[string][]Dependency{"app": {{Name: "a"}, {Name: "b"}}}
+	fetch := func(ctx context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		if !integrations.IsOffline(ctx) {
+			t.Error("fetch called without an offline context")
+		}
+		if dep.Name != "app" {
+			return nil, &integrations.NotCachedError{Key: "reg:" + dep.Name}
+		}
+		return &fakeInfo{name: dep.Name, deps: registry[dep.Name]}, nil
+	}
+
+	_, err := Parse(context.Background(), Roots([]string{"app"}), Options{Offline: true}, fetch)
+	if !errors.Is(err, integrations.ErrNotCached) {
+		t.Fatalf("got %v, want ErrNotCached", err)
+	}
+	if !strings.Contains(err.Error(), "reg:a, reg:b") {
+		t.Errorf("error %q should list the missing keys", err)
+	}
+}
+
+func TestParse_NormalizesLicense(t *testing.T) {
+	licenses := map[string]string{"app": "Apache License, Version 2.0", "a": "MIT/Apache-2.0", "b": "Custom"}
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		info := &fakeInfo{name: dep.Name, license: licenses[dep.Name]}
+		if dep.Name == "app" {
+			info.deps = []Dependency{{Name: "a"}, {Name: "b"}}
+		}
+		return info, nil
+	}
+
+	g, err := Parse(context.Background(), Roots([]string{"app"}), Options{}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"app": "Apache-2.0", "a": "MIT OR Apache-2.0", "b": "Custom"}
+	for id, l := range want {
+		n, _ := g.Node(id)
+		if got := n.Meta["license"]; got != l {
+			t.Errorf("%s license = %v, want %q", id, got, l)
+		}
+	}
+}
+
+func TestParse_ConstraintUnsatisfied(t *testing.T) {
+	fetch := func(_ context.Context, dep Dependency, _ bool) (*fakeInfo, error) {
+		info := &fakeInfo{name: dep.Name}
+		if dep.Name == "app" {
+			// Every package is at 1.0.0.
+			info.deps = []Dependency{{Name: "a", Constraint: "^1.0"}, {Name: "b", Constraint: "^2.0"}, {Name: "c", Constraint: "github:org/c"}}
+		}
+		return info, nil
+	}
+
+	logged := 0
+	g, err := Parse(context.Background(), Roots([]string{"app"}), Options{
+		VersionScheme: version.NPM,
+		Logger:        func(string, ...any) { logged++ },
+	}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range g.Edges() {
+		if got, want := e.Meta["constraint_unsatisfied"] == true, e.To == "b"; got != want {
+			t.Errorf("%s -> %s: constraint_unsatisfied = %v, want %v", e.From, e.To, got, want)
+		}
+	}
+	if logged != 1 {
+		t.Errorf("logged %d warnings, want 1", logged)
+	}
+}
@@ -0,0 +1,31 @@
+package source
+
+import (
+	"context"
+
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+// VersionLister lists the published versions of a package.
+type VersionLister func(ctx context.Context, name string, refresh bool) ([]string, error)
+
+// ResolveVersion picks the highest published version of dep that satisfies
+// its constraint under scheme. It returns an empty version, meaning "use
+// the latest", when dep is unconstrained, when the constraint is not
+// something the scheme understands (git URLs, tags, path dependencies) or
+// when no published version satisfies it. Parse marks the edges of the
+// last case "constraint_unsatisfied" when Options name the scheme.
+func ResolveVersion(ctx context.Context, dep Dependency, scheme version.Scheme, list VersionLister, refresh bool) (string, error) {
+	if dep.Constraint == "" {
+		return "", nil
+	}
+	versions, err := list(ctx, dep.Name, refresh)
+	if err != nil {
+		return "", err
+	}
+	v, err := version.Highest(scheme, versions, dep.Constraint)
+	if err != nil {
+		return "", nil
+	}
+	return v, nil
+}
@@ -0,0 +1,43 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/version"
+)
+
+func TestResolveVersion(t *testing.T) {
+	list := func(_ context.Context, name string, _ bool) ([]string, error) {
+		if name == "broken" {
+			return nil, errors.New("boom")
+		}
+		return []string{"1.2.0", "1.4.1", "2.0.0", "2.1.0-beta.1"}, nil
+	}
+
+	tests := []struct {
+		dep     Dependency
+		want    string
+		wantErr bool
+	}{
+		{Dependency{Name: "pkg"}, "", false},
+		{Dependency{Name: "pkg", Constraint: "^1.2"}, "1.4.1", false},
+		{Dependency{Name: "pkg", Constraint: ">=2"}, "2.0.0", false},
+		{Dependency{Name: "pkg", Constraint: "^3"}, "", false},
+		{Dependency{Name: "pkg", Constraint: "github:org/pkg"}, "", false},
+		{Dependency{Name: "broken", Constraint: "^1"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dep.Name+" "+tt.dep.Constraint, func(t *testing.T) {
+			got, err := ResolveVersion(context.Background(), tt.dep, version.NPM, list, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
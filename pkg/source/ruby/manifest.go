@@ -0,0 +1,86 @@
+package ruby
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+var (
+	gemRE      = regexp.MustCompile(`^gem\s+["']([^"']+)["']`)
+	quotedRE   = regexp.MustCompile(`["']([^"']*)["']`)
+	requireRE  = regexp.MustCompile(`^\s*(?:[<>=!~]+\s*)?\d`)
+	groupRE    = regexp.MustCompile(`^group\s+(.+?)\s+do\b`)
+	devGroupRE = regexp.MustCompile(`:(development|test)\b|["'](development|test)["']`)
+	blockRE    = regexp.MustCompile(`\bdo(\s*\|[^|]*\|)?\s*$`)
+)
+
+// manifestFiles mark a directory as a project.
+var manifestFiles = []string{"Gemfile"}
+
+// readManifest reads the gem declarations of a project's Gemfile. The
+// Gemfile is Ruby, so this only understands the common forms: top-level
+// gem lines, group blocks and group: options. Gems of development and
+// test groups are dev dependencies.
+func readManifest(dir string) (*source.Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "Gemfile"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &source.Manifest{Name: source.ProjectName(dir), File: "Gemfile"}
+	var blocks []bool // open do-blocks; true for development/test groups
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case line == "end":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		case groupRE.MatchString(line):
+			blocks = append(blocks, devGroupRE.MatchString(groupRE.FindStringSubmatch(line)[1]))
+		case blockRE.MatchString(line):
+			blocks = append(blocks, false)
+		}
+
+		match := gemRE.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		kind := source.KindRuntime
+		if _, opts, ok := strings.Cut(line, "group:"); slices.Contains(blocks, true) || ok && devGroupRE.MatchString(opts) {
+			kind = source.KindDev
+		}
+		name := strings.ToLower(match[1])
+		i := slices.IndexFunc(m.Dependencies, func(d source.Dependency) bool { return d.Name == name })
+		switch {
+		case i < 0:
+			m.Dependencies = append(m.Dependencies, source.Dependency{Name: name, Constraint: gemRequirements(line), Kind: kind})
+		case kind == source.KindRuntime:
+			m.Dependencies[i].Kind = kind
+		}
+	}
+	return m, sc.Err()
+}
+
+// gemRequirements joins the version arguments of a gem line, so
+// gem "rails", "~> 7.1", ">= 7.1.2" yields "~> 7.1, >= 7.1.2".
+func gemRequirements(line string) string {
+	var reqs []string
+	for _, m := range quotedRE.FindAllStringSubmatch(line, -1)[1:] {
+		if requireRE.MatchString(m[1]) {
+			reqs = append(reqs, strings.TrimSpace(m[1]))
+		}
+	}
+	return strings.Join(reqs, ", ")
+}
@@ -0,0 +1,60 @@
+package ruby
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestReadManifest(t *testing.T) {
+	gemfile := `source "https://rubygems.org"
+
+ruby "3.3.0"
+
+gem "rails", "~> 7.1"
+gem 'pg', '>= 1.1'
+gem "bootsnap", require: false
+gem "debug", group: :development
+
+group :development, :test do
+  gem "rspec-rails"
+  platforms :mri do
+    gem "byebug"
+  end
+end
+
+group :production do
+  gem "Puma"
+end
+`
+	dir := filepath.Join(t.TempDir(), "shop")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Gemfile"), []byte(gemfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "shop" {
+		t.Errorf("name = %s", m.Name)
+	}
+	want := []source.Dependency{
+		{Name: "rails", Constraint: "~> 7.1", Kind: source.KindRuntime},
+		{Name: "pg", Constraint: ">= 1.1", Kind: source.KindRuntime},
+		{Name: "bootsnap", Kind: source.KindRuntime},
+		{Name: "debug", Kind: source.KindDev},
+		{Name: "rspec-rails", Kind: source.KindDev},
+		{Name: "byebug", Kind: source.KindDev},
+		{Name: "puma", Kind: source.KindRuntime},
+	}
+	if !reflect.DeepEqual(m.Dependencies, want) {
+		t.Errorf("deps = %v, want %v", m.Dependencies, want)
+	}
+}
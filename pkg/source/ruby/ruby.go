@@ -7,6 +7,7 @@ import (
 	"github.com/matzehuels/stacktower/pkg/dag"
 	"github.com/matzehuels/stacktower/pkg/integrations/rubygems"
 	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
 )
 
 type Parser struct {
@@ -21,12 +22,18 @@ func NewParser(cacheTTL time.Duration) (*Parser, error) {
 	return &Parser{client: c}, nil
 }
 
-func (p *Parser) Parse(ctx context.Context, gem string, opts source.Options) (*dag.DAG, error) {
-	return source.Parse(ctx, gem, opts, p.fetch)
+func (p *Parser) Parse(ctx context.Context, gems []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLGem
+	opts.VersionScheme = version.RubyGems
+	return source.ParseRoots(ctx, source.Roots(gems), opts, manifestFiles, readManifest, p.fetch)
 }
 
-func (p *Parser) fetch(ctx context.Context, name string, refresh bool) (*gemInfo, error) {
-	info, err := p.client.FetchGem(ctx, name, refresh)
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*gemInfo, error) {
+	v, err := source.ResolveVersion(ctx, dep, version.RubyGems, p.client.FetchVersions, refresh)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.client.FetchGemVersion(ctx, dep.Name, v, refresh)
 	if err != nil {
 		return nil, err
 	}
@@ -37,9 +44,9 @@ type gemInfo struct {
 	*rubygems.GemInfo
 }
 
-func (gi *gemInfo) GetName() string           { return gi.Name }
-func (gi *gemInfo) GetVersion() string        { return gi.Version }
-func (gi *gemInfo) GetDependencies() []string { return gi.Dependencies }
+func (gi *gemInfo) GetName() string                      { return gi.Name }
+func (gi *gemInfo) GetVersion() string                   { return gi.Version }
+func (gi *gemInfo) GetDependencies() []source.Dependency { return gi.Dependencies }
 
 func (gi *gemInfo) ToMetadata() map[string]any {
 	m := map[string]any{"version": gi.Version}
@@ -0,0 +1,129 @@
+package rust
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+// enabledDependencies returns the dependencies a crate builds with when
+// the given features are enabled, following Cargo's feature rules:
+//
+//   - "dep:x" enables the optional dependency x
+//   - "x/f" enables dependency x and its feature f
+//   - "x?/f" enables feature f of x only if x is enabled anyway
+//   - any other entry names another feature, or an optional dependency
+//     through its implicit feature, which exists unless the dependency is
+//     ever referred to as "dep:x"
+//
+// Optional dependencies that are enabled carry the feature that named them
+// in Feature; features of dependencies are added to their Extras.
+func enabledDependencies(deps []source.Dependency, table map[string][]string, features []string) []source.Dependency {
+	r := &featureResolver{
+		deps:     deps,
+		table:    table,
+		explicit: make(map[string]bool),
+		on:       make(map[string]string),
+		extras:   make(map[string][]string),
+		weak:     make(map[string][]string),
+		seen:     make(map[string]bool),
+	}
+	for entries := range maps.Values(table) {
+		for _, e := range entries {
+			if dep, ok := strings.CutPrefix(e, "dep:"); ok {
+				r.explicit[dep] = true
+			}
+		}
+	}
+	for _, f := range features {
+		r.enableFeature(f)
+	}
+
+	var out []source.Dependency
+	for _, d := range deps {
+		if d.Optional {
+			feature, ok := r.on[d.Name]
+			if !ok {
+				continue
+			}
+			d.Feature = feature
+		}
+		d.Extras = slices.Clone(d.Extras)
+		for _, e := range slices.Concat(r.extras[d.Name], r.weak[d.Name]) {
+			if !slices.Contains(d.Extras, e) {
+				d.Extras = append(d.Extras, e)
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+type featureResolver struct {
+	deps     []source.Dependency
+	table    map[string][]string
+	explicit map[string]bool // optional dependencies without an implicit feature
+
+	on     map[string]string   // optional dependency -> feature that enabled it
+	extras map[string][]string // dependency -> features enabled on it
+	weak   map[string][]string // dependency -> features enabled if it is
+	seen   map[string]bool
+}
+
+func (r *featureResolver) enableFeature(name string) {
+	if r.seen[name] {
+		return
+	}
+	r.seen[name] = true
+
+	entries, ok := r.table[name]
+	if !ok {
+		if r.hasImplicitFeature(name) {
+			r.enableDep(name, name)
+		}
+		return
+	}
+	for _, e := range entries {
+		r.enableEntry(e, name)
+	}
+}
+
+func (r *featureResolver) enableEntry(entry, from string) {
+	if dep, ok := strings.CutPrefix(entry, "dep:"); ok {
+		r.enableDep(dep, from)
+		return
+	}
+	if dep, feature, ok := strings.Cut(entry, "/"); ok {
+		if weak, ok := strings.CutSuffix(dep, "?"); ok {
+			r.weak[weak] = append(r.weak[weak], feature)
+			return
+		}
+		r.enableDep(dep, from)
+		r.extras[dep] = append(r.extras[dep], feature)
+		return
+	}
+	if _, isFeature := r.table[entry]; !isFeature && r.hasImplicitFeature(entry) {
+		r.enableDep(entry, from)
+		return
+	}
+	r.enableFeature(entry)
+}
+
+func (r *featureResolver) enableDep(name, from string) {
+	if !r.isOptional(name) {
+		return
+	}
+	if _, ok := r.on[name]; !ok {
+		r.on[name] = from
+	}
+}
+
+func (r *featureResolver) hasImplicitFeature(name string) bool {
+	return !r.explicit[name] && r.isOptional(name)
+}
+
+func (r *featureResolver) isOptional(name string) bool {
+	return slices.ContainsFunc(r.deps, func(d source.Dependency) bool { return d.Optional && d.Name == name })
+}
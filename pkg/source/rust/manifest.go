@@ -0,0 +1,116 @@
+package rust
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+type cargoManifest struct {
+	Package struct {
+		Name       string `toml:"name"`
+		Version    any    `toml:"version"`
+		Repository any    `toml:"repository"`
+		Homepage   any    `toml:"homepage"`
+	} `toml:"package"`
+	Dependencies      map[string]any      `toml:"dependencies"`
+	DevDependencies   map[string]any      `toml:"dev-dependencies"`
+	BuildDependencies map[string]any      `toml:"build-dependencies"`
+	Features          map[string][]string `toml:"features"`
+	Workspace         struct {
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+// manifestFiles mark a directory as a project.
+var manifestFiles = []string{"Cargo.toml"}
+
+// readManifest reads the dependencies of a project's Cargo.toml, with
+// [dev-dependencies] and [build-dependencies] tagged by kind. Optional
+// dependencies are only kept when one of features enables them
+// and renamed dependencies resolve to the crate they point at. A virtual
+// workspace manifest contributes its [workspace.dependencies].
+func readManifest(dir string, features []string) (*source.Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, err
+	}
+	var cm cargoManifest
+	if err := toml.Unmarshal(data, &cm); err != nil {
+		return nil, fmt.Errorf("Cargo.toml: %w", err)
+	}
+
+	m := &source.Manifest{
+		Name:        cm.Package.Name,
+		ProjectURLs: map[string]string{},
+		File:        "Cargo.toml",
+	}
+	if m.Name == "" {
+		m.Name = source.ProjectName(dir)
+	}
+	// Fields inherited from the workspace are tables, not strings.
+	if v, ok := cm.Package.Version.(string); ok {
+		m.Version = v
+	}
+	if v, ok := cm.Package.Repository.(string); ok {
+		m.ProjectURLs["repository"] = v
+	}
+	if v, ok := cm.Package.Homepage.(string); ok {
+		m.HomePage = v
+	}
+
+	deps := cm.Dependencies
+	if cm.Package.Name == "" && deps == nil {
+		deps = cm.Workspace.Dependencies
+	}
+	// Features refer to dependencies by their key, which differs from the
+	// crate name for renamed dependencies.
+	crateNames := make(map[string]string)
+	all := cargoDependencies(deps, source.KindRuntime, crateNames)
+	all = append(all, cargoDependencies(cm.DevDependencies, source.KindDev, crateNames)...)
+	all = append(all, cargoDependencies(cm.BuildDependencies, source.KindBuild, crateNames)...)
+
+	for _, dep := range enabledDependencies(all, cm.Features, features) {
+		if pkg, ok := crateNames[dep.Name]; ok {
+			dep.Name = pkg
+		}
+		m.Dependencies = append(m.Dependencies, dep)
+	}
+	return m, nil
+}
+
+// cargoDependencies reads one dependency table, recording the crate
+// behind each renamed dependency in crateNames.
+func cargoDependencies(deps map[string]any, kind string, crateNames map[string]string) []source.Dependency {
+	var out []source.Dependency
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		dep := source.Dependency{Name: name, Extras: []string{"default"}, Kind: kind}
+		switch spec := deps[name].(type) {
+		case string:
+			dep.Constraint = spec
+		case map[string]any:
+			dep.Optional, _ = spec["optional"].(bool)
+			if pkg, ok := spec["package"].(string); ok {
+				crateNames[name] = pkg
+			}
+			dep.Constraint, _ = spec["version"].(string)
+			if defaults, ok := spec["default-features"].(bool); ok && !defaults {
+				dep.Extras = nil
+			}
+			list, _ := spec["features"].([]any)
+			for _, f := range list {
+				if s, ok := f.(string); ok {
+					dep.Extras = append(dep.Extras, s)
+				}
+			}
+		}
+		out = append(out, dep)
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package rust
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matzehuels/stacktower/pkg/source"
+)
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		cargo    string
+		features []string
+		wantName string
+		wantDeps []source.Dependency
+	}{
+		{
+			name: "package",
+			cargo: `
+[package]
+name = "svc"
+version.workspace = true
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+tokio = "1"
+json = { package = "serde_json", version = "1" }
+tracing = { version = "0.1", optional = true }
+
+[dev-dependencies]
+proptest = "1"
+
+[build-dependencies]
+cc = "1"
+`,
+			wantName: "svc",
+			wantDeps: []source.Dependency{
+				{Name: "serde_json", Constraint: "1", Extras: []string{"default"}, Kind: source.KindRuntime},
+				{Name: "serde", Constraint: "1", Extras: []string{"default", "derive"}, Kind: source.KindRuntime},
+				{Name: "tokio", Constraint: "1", Extras: []string{"default"}, Kind: source.KindRuntime},
+				{Name: "proptest", Constraint: "1", Extras: []string{"default"}, Kind: source.KindDev},
+				{Name: "cc", Constraint: "1", Extras: []string{"default"}, Kind: source.KindBuild},
+			},
+		},
+		{
+			name: "features",
+			cargo: `
+[package]
+name = "svc"
+
+[dependencies]
+tokio = { version = "1", default-features = false }
+tracing = { version = "0.1", optional = true }
+rustls = { version = "0.23", optional = true }
+serde = { version = "1", optional = true }
+
+[features]
+default = ["tls"]
+tls = ["dep:rustls", "tokio/net"]
+observe = ["tracing", "serde?/std"]
+`,
+			features: []string{"default", "observe"},
+			wantName: "svc",
+			wantDeps: []source.Dependency{
+				{Name: "rustls", Constraint: "0.23", Extras: []string{"default"}, Feature: "tls", Optional: true, Kind: source.KindRuntime},
+				{Name: "tokio", Constraint: "1", Extras: []string{"net"}, Kind: source.KindRuntime},
+				{Name: "tracing", Constraint: "0.1", Extras: []string{"default"}, Feature: "observe", Optional: true, Kind: source.KindRuntime},
+			},
+		},
+		{
+			name: "virtual workspace",
+			cargo: `
+[workspace]
+members = ["crates/*"]
+
+[workspace.dependencies]
+anyhow = "1"
+`,
+			wantName: "ws",
+			wantDeps: []source.Dependency{{Name: "anyhow", Constraint: "1", Extras: []string{"default"}, Kind: source.KindRuntime}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "ws")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(tt.cargo), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := readManifest(dir, tt.features)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Name != tt.wantName {
+				t.Errorf("name = %s, want %s", m.Name, tt.wantName)
+			}
+			if !reflect.DeepEqual(m.Dependencies, tt.wantDeps) {
+				t.Errorf("deps = %v, want %v", m.Dependencies, tt.wantDeps)
+			}
+		})
+	}
+}
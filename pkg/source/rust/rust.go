@@ -7,6 +7,7 @@ import (
 	"github.com/matzehuels/stacktower/pkg/dag"
 	"github.com/matzehuels/stacktower/pkg/integrations/crates"
 	"github.com/matzehuels/stacktower/pkg/source"
+	"github.com/matzehuels/stacktower/pkg/version"
 )
 
 type Parser struct {
@@ -21,25 +22,38 @@ func NewParser(cacheTTL time.Duration) (*Parser, error) {
 	return &Parser{client: c}, nil
 }
 
-func (p *Parser) Parse(ctx context.Context, crate string, opts source.Options) (*dag.DAG, error) {
-	return source.Parse(ctx, crate, opts, p.fetch)
+// Parse crawls crate with its default features plus opts.Features.
+func (p *Parser) Parse(ctx context.Context, crates []string, opts source.Options) (*dag.DAG, error) {
+	opts.PURLType = source.PURLCargo
+	opts.VersionScheme = version.Cargo
+	opts.Features = append([]string{"default"}, opts.Features...)
+	readProject := func(dir string) (*source.Manifest, error) { return readManifest(dir, opts.Features) }
+	return source.ParseRoots(ctx, source.Roots(crates), opts, manifestFiles, readProject, p.fetch)
 }
 
-func (p *Parser) fetch(ctx context.Context, name string, refresh bool) (*crateInfo, error) {
-	info, err := p.client.FetchCrate(ctx, name, refresh)
+func (p *Parser) fetch(ctx context.Context, dep source.Dependency, refresh bool) (*crateInfo, error) {
+	v, err := source.ResolveVersion(ctx, dep, version.Cargo, p.client.FetchVersions, refresh)
 	if err != nil {
 		return nil, err
 	}
-	return &crateInfo{info}, nil
+	info, err := p.client.FetchCrateVersion(ctx, dep.Name, v, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return &crateInfo{CrateInfo: info, features: dep.Extras}, nil
 }
 
 type crateInfo struct {
 	*crates.CrateInfo
+	features []string
 }
 
-func (ci *crateInfo) GetName() string           { return ci.Name }
-func (ci *crateInfo) GetVersion() string        { return ci.Version }
-func (ci *crateInfo) GetDependencies() []string { return ci.Dependencies }
+func (ci *crateInfo) GetName() string    { return ci.Name }
+func (ci *crateInfo) GetVersion() string { return ci.Version }
+
+func (ci *crateInfo) GetDependencies() []source.Dependency {
+	return enabledDependencies(ci.Dependencies, ci.Features, ci.features)
+}
 
 func (ci *crateInfo) ToMetadata() map[string]any {
 	m := map[string]any{"version": ci.Version}
@@ -1,8 +1,13 @@
 package rust
 
 import (
+	"maps"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/matzehuels/stacktower/pkg/integrations/crates"
+	"github.com/matzehuels/stacktower/pkg/source"
 )
 
 func TestNewParser(t *testing.T) {
@@ -14,3 +19,47 @@ func TestNewParser(t *testing.T) {
 		t.Error("client not initialized")
 	}
 }
+
+func TestCrateInfo_GetDependencies(t *testing.T) {
+	info := &crates.CrateInfo{
+		Name: "reqwest",
+		Dependencies: []source.Dependency{
+			{Name: "http", Constraint: "^1"},
+			{Name: "serde_json", Constraint: "^1", Optional: true},
+			{Name: "rustls", Constraint: "^0.23", Optional: true},
+			{Name: "hyper", Constraint: "^1", Extras: []string{"default"}, Optional: true},
+		},
+		Features: map[string][]string{
+			"default": {"hyper"},
+			"json":    {"serde_json"},
+			"tls":     {"rustls", "hyper?/tls"},
+		},
+	}
+
+	tests := []struct {
+		features []string
+		want     map[string]string
+	}{
+		{nil, map[string]string{"http": ""}},
+		{[]string{"default"}, map[string]string{"http": "", "hyper": "default"}},
+		{[]string{"json", "tls"}, map[string]string{"http": "", "serde_json": "json", "rustls": "tls"}},
+	}
+
+	for _, tt := range tests {
+		ci := &crateInfo{CrateInfo: info, features: tt.features}
+		got := map[string]string{}
+		for _, d := range ci.GetDependencies() {
+			got[d.Name] = d.Feature
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("features %v: got %v, want %v", tt.features, got, tt.want)
+		}
+	}
+
+	ci := &crateInfo{CrateInfo: info, features: []string{"default", "tls"}}
+	for _, d := range ci.GetDependencies() {
+		if d.Name == "hyper" && !slices.Equal(d.Extras, []string{"default", "tls"}) {
+			t.Errorf("hyper extras = %v, want weak tls feature applied", d.Extras)
+		}
+	}
+}
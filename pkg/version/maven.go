@@ -0,0 +1,369 @@
+package version
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// mavenItem is a component of a Maven version: a number, a qualifier or a
+// sublist started by "-" or by a switch between digits and letters, as in
+// Maven's ComparableVersion.
+type mavenItem struct {
+	num   string // digits without leading zeros, for numbers
+	qual  string // qualifier, with aliases resolved; for qualifiers
+	list  []*mavenItem
+	isNum bool
+	isSub bool
+}
+
+// mavenQualifiers are the well-known qualifiers in release order; "" is a
+// release (ga, final). Others sort after sp, alphabetically.
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenAliases = map[string]string{"ga": "", "final": "", "release": "", "cr": "rc"}
+
+const mavenReleaseIndex = 5
+
+func newMavenNumber(s string) *mavenItem {
+	s = strings.TrimLeft(s, "0")
+	return &mavenItem{num: s, isNum: true}
+}
+
+func newMavenQualifier(s string, followedByDigit bool) *mavenItem {
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[s]; ok {
+		s = alias
+	}
+	return &mavenItem{qual: s}
+}
+
+// isNull reports whether the item compares equal to a missing one: zero,
+// a release qualifier or an empty sublist.
+func (it *mavenItem) isNull() bool {
+	switch {
+	case it.isNum:
+		return it.num == ""
+	case it.isSub:
+		return len(it.list) == 0
+	}
+	return it.qual == ""
+}
+
+// qualifierKey orders qualifiers: known ones by their index, others after
+// them alphabetically.
+func qualifierKey(q string) string {
+	if i := slices.Index(mavenQualifiers, q); i >= 0 {
+		return strconv.Itoa(i)
+	}
+	return strconv.Itoa(len(mavenQualifiers)) + "-" + q
+}
+
+// compare orders it against o, which is nil for a missing item.
+func (it *mavenItem) compare(o *mavenItem) int {
+	switch {
+	case it.isNum:
+		switch {
+		case o == nil:
+			if it.num == "" {
+				return 0
+			}
+			return 1
+		case o.isNum:
+			return cmp.Or(cmp.Compare(len(it.num), len(o.num)), strings.Compare(it.num, o.num))
+		}
+		return 1 // 1.1 > 1-sp, 1.1 > 1-1
+	case it.isSub:
+		if o == nil {
+			if len(it.list) == 0 {
+				return 0
+			}
+			return it.list[0].compare(nil)
+		}
+		switch {
+		case o.isNum:
+			return -1
+		case !o.isSub:
+			return 1
+		}
+		for i := range max(len(it.list), len(o.list)) {
+			var a, b *mavenItem
+			if i < len(it.list) {
+				a = it.list[i]
+			}
+			if i < len(o.list) {
+				b = o.list[i]
+			}
+			var c int
+			if a == nil {
+				c = -b.compare(nil)
+			} else {
+				c = a.compare(b)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	switch {
+	case o == nil:
+		return strings.Compare(qualifierKey(it.qual), strconv.Itoa(mavenReleaseIndex))
+	case o.isNum, o.isSub:
+		return -1
+	}
+	return strings.Compare(qualifierKey(it.qual), qualifierKey(o.qual))
+}
+
+// normalize drops the null items at the end of each sublist, so 1.0.0 ==
+// 1 and 1.0-ga == 1.
+func (it *mavenItem) normalize() {
+	for i := len(it.list) - 1; i >= 0; i-- {
+		last := it.list[i]
+		if last.isSub {
+			last.normalize()
+		}
+		if last.isNull() {
+			it.list = slices.Delete(it.list, i, i+1)
+		} else if !last.isSub {
+			break
+		}
+	}
+}
+
+// mavenVersion is a Maven version, ordered like Maven's ComparableVersion:
+// 1.0-alpha1 < 1.0-rc1 < 1.0-SNAPSHOT < 1.0 == 1.0.RELEASE < 1.0-sp1 <
+// 1.0-jre < 1.0.1.
+type mavenVersion struct {
+	items *mavenItem
+	raw   string
+}
+
+func parseMavenVersion(s string) (*mavenVersion, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" || strings.ContainsAny(raw, " ,[]()") {
+		return nil, invalidVersion(s)
+	}
+	body := strings.ToLower(raw)
+
+	root := &mavenItem{isSub: true}
+	list := root
+	start, digit := 0, false
+	add := func(end int, followedByDigit bool) {
+		part := body[start:end]
+		switch {
+		case part == "":
+			list.list = append(list.list, newMavenNumber("0"))
+		case digit:
+			list.list = append(list.list, newMavenNumber(part))
+		default:
+			list.list = append(list.list, newMavenQualifier(part, followedByDigit))
+		}
+	}
+	sublist := func() {
+		sub := &mavenItem{isSub: true}
+		list.list = append(list.list, sub)
+		list = sub
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		isDigit := c >= '0' && c <= '9'
+		switch {
+		case c == '.':
+			add(i, false)
+			start = i + 1
+		case c == '-':
+			add(i, false)
+			start = i + 1
+			sublist()
+		case isDigit && !digit && i > start:
+			add(i, true)
+			start = i
+			sublist()
+		case !isDigit && digit && i > start:
+			add(i, false)
+			start = i
+			sublist()
+		}
+		if c != '.' && c != '-' {
+			digit = isDigit
+		}
+	}
+	if start < len(body) {
+		add(len(body), false)
+	}
+	root.normalize()
+	return &mavenVersion{items: root, raw: raw}, nil
+}
+
+// Prerelease reports whether the version has an alpha, beta, milestone,
+// rc or snapshot qualifier.
+func (v *mavenVersion) Prerelease() bool {
+	var pre func(*mavenItem) bool
+	pre = func(it *mavenItem) bool {
+		if it.isSub {
+			return slices.ContainsFunc(it.list, pre)
+		}
+		i := slices.Index(mavenQualifiers, it.qual)
+		return !it.isNum && i >= 0 && i < mavenReleaseIndex
+	}
+	return pre(v.items)
+}
+
+func (v *mavenVersion) String() string { return v.raw }
+
+func (v *mavenVersion) Compare(other Version) int {
+	return v.items.compare(other.(*mavenVersion).items)
+}
+
+// intervalBound is one end of a Maven or NuGet version range.
+type intervalBound struct {
+	v         Version
+	inclusive bool
+}
+
+// interval is a range such as [1.0,2.0); nil bounds are open.
+type interval struct {
+	lower, upper *intervalBound
+}
+
+func (r interval) contains(v Version) bool {
+	if r.lower != nil {
+		if c := v.Compare(r.lower.v); c < 0 || c == 0 && !r.lower.inclusive {
+			return false
+		}
+	}
+	if r.upper != nil {
+		if c := v.Compare(r.upper.v); c > 0 || c == 0 && !r.upper.inclusive {
+			return false
+		}
+	}
+	return true
+}
+
+// intervalConstraint is a union of ranges in the interval notation of
+// Maven and NuGet.
+type intervalConstraint struct {
+	ranges []interval
+	parse  func(string) (Version, error)
+	raw    string
+}
+
+func (c *intervalConstraint) Check(v Version) bool {
+	if _, err := c.parse(v.String()); err != nil {
+		return false
+	}
+	return slices.ContainsFunc(c.ranges, func(r interval) bool { return r.contains(v) })
+}
+
+func (c *intervalConstraint) String() string { return c.raw }
+
+// parseIntervals reads ranges such as [1.0], [1.0,2.0), (,1.0],[1.2,) and
+// a bare version, which bare turns into a range. An empty constraint
+// allows any version.
+func parseIntervals(s string, parse func(string) (Version, error), bare func(Version) interval) (*intervalConstraint, error) {
+	c := &intervalConstraint{parse: parse, raw: strings.TrimSpace(s)}
+	rest := c.raw
+	if rest == "" {
+		c.ranges = []interval{{}}
+		return c, nil
+	}
+	if rest[0] != '[' && rest[0] != '(' {
+		v, err := parse(rest)
+		if err != nil {
+			return nil, invalidConstraint(s)
+		}
+		c.ranges = []interval{bare(v)}
+		return c, nil
+	}
+
+	for rest != "" {
+		end := strings.IndexAny(rest, "])")
+		if end < 0 || (rest[0] != '[' && rest[0] != '(') {
+			return nil, invalidConstraint(s)
+		}
+		body := rest[1:end]
+		lowerIn, upperIn := rest[0] == '[', rest[end] == ']'
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[end+1:]), ","))
+
+		var r interval
+		lo, hi, isRange := strings.Cut(body, ",")
+		if !isRange {
+			// [1.0] is exactly 1.0.
+			if !lowerIn || !upperIn {
+				return nil, invalidConstraint(s)
+			}
+			hi = lo
+		}
+		for _, b := range []struct {
+			text      string
+			inclusive bool
+			bound     **intervalBound
+		}{{lo, lowerIn, &r.lower}, {hi, upperIn, &r.upper}} {
+			if text := strings.TrimSpace(b.text); text != "" {
+				v, err := parse(text)
+				if err != nil {
+					return nil, invalidConstraint(s)
+				}
+				*b.bound = &intervalBound{v: v, inclusive: b.inclusive}
+			}
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+type mavenScheme struct{}
+
+func (mavenScheme) Name() string { return "maven" }
+func (mavenScheme) ParseVersion(s string) (Version, error) {
+	return parseMavenVersion(s)
+}
+
+// ParseConstraint understands Maven version ranges. A bare version is the
+// version Maven picks for a soft requirement: exactly that one.
+func (mavenScheme) ParseConstraint(s string) (Constraint, error) {
+	return parseIntervals(s, mavenScheme{}.ParseVersion, func(v Version) interval {
+		b := &intervalBound{v: v, inclusive: true}
+		return interval{lower: b, upper: b}
+	})
+}
+
+type nugetScheme struct{}
+
+func (nugetScheme) Name() string { return "nuget" }
+
+// ParseVersion accepts NuGet versions: up to four numeric components and a
+// prerelease label compared without regard to case. Build metadata is
+// ignored.
+func (nugetScheme) ParseVersion(s string) (Version, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "v") {
+		return nil, invalidVersion(s)
+	}
+	v, err := parseSemver(s)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range v.pre {
+		v.pre[i] = strings.ToLower(p)
+	}
+	return v, nil
+}
+
+// ParseConstraint understands NuGet version ranges. A bare version is a
+// minimum.
+func (nugetScheme) ParseConstraint(s string) (Constraint, error) {
+	return parseIntervals(s, nugetScheme{}.ParseVersion, func(v Version) interval {
+		return interval{lower: &intervalBound{v: v, inclusive: true}}
+	})
+}
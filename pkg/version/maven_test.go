@@ -0,0 +1,68 @@
+package version
+
+import "testing"
+
+func TestMavenVersionCompare(t *testing.T) {
+	ordered := []string{
+		"1.0-alpha1", "1.0-beta", "1.0-m2", "1.0-rc1", "1.0-SNAPSHOT", "1.0",
+		"1.0-sp1", "1.0-jre", "1.0.1", "5.2.0.RELEASE", "5.2.20.RELEASE", "5.3.18",
+		"31.1-android", "31.1-jre", "32.0.0-jre",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseMavenVersion(ordered[i-1])
+		b, _ := parseMavenVersion(ordered[i])
+		if a.Compare(b) >= 0 {
+			t.Errorf("%s should sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+	for _, pair := range [][2]string{{"1", "1.0.0"}, {"1.0", "1.0-ga"}, {"1.0", "1.0.FINAL"}, {"1.0-cr1", "1.0-rc1"}, {"1.0.0.10", "1.0.0.010"}} {
+		a, _ := parseMavenVersion(pair[0])
+		b, _ := parseMavenVersion(pair[1])
+		if a.Compare(b) != 0 {
+			t.Errorf("%s should equal %s", pair[0], pair[1])
+		}
+	}
+	a, _ := parseMavenVersion("1.0.0.9")
+	b, _ := parseMavenVersion("1.0.0.10")
+	if a.Compare(b) >= 0 {
+		t.Error("1.0.0.9 should sort before 1.0.0.10")
+	}
+	if pre, _ := parseMavenVersion("2.0.0-M3"); !pre.Prerelease() {
+		t.Error("2.0.0-M3 should be a prerelease")
+	}
+	if v, _ := parseMavenVersion("31.1-jre"); v.Prerelease() {
+		t.Error("31.1-jre should not be a prerelease")
+	}
+}
+
+func TestMavenConstraint(t *testing.T) {
+	checkAll(t, Maven, []constraintTest{
+		{"[1.0,2.0)", []string{"1.0", "1.9.9", "2.0-rc1"}, []string{"0.9", "2.0"}},
+		{"(,1.0]", []string{"0.1", "1.0"}, []string{"1.0.1"}},
+		{"[1.2]", []string{"1.2", "1.2.0"}, []string{"1.2.1"}},
+		{"(,1.0],[1.2,)", []string{"1.0", "1.2", "3"}, []string{"1.1"}},
+		{"5.3.18", []string{"5.3.18"}, []string{"5.3.19"}},
+	})
+	for _, s := range []string{"[1.0,2.0", "[1.0)", "[a b]"} {
+		if _, err := Maven.ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q): expected an error", s)
+		}
+	}
+}
+
+func TestNuGetVersion(t *testing.T) {
+	a, _ := NuGet.ParseVersion("1.0.0-Beta")
+	b, _ := NuGet.ParseVersion("1.0.0-beta+build.5")
+	if a.Compare(b) != 0 {
+		t.Error("prerelease labels should compare without case")
+	}
+	c, _ := NuGet.ParseVersion("1.0.0.1")
+	if a.Compare(c) >= 0 || !a.Prerelease() {
+		t.Error("1.0.0-beta should be a prerelease before 1.0.0.1")
+	}
+	checkAll(t, NuGet, []constraintTest{
+		{"13.0.1", []string{"13.0.1", "13.0.3"}, []string{"12.0.3"}},
+		{"[1.0,2.0)", []string{"1.0.0", "1.5"}, []string{"2.0.0"}},
+		{"(1.0,)", []string{"1.0.1"}, []string{"1.0"}},
+	})
+}
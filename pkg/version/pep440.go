@@ -0,0 +1,254 @@
+package version
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var pep440RE = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pep440 is a Python package version. Missing pre, post and dev parts are
+// represented by has* flags rather than sentinel numbers.
+type pep440 struct {
+	epoch   int
+	release []int
+	pre     int // 0 a, 1 b, 2 rc
+	preN    int
+	hasPre  bool
+	post    int
+	hasPost bool
+	dev     int
+	hasDev  bool
+	local   string
+	raw     string
+}
+
+func parsePEP440(s string) (*pep440, error) {
+	raw := strings.TrimSpace(s)
+	m := pep440RE.FindStringSubmatch(raw)
+	if m == nil {
+		return nil, invalidVersion(s)
+	}
+	v := &pep440{raw: raw, local: strings.ToLower(m[10])}
+	v.epoch, _ = strconv.Atoi(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		n, _ := strconv.Atoi(part)
+		v.release = append(v.release, n)
+	}
+	if m[3] != "" {
+		v.hasPre = true
+		switch strings.ToLower(m[3]) {
+		case "a", "alpha":
+			v.pre = 0
+		case "b", "beta":
+			v.pre = 1
+		default:
+			v.pre = 2
+		}
+		v.preN, _ = strconv.Atoi(m[4])
+	}
+	if m[5] != "" || m[6] != "" {
+		v.hasPost = true
+		v.post, _ = strconv.Atoi(m[5] + m[7])
+	}
+	if m[8] != "" {
+		v.hasDev = true
+		v.dev, _ = strconv.Atoi(m[9])
+	}
+	return v, nil
+}
+
+func (v *pep440) Prerelease() bool { return v.hasPre || v.hasDev }
+func (v *pep440) String() string   { return v.raw }
+
+func (v *pep440) Compare(other Version) int {
+	o := other.(*pep440)
+	if c := v.comparePublic(o); c != 0 {
+		return c
+	}
+	return strings.Compare(v.local, o.local)
+}
+
+func (v *pep440) comparePublic(o *pep440) int {
+	if c := cmp.Compare(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+	if c := compareRelease(v.release, o.release); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.preKey(), o.preKey()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.postKey(), o.postKey()); c != 0 {
+		return c
+	}
+	return cmp.Compare(v.devKey(), o.devKey())
+}
+
+// preKey sorts a bare dev release (1.0.dev1) before any prerelease and a
+// final release after all of them.
+func (v *pep440) preKey() int {
+	switch {
+	case v.hasPre:
+		return v.pre<<20 | v.preN
+	case v.hasDev && !v.hasPost:
+		return -1
+	}
+	return 3 << 20
+}
+
+func (v *pep440) postKey() int {
+	if v.hasPost {
+		return v.post
+	}
+	return -1
+}
+
+func (v *pep440) devKey() int {
+	if v.hasDev {
+		return v.dev
+	}
+	return 1 << 30
+}
+
+func compareRelease(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		if c := cmp.Compare(component(a, i), component(b, i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+type pep440Specifier struct {
+	op       string
+	v        *pep440
+	wildcard bool
+	raw      string
+}
+
+func (s pep440Specifier) check(v *pep440) bool {
+	switch s.op {
+	case "===":
+		return v.raw == s.raw
+	case "==", "!=":
+		match := false
+		if s.wildcard {
+			match = v.epoch == s.v.epoch && releasePrefix(v.release, s.v.release)
+		} else {
+			match = v.comparePublic(s.v) == 0 && (s.v.local == "" || v.local == s.v.local)
+		}
+		return match == (s.op == "==")
+	case "~=":
+		prefix := s.v.release[:len(s.v.release)-1]
+		return v.comparePublic(s.v) >= 0 && v.epoch == s.v.epoch && releasePrefix(v.release, prefix)
+	}
+
+	c := v.comparePublic(s.v)
+	switch s.op {
+	case "<=":
+		return c <= 0
+	case ">=":
+		return c >= 0
+	case "<":
+		// <V excludes prereleases of V itself unless V is one.
+		return c < 0 && (s.v.Prerelease() || !v.Prerelease() || !v.sameRelease(s.v))
+	case ">":
+		// >V excludes post and local releases of V unless V is a post release.
+		return c > 0 && (s.v.hasPost || !v.hasPost || !v.sameBase(s.v))
+	}
+	return false
+}
+
+func (v *pep440) sameRelease(o *pep440) bool {
+	return v.epoch == o.epoch && compareRelease(v.release, o.release) == 0
+}
+
+func (v *pep440) sameBase(o *pep440) bool {
+	return v.sameRelease(o) && v.preKey() == o.preKey()
+}
+
+// releasePrefix reports whether release starts with prefix, padding the
+// release with zeros (== 1.0.* matches 1).
+func releasePrefix(release, prefix []int) bool {
+	for i, n := range prefix {
+		if component(release, i) != n {
+			return false
+		}
+	}
+	return true
+}
+
+type pep440Constraint struct {
+	specs []pep440Specifier
+	raw   string
+}
+
+func (c *pep440Constraint) Check(v Version) bool {
+	pv, ok := v.(*pep440)
+	if !ok {
+		return false
+	}
+	return !slices.ContainsFunc(c.specs, func(s pep440Specifier) bool { return !s.check(pv) })
+}
+
+func (c *pep440Constraint) String() string { return c.raw }
+
+var pep440Operators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+type pep440Scheme struct{}
+
+func (pep440Scheme) Name() string                           { return "pep440" }
+func (pep440Scheme) ParseVersion(s string) (Version, error) { return parsePEP440(s) }
+
+// ParseConstraint understands PEP 440 specifier sets such as
+// ">=2.0,<3,!=2.1.*" and "~=1.4.2". The legacy parenthesized form
+// "(>=2.0)" is accepted as well.
+func (pep440Scheme) ParseConstraint(s string) (Constraint, error) {
+	raw := strings.TrimSpace(s)
+	c := &pep440Constraint{raw: raw}
+	body := strings.TrimSuffix(strings.TrimPrefix(raw, "("), ")")
+	if strings.TrimSpace(body) == "" {
+		return c, nil
+	}
+	for _, part := range strings.Split(body, ",") {
+		part = strings.TrimSpace(part)
+		op := ""
+		for _, candidate := range pep440Operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return nil, invalidConstraint(s)
+		}
+		operand := strings.TrimSpace(part[len(op):])
+		spec := pep440Specifier{op: op, raw: operand}
+		if op == "===" {
+			c.specs = append(c.specs, spec)
+			continue
+		}
+		if rest, ok := strings.CutSuffix(operand, ".*"); ok {
+			if op != "==" && op != "!=" {
+				return nil, invalidConstraint(s)
+			}
+			spec.wildcard = true
+			operand = rest
+		}
+		v, err := parsePEP440(operand)
+		if err != nil || (op == "~=" && len(v.release) < 2) {
+			return nil, invalidConstraint(s)
+		}
+		spec.v = v
+		c.specs = append(c.specs, spec)
+	}
+	return c, nil
+}
@@ -0,0 +1,56 @@
+package version
+
+import "testing"
+
+func TestPEP440Compare(t *testing.T) {
+	ordered := []string{
+		"1.0.dev1", "1.0a1", "1.0a2.dev1", "1.0a2", "1.0b1", "1.0rc1",
+		"1.0", "1.0+local", "1.0.post1.dev1", "1.0.post1", "1.1", "1!0.5",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, err := parsePEP440(ordered[i-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := parsePEP440(ordered[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Compare(b) >= 0 {
+			t.Errorf("%s should sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	a, _ := parsePEP440("1.0.0")
+	b, _ := parsePEP440("1.0")
+	if a.Compare(b) != 0 {
+		t.Error("1.0.0 should equal 1.0")
+	}
+	c, _ := parsePEP440("1.0-ALPHA.3")
+	if !c.hasPre || c.pre != 0 || c.preN != 3 {
+		t.Errorf("alternative spelling not normalized: %+v", c)
+	}
+}
+
+func TestPEP440Constraint(t *testing.T) {
+	checkAll(t, PEP440, []constraintTest{
+		{">=2.0,<3", []string{"2.0", "2.31.0"}, []string{"1.9", "3.0"}},
+		{"~=1.4.2", []string{"1.4.2", "1.4.9"}, []string{"1.5.0", "1.4.1"}},
+		{"~=2.2", []string{"2.2", "2.9"}, []string{"3.0"}},
+		{"==1.1.*", []string{"1.1", "1.1.5"}, []string{"1.2"}},
+		{"!=1.5.*", []string{"1.4", "1.6"}, []string{"1.5.1"}},
+		{"==2.0", []string{"2.0.0", "2.0+cpu"}, []string{"2.0.1"}},
+		{"<2.0", []string{"1.9"}, []string{"2.0rc1", "2.0"}},
+		{"<2.0rc2", []string{"2.0rc1"}, nil},
+		{">1.0", []string{"1.1"}, []string{"1.0.post1"}},
+		{">1.0.post1", []string{"1.0.post2"}, nil},
+		{"(>=1.0)", []string{"1.0"}, nil},
+		{"", []string{"0.1"}, nil},
+	})
+
+	for _, bad := range []string{"~=1", ">=1.*", "latest"} {
+		if _, err := PEP440.ParseConstraint(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
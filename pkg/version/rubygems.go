@@ -0,0 +1,198 @@
+package version
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var gemSegmentRE = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+var gemVersionRE = regexp.MustCompile(`^[0-9]+(\.[0-9a-zA-Z]+)*(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// gemVersion is a RubyGems version. Segments are numbers or strings; any
+// string segment makes the version a prerelease.
+type gemVersion struct {
+	segments []any
+	raw      string
+}
+
+func parseGemVersion(s string) (*gemVersion, error) {
+	raw := strings.TrimSpace(s)
+	if !gemVersionRE.MatchString(raw) {
+		return nil, invalidVersion(s)
+	}
+	// RubyGems treats "-" as the start of a prerelease: 1.0-a == 1.0.pre.a.
+	body := strings.ReplaceAll(raw, "-", ".pre.")
+	v := &gemVersion{raw: raw}
+	for _, seg := range gemSegmentRE.FindAllString(body, -1) {
+		if n, err := strconv.Atoi(seg); err == nil {
+			v.segments = append(v.segments, n)
+		} else {
+			v.segments = append(v.segments, seg)
+		}
+	}
+	return v, nil
+}
+
+func (v *gemVersion) Prerelease() bool {
+	return slices.ContainsFunc(v.segments, func(s any) bool { _, ok := s.(string); return ok })
+}
+
+func (v *gemVersion) String() string { return v.raw }
+
+// canonical drops trailing zeros from the release and prerelease parts,
+// so 1.0 == 1 and 1.0.a == 1.a.
+func (v *gemVersion) canonical() []any {
+	release := len(v.segments)
+	for i, s := range v.segments {
+		if _, ok := s.(string); ok {
+			release = i
+			break
+		}
+	}
+	head := trimZeros(v.segments[:release])
+	tail := trimZeros(v.segments[release:])
+	return append(slices.Clone(head), tail...)
+}
+
+func trimZeros(segs []any) []any {
+	for len(segs) > 0 {
+		if n, ok := segs[len(segs)-1].(int); !ok || n != 0 {
+			break
+		}
+		segs = segs[:len(segs)-1]
+	}
+	return segs
+}
+
+func (v *gemVersion) Compare(other Version) int {
+	a, b := v.canonical(), other.(*gemVersion).canonical()
+	for i := range max(len(a), len(b)) {
+		var x, y any = 0, 0
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareGemSegment(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareGemSegment orders strings before numbers, so 1.0.a < 1.0.
+func compareGemSegment(a, b any) int {
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	switch {
+	case aStr && bStr:
+		return strings.Compare(as, bs)
+	case aStr:
+		return -1
+	case bStr:
+		return 1
+	}
+	return cmp.Compare(a.(int), b.(int))
+}
+
+// bump is the exclusive upper bound of ~> v: drop prerelease segments and
+// the last release segment, then increment the new last segment.
+func (v *gemVersion) bump() *gemVersion {
+	var release []int
+	for _, s := range v.segments {
+		n, ok := s.(int)
+		if !ok {
+			break
+		}
+		release = append(release, n)
+	}
+	if len(release) > 1 {
+		release = release[:len(release)-1]
+	}
+	release[len(release)-1]++
+	b := &gemVersion{}
+	parts := make([]string, len(release))
+	for i, n := range release {
+		b.segments = append(b.segments, n)
+		parts[i] = strconv.Itoa(n)
+	}
+	b.raw = strings.Join(parts, ".")
+	return b
+}
+
+type gemRequirement struct {
+	op string
+	v  *gemVersion
+}
+
+func (r gemRequirement) check(v *gemVersion) bool {
+	c := v.Compare(r.v)
+	switch r.op {
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case "~>":
+		return c >= 0 && v.Compare(r.v.bump()) < 0
+	}
+	return c == 0
+}
+
+type gemConstraint struct {
+	reqs []gemRequirement
+	raw  string
+}
+
+func (c *gemConstraint) Check(v Version) bool {
+	gv, ok := v.(*gemVersion)
+	if !ok {
+		return false
+	}
+	return !slices.ContainsFunc(c.reqs, func(r gemRequirement) bool { return !r.check(gv) })
+}
+
+func (c *gemConstraint) String() string { return c.raw }
+
+var gemOperators = []string{">=", "<=", "!=", "~>", ">", "<", "="}
+
+type rubyGemsScheme struct{}
+
+func (rubyGemsScheme) Name() string                           { return "rubygems" }
+func (rubyGemsScheme) ParseVersion(s string) (Version, error) { return parseGemVersion(s) }
+
+// ParseConstraint understands Gem::Requirement strings: comma separated
+// requirements with =, !=, >, <, >=, <= and the pessimistic ~>. A bare
+// version means =.
+func (rubyGemsScheme) ParseConstraint(s string) (Constraint, error) {
+	raw := strings.TrimSpace(s)
+	c := &gemConstraint{raw: raw}
+	if raw == "" {
+		return c, nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		op := "="
+		for _, candidate := range gemOperators {
+			if rest, ok := strings.CutPrefix(part, candidate); ok {
+				op, part = candidate, strings.TrimSpace(rest)
+				break
+			}
+		}
+		v, err := parseGemVersion(part)
+		if err != nil {
+			return nil, invalidConstraint(s)
+		}
+		c.reqs = append(c.reqs, gemRequirement{op: op, v: v})
+	}
+	return c, nil
+}
@@ -0,0 +1,34 @@
+package version
+
+import "testing"
+
+func TestGemVersionCompare(t *testing.T) {
+	ordered := []string{"1.0.a", "1.0.b1", "1.0.rc1", "1.0", "1.0.1", "1.1", "1.10"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseGemVersion(ordered[i-1])
+		b, _ := parseGemVersion(ordered[i])
+		if a.Compare(b) >= 0 {
+			t.Errorf("%s should sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+	a, _ := parseGemVersion("1.0.0")
+	b, _ := parseGemVersion("1")
+	if a.Compare(b) != 0 {
+		t.Error("1.0.0 should equal 1")
+	}
+	if pre, _ := parseGemVersion("2.0.0.beta2"); !pre.Prerelease() {
+		t.Error("2.0.0.beta2 should be a prerelease")
+	}
+}
+
+func TestGemConstraint(t *testing.T) {
+	checkAll(t, RubyGems, []constraintTest{
+		{"~> 2.2", []string{"2.2", "2.9.1"}, []string{"3.0", "2.1"}},
+		{"~> 2.2.0", []string{"2.2.9"}, []string{"2.3.0"}},
+		{"~> 1.0, >= 1.0.2", []string{"1.0.2", "1.9"}, []string{"1.0.1", "2.0"}},
+		{">= 1.1, < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{"= 7.1.3", []string{"7.1.3"}, []string{"7.1.4"}},
+		{"7.1.3", []string{"7.1.3"}, nil},
+		{"!= 1.2", []string{"1.3"}, []string{"1.2.0"}},
+	})
+}
@@ -0,0 +1,463 @@
+package version
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// semver is a semantic version as used by npm, Cargo and Composer.
+// Composer allows a fourth numeric component, which compares like the
+// others; missing components count as zero.
+type semver struct {
+	nums []int
+	pre  []string
+	raw  string
+}
+
+func parseSemver(s string) (*semver, error) {
+	raw := strings.TrimSpace(s)
+	body := strings.TrimPrefix(strings.TrimPrefix(raw, "v"), "V")
+	body, _, _ = strings.Cut(body, "+")
+	body, pre, hasPre := strings.Cut(body, "-")
+
+	parts := strings.Split(body, ".")
+	if len(parts) > 4 {
+		return nil, invalidVersion(s)
+	}
+	v := &semver{raw: raw}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, invalidVersion(s)
+		}
+		v.nums = append(v.nums, n)
+	}
+	for len(v.nums) < 3 {
+		v.nums = append(v.nums, 0)
+	}
+	if hasPre {
+		if pre == "" {
+			return nil, invalidVersion(s)
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, nil
+}
+
+func (v *semver) Prerelease() bool { return len(v.pre) > 0 }
+func (v *semver) String() string   { return v.raw }
+
+func (v *semver) Compare(other Version) int {
+	o := other.(*semver)
+	for i := range max(len(v.nums), len(o.nums)) {
+		if c := cmp.Compare(component(v.nums, i), component(o.nums, i)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+	for i := range min(len(v.pre), len(o.pre)) {
+		if c := comparePreIdent(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.pre), len(o.pre))
+}
+
+func component(nums []int, i int) int {
+	if i < len(nums) {
+		return nums[i]
+	}
+	return 0
+}
+
+// comparePreIdent orders prerelease identifiers: numeric identifiers
+// compare numerically and sort before alphanumeric ones.
+func comparePreIdent(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+type comparator struct {
+	op string
+	v  *semver
+}
+
+func (c comparator) check(v *semver) bool {
+	r := v.Compare(c.v)
+	switch c.op {
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "!=":
+		return r != 0
+	}
+	return r == 0
+}
+
+// semverRange is a disjunction of conjunctions of comparators. An empty
+// conjunction allows every version.
+type semverRange struct {
+	sets [][]comparator
+	raw  string
+}
+
+func (r *semverRange) Check(v Version) bool {
+	sv, ok := v.(*semver)
+	if !ok {
+		return false
+	}
+	for _, set := range r.sets {
+		if !slices.ContainsFunc(set, func(c comparator) bool { return !c.check(sv) }) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *semverRange) String() string { return r.raw }
+
+// partial is a possibly incomplete version from a constraint: 1, 1.2,
+// 1.2.x and * leave the trailing components unspecified.
+type partial struct {
+	nums []int
+	pre  []string
+}
+
+func parsePartial(s string) (partial, error) {
+	body := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	body, _, _ = strings.Cut(body, "+")
+	body, pre, hasPre := strings.Cut(body, "-")
+
+	var p partial
+	for _, part := range strings.Split(body, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || len(p.nums) == 4 {
+			return partial{}, invalidConstraint(s)
+		}
+		p.nums = append(p.nums, n)
+	}
+	if hasPre {
+		if len(p.nums) < 3 || pre == "" {
+			return partial{}, invalidConstraint(s)
+		}
+		p.pre = strings.Split(pre, ".")
+	}
+	return p, nil
+}
+
+func (p partial) full() bool { return len(p.nums) >= 3 }
+
+// floor is the lowest version matching p.
+func (p partial) floor() *semver {
+	v := &semver{nums: slices.Clone(p.nums), pre: p.pre}
+	for len(v.nums) < 3 {
+		v.nums = append(v.nums, 0)
+	}
+	return v
+}
+
+// bump increments component i and zeroes everything after it. The result
+// carries the lowest prerelease so that prereleases of the bound itself
+// are excluded by a < comparison.
+func (p partial) bump(i int) *semver {
+	v := &semver{nums: make([]int, max(3, i+1)), pre: []string{"0"}}
+	copy(v.nums, p.nums[:i+1])
+	v.nums[i]++
+	return v
+}
+
+func (p partial) xrange() []comparator {
+	switch {
+	case len(p.nums) == 0:
+		return nil
+	case p.full():
+		return []comparator{{"=", p.floor()}}
+	}
+	return []comparator{{">=", p.floor()}, {"<", p.bump(len(p.nums) - 1)}}
+}
+
+func (p partial) caret() []comparator {
+	if len(p.nums) == 0 {
+		return nil
+	}
+	i := 0
+	for i < len(p.nums)-1 && i < 2 && p.nums[i] == 0 {
+		i++
+	}
+	return []comparator{{">=", p.floor()}, {"<", p.bump(i)}}
+}
+
+func (p partial) tilde() []comparator {
+	if len(p.nums) == 0 {
+		return nil
+	}
+	return []comparator{{">=", p.floor()}, {"<", p.bump(min(1, len(p.nums)-1))}}
+}
+
+func (p partial) compare(op string) []comparator {
+	if len(p.nums) == 0 {
+		if op == "<" || op == ">" {
+			return []comparator{{op, &semver{nums: []int{0, 0, 0}, pre: []string{"0"}}}}
+		}
+		return nil
+	}
+	if p.full() {
+		return []comparator{{op, p.floor()}}
+	}
+	switch op {
+	case ">":
+		b := p.bump(len(p.nums) - 1)
+		b.pre = nil
+		return []comparator{{">=", b}}
+	case "<=":
+		return []comparator{{"<", p.bump(len(p.nums) - 1)}}
+	}
+	return []comparator{{op, p.floor()}}
+}
+
+var operators = []string{">=", "<=", "!=", "==", "~>", ">", "<", "=", "^", "~"}
+
+func splitOperator(s string) (string, string) {
+	for _, op := range operators {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			return op, strings.TrimSpace(rest)
+		}
+	}
+	return "", s
+}
+
+// expand turns one operator and operand into comparators; def is used when
+// the operand has no operator.
+func expand(op, operand, def string, tilde func(partial) []comparator) ([]comparator, error) {
+	p, err := parsePartial(operand)
+	if err != nil {
+		return nil, err
+	}
+	if op == "" {
+		op = def
+	}
+	switch op {
+	case "^":
+		return p.caret(), nil
+	case "~", "~>":
+		return tilde(p), nil
+	case "=", "==":
+		return p.xrange(), nil
+	case "!=":
+		return []comparator{{"!=", p.floor()}}, nil
+	}
+	return p.compare(op), nil
+}
+
+var hyphenRE = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+
+func hyphenRange(s string) ([]comparator, bool, error) {
+	m := hyphenRE.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false, nil
+	}
+	lo, err := parsePartial(m[1])
+	if err != nil {
+		return nil, true, err
+	}
+	hi, err := parsePartial(m[2])
+	if err != nil {
+		return nil, true, err
+	}
+	return append(lo.compare(">="), hi.compare("<=")...), true, nil
+}
+
+// tokens splits a whitespace separated comparator list, joining operators
+// written apart from their operand (">= 1.2").
+func tokens(s string) []string {
+	var out []string
+	pending := ""
+	for _, f := range strings.Fields(s) {
+		if op, rest := splitOperator(f); op != "" && rest == "" {
+			pending += f
+			continue
+		}
+		out = append(out, pending+f)
+		pending = ""
+	}
+	if pending != "" {
+		out = append(out, pending)
+	}
+	return out
+}
+
+func isAny(s string) bool {
+	switch s {
+	case "", "*", "x", "X", "latest":
+		return true
+	}
+	return false
+}
+
+type npmScheme struct{}
+
+func (npmScheme) Name() string                           { return "npm" }
+func (npmScheme) ParseVersion(s string) (Version, error) { return parseSemver(s) }
+
+// ParseConstraint understands node-semver ranges: ||, hyphen ranges,
+// x-ranges, ^, ~ and comparison operators. Tags, URLs and aliases are
+// rejected.
+func (npmScheme) ParseConstraint(s string) (Constraint, error) {
+	r := &semverRange{raw: strings.TrimSpace(s)}
+	for _, alt := range strings.Split(r.raw, "||") {
+		alt = strings.TrimSpace(alt)
+		if isAny(alt) {
+			r.sets = append(r.sets, nil)
+			continue
+		}
+		set, ok, err := hyphenRange(alt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			for _, tok := range tokens(alt) {
+				op, operand := splitOperator(tok)
+				comps, err := expand(op, operand, "=", partial.tilde)
+				if err != nil {
+					return nil, invalidConstraint(s)
+				}
+				set = append(set, comps...)
+			}
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+type cargoScheme struct{}
+
+func (cargoScheme) Name() string                           { return "cargo" }
+func (cargoScheme) ParseVersion(s string) (Version, error) { return parseSemver(s) }
+
+// ParseConstraint understands Cargo requirements: comma separated
+// comparators where a bare version means ^.
+func (cargoScheme) ParseConstraint(s string) (Constraint, error) {
+	r := &semverRange{raw: strings.TrimSpace(s), sets: [][]comparator{nil}}
+	if isAny(r.raw) {
+		return r, nil
+	}
+	for _, part := range strings.Split(r.raw, ",") {
+		op, operand := splitOperator(strings.TrimSpace(part))
+		comps, err := expand(op, operand, "^", partial.tilde)
+		if err != nil {
+			return nil, invalidConstraint(s)
+		}
+		r.sets[0] = append(r.sets[0], comps...)
+	}
+	return r, nil
+}
+
+type composerScheme struct{}
+
+func (composerScheme) Name() string { return "composer" }
+
+// ParseVersion accepts Composer's spellings: a v prefix, four numeric
+// components and stability suffixes such as -beta2 or -RC1. Branch
+// versions (dev-main, 1.x-dev) are rejected.
+func (composerScheme) ParseVersion(s string) (Version, error) {
+	if strings.HasPrefix(s, "dev-") || strings.HasSuffix(s, "-dev") {
+		return nil, invalidVersion(s)
+	}
+	v, err := parseSemver(s)
+	if err != nil {
+		return nil, err
+	}
+	v.pre = composerStability(v.pre)
+	return v, nil
+}
+
+var stabilityRE = regexp.MustCompile(`^([a-zA-Z]+)\.?(\d*)$`)
+
+// composerStability splits "beta2" into "beta", "2" and lowercases the
+// label so that alpha < beta < rc.
+func composerStability(pre []string) []string {
+	if len(pre) != 1 {
+		return pre
+	}
+	m := stabilityRE.FindStringSubmatch(pre[0])
+	if m == nil {
+		return pre
+	}
+	out := []string{strings.ToLower(m[1])}
+	if m[2] != "" {
+		out = append(out, m[2])
+	}
+	return out
+}
+
+// ParseConstraint understands Composer constraints: || and | alternatives,
+// comma or space separated conjunctions, hyphen ranges, wildcards, ^ and
+// Composer's ~ (the last given component may increase). Stability flags
+// like @dev are ignored.
+func (composerScheme) ParseConstraint(s string) (Constraint, error) {
+	r := &semverRange{raw: strings.TrimSpace(s)}
+	normalized := strings.ReplaceAll(r.raw, "||", "|")
+	for _, alt := range strings.Split(normalized, "|") {
+		alt = strings.TrimSpace(alt)
+		if i := strings.IndexByte(alt, '@'); i >= 0 {
+			alt = strings.TrimSpace(alt[:i])
+		}
+		if isAny(alt) {
+			r.sets = append(r.sets, nil)
+			continue
+		}
+		set, ok, err := hyphenRange(alt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			for _, tok := range tokens(strings.ReplaceAll(alt, ",", " ")) {
+				op, operand := splitOperator(tok)
+				if strings.HasPrefix(operand, "dev-") || strings.HasSuffix(operand, "-dev") {
+					return nil, invalidConstraint(s)
+				}
+				comps, err := expand(op, operand, "=", composerTilde)
+				if err != nil {
+					return nil, invalidConstraint(s)
+				}
+				set = append(set, comps...)
+			}
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+func composerTilde(p partial) []comparator {
+	if len(p.nums) == 0 {
+		return nil
+	}
+	return []comparator{{">=", p.floor()}, {"<", p.bump(max(0, len(p.nums)-2))}}
+}
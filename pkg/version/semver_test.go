@@ -0,0 +1,120 @@
+package version
+
+import "testing"
+
+func TestSemverCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseSemver(ordered[i-1])
+		b, _ := parseSemver(ordered[i])
+		if a.Compare(b) >= 0 {
+			t.Errorf("%s should sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+	a, _ := parseSemver("v1.2.3+build.5")
+	b, _ := parseSemver("1.2.3")
+	if a.Compare(b) != 0 {
+		t.Error("build metadata and v prefix should not affect ordering")
+	}
+}
+
+func checkAll(t *testing.T, s Scheme, tests []constraintTest) {
+	t.Helper()
+	for _, tt := range tests {
+		c, err := s.ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("%s: ParseConstraint(%q): %v", s.Name(), tt.constraint, err)
+			continue
+		}
+		for _, raw := range tt.match {
+			v, err := s.ParseVersion(raw)
+			if err != nil {
+				t.Errorf("%s: ParseVersion(%q): %v", s.Name(), raw, err)
+			} else if !c.Check(v) {
+				t.Errorf("%s: %q should match %s", s.Name(), tt.constraint, raw)
+			}
+		}
+		for _, raw := range tt.reject {
+			v, err := s.ParseVersion(raw)
+			if err != nil {
+				t.Errorf("%s: ParseVersion(%q): %v", s.Name(), raw, err)
+			} else if c.Check(v) {
+				t.Errorf("%s: %q should not match %s", s.Name(), tt.constraint, raw)
+			}
+		}
+	}
+}
+
+type constraintTest struct {
+	constraint string
+	match      []string
+	reject     []string
+}
+
+func TestNPMConstraint(t *testing.T) {
+	checkAll(t, NPM, []constraintTest{
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0", "2.0.0-beta.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.9.9"}, []string{"2.0.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1", []string{"1.5.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, nil},
+		{"", []string{"1.0.0"}, nil},
+		{">= 1.2.0 < 1.4", []string{"1.2.0", "1.3.9"}, []string{"1.4.0", "1.1.9"}},
+		{"1.2.3 - 2.3", []string{"1.2.3", "2.3.9"}, []string{"2.4.0"}},
+		{"<1.0.0 || >=2.1.0", []string{"0.9.0", "2.1.0"}, []string{"1.5.0", "2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"=2.0.0", []string{"2.0.0"}, []string{"2.0.1"}},
+	})
+
+	for _, bad := range []string{"git+https://github.com/a/b.git", "file:../lib", "next", "npm:other@^1"} {
+		if _, err := NPM.ParseConstraint(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestCargoConstraint(t *testing.T) {
+	checkAll(t, Cargo, []constraintTest{
+		{"1.2.3", []string{"1.2.3", "1.8.0"}, []string{"2.0.0", "1.2.2"}},
+		{"0.4", []string{"0.4.20"}, []string{"0.5.0"}},
+		{"=1.0.1", []string{"1.0.1"}, []string{"1.0.2"}},
+		{"~1.2", []string{"1.2.5"}, []string{"1.3.0"}},
+		{">=1.2, <1.5", []string{"1.4.9"}, []string{"1.5.0"}},
+		{"1.*", []string{"1.9.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.1.0"}, nil},
+	})
+}
+
+func TestComposerConstraint(t *testing.T) {
+	checkAll(t, Composer, []constraintTest{
+		{"^2.0 || ^3.0", []string{"2.5.0", "v3.0.1"}, []string{"1.9.0", "4.0.0"}},
+		{"~1.2", []string{"1.2.0", "1.9.9"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">=7.4,<8.0", []string{"7.4.33"}, []string{"8.0.0"}},
+		{">=1.0 <1.1 || >=1.2", []string{"1.0.5", "1.2.0"}, []string{"1.1.0"}},
+		{"1.0.*", []string{"1.0.9"}, []string{"1.1.0"}},
+		{"1.0.2", []string{"1.0.2"}, []string{"1.0.3"}},
+		{"^1.0@dev", []string{"1.5.0"}, nil},
+		{"1.0 - 2.0", []string{"2.0.9"}, []string{"2.1.0"}},
+		{"!=1.5.0", []string{"1.4.0"}, []string{"1.5.0"}},
+	})
+
+	a, _ := Composer.ParseVersion("1.0.0-RC1")
+	b, _ := Composer.ParseVersion("1.0.0-beta2")
+	if a.Compare(b) <= 0 {
+		t.Error("RC1 should sort after beta2")
+	}
+	if _, err := Composer.ParseVersion("dev-main"); err == nil {
+		t.Error("expected error for branch version")
+	}
+	if _, err := Composer.ParseConstraint("dev-main"); err == nil {
+		t.Error("expected error for branch constraint")
+	}
+}
@@ -0,0 +1,100 @@
+// Package version parses versions and version constraints of the package
+// ecosystems stacktower crawls, so parsers can pick the release a
+// dependency's constraint would actually install.
+package version
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidVersion    = errors.New("invalid version")
+	ErrInvalidConstraint = errors.New("invalid constraint")
+	ErrNoMatch           = errors.New("no version satisfies constraint")
+)
+
+// Version is a parsed version. Versions only compare with versions of the
+// same scheme.
+type Version interface {
+	Compare(other Version) int
+	Prerelease() bool
+	String() string
+}
+
+// Constraint is a parsed version requirement.
+type Constraint interface {
+	Check(v Version) bool
+	String() string
+}
+
+// Scheme is a versioning convention: how versions are spelled and ordered
+// and how requirements on them are written.
+type Scheme interface {
+	Name() string
+	ParseVersion(s string) (Version, error)
+	ParseConstraint(s string) (Constraint, error)
+}
+
+var (
+	NPM      Scheme = npmScheme{}
+	Cargo    Scheme = cargoScheme{}
+	Composer Scheme = composerScheme{}
+	PEP440   Scheme = pep440Scheme{}
+	RubyGems Scheme = rubyGemsScheme{}
+	Maven    Scheme = mavenScheme{}
+	NuGet    Scheme = nugetScheme{}
+)
+
+// Highest returns the highest of versions that satisfies constraint.
+// Stable releases win over prereleases; a prerelease is only returned when
+// no stable release matches. Versions the scheme cannot parse are ignored.
+func Highest(s Scheme, versions []string, constraint string) (string, error) {
+	return pick(s, versions, constraint, 1)
+}
+
+// Lowest returns the lowest of versions that satisfies constraint, the one
+// NuGet restores. Stable releases win over prereleases as in Highest.
+func Lowest(s Scheme, versions []string, constraint string) (string, error) {
+	return pick(s, versions, constraint, -1)
+}
+
+// pick returns the matching version that compares to the others as order
+// says: 1 for the highest, -1 for the lowest.
+func pick(s Scheme, versions []string, constraint string, order int) (string, error) {
+	c, err := s.ParseConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	var best, bestPre Version
+	for _, raw := range versions {
+		v, err := s.ParseVersion(raw)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if v.Prerelease() {
+			if bestPre == nil || v.Compare(bestPre)*order > 0 {
+				bestPre = v
+			}
+		} else if best == nil || v.Compare(best)*order > 0 {
+			best = v
+		}
+	}
+
+	switch {
+	case best != nil:
+		return best.String(), nil
+	case bestPre != nil:
+		return bestPre.String(), nil
+	}
+	return "", fmt.Errorf("%w: %s %q", ErrNoMatch, s.Name(), constraint)
+}
+
+func invalidVersion(s string) error {
+	return fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+}
+
+func invalidConstraint(s string) error {
+	return fmt.Errorf("%w: %q", ErrInvalidConstraint, s)
+}
@@ -0,0 +1,61 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHighest(t *testing.T) {
+	tests := []struct {
+		scheme     Scheme
+		versions   []string
+		constraint string
+		want       string
+		err        error
+	}{
+		{NPM, []string{"1.0.0", "1.4.2", "2.0.0", "1.5.0-beta.1"}, "^1.0.0", "1.4.2", nil},
+		{NPM, []string{"1.0.0", "2.0.0-rc.1"}, ">=2.0.0-rc.0", "2.0.0-rc.1", nil},
+		{NPM, []string{"1.0.0", "not-a-version"}, "*", "1.0.0", nil},
+		{Cargo, []string{"0.4.1", "0.4.20", "0.5.0"}, "0.4", "0.4.20", nil},
+		{Composer, []string{"v2.1.0", "v3.0.0", "dev-main"}, "^2.0", "v2.1.0", nil},
+		{PEP440, []string{"1.26.0", "2.0.0", "2.1.0rc1"}, ">=1.21,<2", "1.26.0", nil},
+		{RubyGems, []string{"1.3.0", "1.4.2", "2.0.0"}, "~> 1.4", "1.4.2", nil},
+		{Maven, []string{"1.10.0", "2.0.0", "1.11.0", "2.1.0-SNAPSHOT"}, "", "2.0.0", nil},
+		{Maven, []string{"1.10.0", "2.0.0", "1.11.0"}, "[1.0,2.0)", "1.11.0", nil},
+		{NPM, []string{"1.0.0"}, "^2.0.0", "", ErrNoMatch},
+		{NPM, []string{"1.0.0"}, "github:user/repo", "", ErrInvalidConstraint},
+	}
+	for _, tt := range tests {
+		got, err := Highest(tt.scheme, tt.versions, tt.constraint)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s %q: err = %v, want %v", tt.scheme.Name(), tt.constraint, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s %q = %q, want %q", tt.scheme.Name(), tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestLowest(t *testing.T) {
+	tests := []struct {
+		versions   []string
+		constraint string
+		want       string
+	}{
+		{[]string{"13.0.3", "12.0.1", "13.0.1", "11.0.2"}, "[12.0.0, )", "12.0.1"},
+		{[]string{"12.0.1", "13.0.1"}, "13.0.0", "13.0.1"},
+		{[]string{"8.0.0-rc.1", "8.0.0", "8.0.1"}, "[8.0.0-preview.1, )", "8.0.0"},
+		{[]string{"8.0.0-rc.1"}, "[8.0.0-preview.1, )", "8.0.0-rc.1"},
+	}
+	for _, tt := range tests {
+		got, err := Lowest(NuGet, tt.versions, tt.constraint)
+		if err != nil {
+			t.Errorf("%q: %v", tt.constraint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %q, want %q", tt.constraint, got, tt.want)
+		}
+	}
+}